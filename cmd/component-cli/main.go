@@ -8,17 +8,22 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gardener/component-cli/cmd/component-cli/app"
 )
 
 func main() {
-	ctx := context.Background()
-	defer ctx.Done()
+	// cancel the context on interrupts so that running commands can stop gracefully,
+	// e.g. to resume an interrupted transport later on.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 	cmd := app.NewComponentsCliCommand(ctx)
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Print(err)
+		cancel()
 		os.Exit(1)
 	}
 }
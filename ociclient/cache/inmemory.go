@@ -9,11 +9,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sync"
 
 	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 type inmemoryCache struct {
+	mux   sync.RWMutex
 	store map[string][]byte
 }
 
@@ -29,6 +31,8 @@ func (fs *inmemoryCache) Close() error {
 }
 
 func (fs *inmemoryCache) Get(desc ocispecv1.Descriptor) (io.ReadCloser, error) {
+	fs.mux.RLock()
+	defer fs.mux.RUnlock()
 	data, ok := fs.store[desc.Digest.String()]
 	if !ok {
 		return nil, ErrNotFound
@@ -37,14 +41,20 @@ func (fs *inmemoryCache) Get(desc ocispecv1.Descriptor) (io.ReadCloser, error) {
 }
 
 func (fs *inmemoryCache) Add(desc ocispecv1.Descriptor, reader io.ReadCloser) error {
-	if _, ok := fs.store[desc.Digest.String()]; ok {
+	fs.mux.RLock()
+	_, ok := fs.store[desc.Digest.String()]
+	fs.mux.RUnlock()
+	if ok {
 		// already cached
 		return nil
 	}
+	// the data is read without holding the lock as reading may take a while
 	var buf bytes.Buffer
 	if _, err := io.Copy(&buf, reader); err != nil {
 		return fmt.Errorf("unable to read data: %w", err)
 	}
+	fs.mux.Lock()
+	defer fs.mux.Unlock()
 	fs.store[desc.Digest.String()] = buf.Bytes()
 	return nil
 }
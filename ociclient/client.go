@@ -47,8 +47,12 @@ type client struct {
 	transport      http.RoundTripper
 	allowPlainHttp bool
 	getHostConfig  docker.RegistryHosts
+	retryOptions   RetryOptions
 
 	knownMediaTypes sets.String
+
+	// referrersTags synchronises the updates of the referrers tags of registries without referrers api.
+	referrersTags referrersTags
 }
 
 // NewClient creates a new OCI Client.
@@ -88,6 +92,11 @@ func NewClient(log logr.Logger, opts ...Option) (*client, error) {
 	if trp == nil {
 		trp = http.DefaultTransport
 	}
+	retryOptions := DefaultRetryOptions
+	if options.RetryOptions != nil {
+		retryOptions = options.RetryOptions.Default()
+	}
+	trp = NewRetryingTransport(log, trp, retryOptions)
 
 	cLogger := logrus.New()
 	cLogger.SetLevel(logrus.FatalLevel)
@@ -106,6 +115,7 @@ func NewClient(log logr.Logger, opts ...Option) (*client, error) {
 		allowPlainHttp: options.AllowPlainHttp,
 		httpClient:     options.HTTPClient,
 		transport:      trp,
+		retryOptions:   retryOptions,
 		cache:          options.Cache,
 		getHostConfig: docker.ConfigureDefaultRegistries(
 			docker.WithPlainHTTP(func(_ string) (bool, error) {
@@ -275,6 +285,16 @@ func (c *client) PushBlob(ctx context.Context, ref string, desc ocispecv1.Descri
 	opts.Store = c.cache
 	opts.ApplyOptions(options)
 
+	if len(opts.MountFrom) != 0 {
+		mounted, err := c.mountBlob(ctx, ref, opts.MountFrom, desc)
+		if err != nil {
+			c.log.V(5).Info("unable to mount blob, falling back to upload", "ref", ref, "from", opts.MountFrom, "digest", desc.Digest.String(), "error", err.Error())
+		}
+		if mounted {
+			return nil
+		}
+	}
+
 	resolver, err := c.getResolverForRef(ctx, ref, transport.PushScope)
 	if err != nil {
 		return err
@@ -284,11 +304,7 @@ func (c *client) PushBlob(ctx context.Context, ref string, desc ocispecv1.Descri
 		return err
 	}
 
-	if err := c.pushContent(ctx, opts.Store, pusher, desc); err != nil {
-		return err
-	}
-
-	return nil
+	return c.pushContent(ctx, opts.Store, pusher, desc)
 }
 
 func (c *client) PushRawManifest(ctx context.Context, ref string, desc ocispecv1.Descriptor, rawManifest []byte, options ...PushOption) error {
@@ -358,7 +374,7 @@ func (c *client) PushRawManifest(ctx context.Context, ref string, desc ocispecv1
 		return fmt.Errorf("unable to push manifest: %w", err)
 	}
 
-	return nil
+	return c.addReferrer(ctx, ref, desc, rawManifest)
 }
 
 func (c *client) GetRawManifest(ctx context.Context, ref string) (ocispecv1.Descriptor, []byte, error) {
@@ -449,34 +465,16 @@ func (c *client) pushManifest(ctx context.Context, manifest *ocispecv1.Manifest,
 }
 
 func (c *client) pushImageIndex(ctx context.Context, indexArtifact *oci.Index, pusher remotes.Pusher, cache cache.Cache, opts *PushOptions) error {
-	manifestDescs := []ocispecv1.Descriptor{}
 	for _, manifest := range indexArtifact.Manifests {
-		mdesc, err := c.pushManifest(ctx, manifest.Data, pusher, cache, opts)
-		if err != nil {
+		if _, err := c.pushManifest(ctx, manifest.Data, pusher, cache, opts); err != nil {
 			return fmt.Errorf("unable to upload manifest: %w", err)
 		}
-		mdesc.Platform = manifest.Descriptor.Platform
-		mdesc.Annotations = manifest.Descriptor.Annotations
-		manifestDescs = append(manifestDescs, mdesc)
 	}
 
-	index := ocispecv1.Index{
-		Versioned: specs.Versioned{
-			SchemaVersion: 2,
-		},
-		Manifests:   manifestDescs,
-		Annotations: indexArtifact.Annotations,
-	}
-
-	indexBytes, err := json.Marshal(index)
+	indexDescriptor, indexBytes, err := CreateDescriptorFromIndex(indexArtifact)
 	if err != nil {
 		return err
 	}
-	indexDescriptor := ocispecv1.Descriptor{
-		MediaType: ocispecv1.MediaTypeImageIndex,
-		Digest:    digest.FromBytes(indexBytes),
-		Size:      int64(len(indexBytes)),
-	}
 
 	manifestBuf := bytes.NewBuffer(indexBytes)
 	if err := cache.Add(indexDescriptor, ioutil.NopCloser(manifestBuf)); err != nil {
@@ -554,6 +552,7 @@ func (c *client) getFetchReader(ctx context.Context, ref string, desc ocispecv1.
 	if err != nil {
 		return nil, err
 	}
+	reader = newResumableReader(ctx, c.log, c.retryOptions, reader)
 	// try to cache
 	if c.cache != nil {
 		if err := c.cache.Add(desc, reader); err != nil {
@@ -562,7 +561,11 @@ func (c *client) getFetchReader(ctx context.Context, ref string, desc ocispecv1.
 			if err = reader.Close(); err != nil {
 				c.log.V(2).Info("unable to close reader", "ref", ref, "error", err.Error())
 			}
-			return fetcher.Fetch(ctx, desc)
+			reader, err := fetcher.Fetch(ctx, desc)
+			if err != nil {
+				return nil, err
+			}
+			return newResumableReader(ctx, c.log, c.retryOptions, reader), nil
 		}
 		return c.cache.Get(desc)
 	}
@@ -617,6 +620,12 @@ func (c *client) getRefParserOptions(ref string) ([]name.Option, error) {
 
 // getTransportForRef returns the authenticated transport for a reference.
 func (c *client) getTransportForRef(ctx context.Context, ref string, scopes ...string) (http.RoundTripper, error) {
+	return c.getTransportWithScopes(ctx, ref, scopes, nil)
+}
+
+// getTransportWithScopes returns the authenticated transport for a reference.
+// The scopes are actions on the repository of the reference whereas the additional scopes are fully qualified scopes.
+func (c *client) getTransportWithScopes(ctx context.Context, ref string, scopes []string, additionalScopes []string) (http.RoundTripper, error) {
 	parseOptions, err := c.getRefParserOptions(ref)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get ref parser options: %w", err)
@@ -635,7 +644,7 @@ func (c *client) getTransportForRef(ctx context.Context, ref string, scopes ...s
 	for i, scope := range scopes {
 		scopes[i] = repo.Scope(scope)
 	}
-	trp, err := transport.NewWithContext(ctx, repo.Context().Registry, auth, c.transport, scopes)
+	trp, err := transport.NewWithContext(ctx, repo.Context().Registry, auth, c.transport, append(scopes, additionalScopes...))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create transport: %w", err)
 	}
@@ -881,16 +890,55 @@ func CreateDescriptorFromManifest(manifest *ocispecv1.Manifest) (ocispecv1.Descr
 	return manifestDescriptor, nil
 }
 
+// CreateDescriptorFromIndex creates the descriptor of the image index which is pushed for an index artifact.
+// The marshaled image index is returned together with the descriptor.
+func CreateDescriptorFromIndex(index *oci.Index) (ocispecv1.Descriptor, []byte, error) {
+	manifestDescs := []ocispecv1.Descriptor{}
+	for _, manifest := range index.Manifests {
+		mdesc, err := CreateDescriptorFromManifest(manifest.Data)
+		if err != nil {
+			return ocispecv1.Descriptor{}, nil, fmt.Errorf("unable to create manifest descriptor: %w", err)
+		}
+		mdesc.Platform = manifest.Descriptor.Platform
+		mdesc.Annotations = manifest.Descriptor.Annotations
+		manifestDescs = append(manifestDescs, mdesc)
+	}
+
+	i := ocispecv1.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		Manifests:   manifestDescs,
+		Annotations: index.Annotations,
+	}
+
+	indexBytes, err := json.Marshal(i)
+	if err != nil {
+		return ocispecv1.Descriptor{}, nil, err
+	}
+	indexDescriptor := ocispecv1.Descriptor{
+		MediaType: ocispecv1.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(indexBytes),
+		Size:      int64(len(indexBytes)),
+	}
+
+	return indexDescriptor, indexBytes, nil
+}
+
+// pushContent uploads the content of a descriptor.
+// The upload is restarted if it fails with a transient error.
 func (c *client) pushContent(ctx context.Context, store Store, pusher remotes.Pusher, desc ocispecv1.Descriptor) error {
 	if store == nil {
 		return errors.New("a store is needed to upload content but no store has been defined")
 	}
-	r, err := store.Get(desc)
-	if err != nil {
-		return err
-	}
-	defer r.Close()
+	return c.retry(ctx, "push "+desc.Digest.String(), func() error {
+		return c.pushContentOnce(ctx, store, pusher, desc)
+	})
+}
 
+// pushContentOnce uploads the content of a descriptor.
+// The content is only read from the store if it does not already exist in the registry.
+func (c *client) pushContentOnce(ctx context.Context, store Store, pusher remotes.Pusher, desc ocispecv1.Descriptor) error {
 	writer, err := pusher.Push(AddKnownMediaTypesToCtx(ctx, []string{desc.MediaType}), desc)
 	if err != nil {
 		if errdefs.IsAlreadyExists(err) {
@@ -899,6 +947,12 @@ func (c *client) pushContent(ctx context.Context, store Store, pusher remotes.Pu
 		return err
 	}
 	defer writer.Close()
+
+	r, err := store.Get(desc)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
 	return content.Copy(ctx, writer, r, desc.Size, desc.Digest)
 }
 
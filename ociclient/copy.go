@@ -7,58 +7,368 @@ package ociclient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sync"
 
+	"github.com/opencontainers/go-digest"
 	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
+// DefaultCopyConcurrency is the default number of blobs and manifests which are copied in parallel.
+const DefaultCopyConcurrency = 8
+
+// CopyProgress describes the progress of a copy.
+type CopyProgress struct {
+	// Descriptor is the descriptor of the blob or manifest which has been copied.
+	Descriptor ocispecv1.Descriptor
+	// Transferred is the number of bytes which have been uploaded for the blob or manifest.
+	// It is 0 for blobs which already existed in the target repository or which have been mounted.
+	Transferred int64
+	// Completed is the number of blobs and manifests which have been copied.
+	Completed int
+	// Total is the number of blobs and manifests which are copied.
+	// Blobs which are referenced by multiple manifests are only counted once.
+	Total int
+}
+
+// CopyProgressFunc is called after every copied blob and manifest.
+// Calls are serialized so that the function does not have to be safe for concurrent use.
+type CopyProgressFunc func(progress CopyProgress)
+
+// CopyOptions contains all oci copy options.
+type CopyOptions struct {
+	// Concurrency is the maximal number of blobs and manifests which are copied in parallel.
+	// The limit is shared by all manifests of an image index.
+	Concurrency int
+	// Progress is called after every copied blob and manifest.
+	Progress CopyProgressFunc
+	// Referrers specifies if the manifests which refer to the copied artifact should also be copied.
+	Referrers bool
+}
+
+// CopyOption is the interface to specify different copy options
+type CopyOption interface {
+	ApplyCopyOption(options *CopyOptions)
+}
+
+// ApplyOptions applies the given list options on these options,
+// and then returns itself (for convenient chaining).
+func (o *CopyOptions) ApplyOptions(opts []CopyOption) *CopyOptions {
+	for _, opt := range opts {
+		if opt != nil {
+			opt.ApplyCopyOption(o)
+		}
+	}
+	return o
+}
+
+// WithCopyConcurrency configures the maximal number of blobs and manifests which are copied in parallel.
+type WithCopyConcurrency int
+
+func (c WithCopyConcurrency) ApplyCopyOption(options *CopyOptions) {
+	options.Concurrency = int(c)
+}
+
+// WithCopyProgress configures a function which is called after every copied blob and manifest.
+type WithCopyProgress CopyProgressFunc
+
+func (c WithCopyProgress) ApplyCopyOption(options *CopyOptions) {
+	options.Progress = CopyProgressFunc(c)
+}
+
+// WithCopyReferrers configures whether the manifests which refer to the copied artifact are also copied.
+type WithCopyReferrers bool
+
+func (c WithCopyReferrers) ApplyCopyOption(options *CopyOptions) {
+	options.Referrers = bool(c)
+}
+
 // Copy copies a oci artifact from one location to a target ref.
 // The artifact is copied without any modification.
 // This function does directly stream the blobs from the upstream it does not use any cache.
-func Copy(ctx context.Context, client Client, srcRef, tgtRef string) error {
+//
+// All blobs of the artifact, including the blobs of all manifests of an image index, are copied in parallel.
+// Blobs which are referenced by multiple manifests are only copied once.
+// Blobs which already exist in the target repository are skipped and blobs are mounted
+// instead of being streamed if the source and target repository are located in the same registry.
+// The manifests are pushed after their blobs, the image index is pushed last.
+//
+// The referrers of the artifact, e.g. signatures or SBOMs, are copied afterwards if configured.
+// Referrers are copied one after another with their own progress and their referrers are copied as well.
+func Copy(ctx context.Context, client Client, srcRef, tgtRef string, opts ...CopyOption) error {
+	options := &CopyOptions{}
+	options.ApplyOptions(opts)
+	if options.Concurrency < 1 {
+		options.Concurrency = DefaultCopyConcurrency
+	}
+
 	desc, rawManifest, err := client.GetRawManifest(ctx, srcRef)
 	if err != nil {
 		return fmt.Errorf("unable to get manifest: %w", err)
 	}
 
-	store := GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
-		return client.Fetch(ctx, srcRef, desc, writer)
+	c := &copier{
+		client:      client,
+		srcRef:      srcRef,
+		tgtRef:      tgtRef,
+		progress:    options.Progress,
+		semaphore:   make(chan struct{}, options.Concurrency),
+		transferred: map[digest.Digest]int64{},
+	}
+	c.store = GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
+		cw := &countingWriter{w: writer}
+		err := client.Fetch(ctx, srcRef, desc, cw)
+		c.addTransferred(desc.Digest, cw.bytes)
+		return err
 	})
 
-	if IsMultiArchImage(desc.MediaType) {
-		index := ocispecv1.Index{}
-		if err := json.Unmarshal(rawManifest, &index); err != nil {
-			return fmt.Errorf("unable to unmarshal image index: %w", err)
+	srcRepo, _, err := ParseImageRef(srcRef)
+	if err != nil {
+		return fmt.Errorf("unable to parse src ref: %w", err)
+	}
+
+	tgtRepo, _, err := ParseImageRef(tgtRef)
+	if err != nil {
+		return fmt.Errorf("unable to parse tgt ref: %w", err)
+	}
+
+	if !IsMultiArchImage(desc.MediaType) {
+		if err := c.copy(ctx, []copyManifest{{desc: desc, raw: rawManifest, tgtRef: tgtRef}}, nil); err != nil {
+			return err
 		}
+		return copyReferrers(ctx, client, srcRepo, tgtRepo, desc, options, opts)
+	}
+
+	index := ocispecv1.Index{}
+	if err := json.Unmarshal(rawManifest, &index); err != nil {
+		return fmt.Errorf("unable to unmarshal image index: %w", err)
+	}
 
-		srcRepo, _, err := ParseImageRef(srcRef)
+	manifests := make([]copyManifest, len(index.Manifests))
+	err = c.parallel(ctx, len(index.Manifests), func(ctx context.Context, i int) error {
+		subManifestSrcRef := fmt.Sprintf("%s@%s", srcRepo, index.Manifests[i].Digest)
+		desc, raw, err := client.GetRawManifest(ctx, subManifestSrcRef)
 		if err != nil {
-			return fmt.Errorf("unable to parse src ref: %w", err)
+			return fmt.Errorf("unable to get sub manifest %s: %w", subManifestSrcRef, err)
+		}
+		manifests[i] = copyManifest{
+			desc:   desc,
+			raw:    raw,
+			tgtRef: fmt.Sprintf("%s@%s", tgtRepo, index.Manifests[i].Digest),
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-		tgtRepo, _, err := ParseImageRef(tgtRef)
-		if err != nil {
-			return fmt.Errorf("unable to parse tgt ref: %w", err)
+	if err := c.copy(ctx, manifests, &copyManifest{desc: desc, raw: rawManifest, tgtRef: tgtRef}); err != nil {
+		return err
+	}
+	return copyReferrers(ctx, client, srcRepo, tgtRepo, desc, options, opts)
+}
+
+// copyReferrers copies all manifests which refer to the given manifest if configured.
+func copyReferrers(ctx context.Context, client Client, srcRepo, tgtRepo string, desc ocispecv1.Descriptor, options *CopyOptions, opts []CopyOption) error {
+	if !options.Referrers {
+		return nil
+	}
+	extendedClient, ok := client.(ExtendedClient)
+	if !ok {
+		return errors.New("the client does not support listing referrers")
+	}
+	referrers, err := extendedClient.ListReferrers(ctx, fmt.Sprintf("%s@%s", srcRepo, desc.Digest), "")
+	if err != nil {
+		return fmt.Errorf("unable to list referrers of %s: %w", desc.Digest, err)
+	}
+	for _, referrer := range referrers {
+		srcRef := fmt.Sprintf("%s@%s", srcRepo, referrer.Digest)
+		if err := Copy(ctx, client, srcRef, fmt.Sprintf("%s@%s", tgtRepo, referrer.Digest), opts...); err != nil {
+			return fmt.Errorf("unable to copy referrer %s: %w", srcRef, err)
 		}
+	}
+	return nil
+}
+
+// copyManifest is a manifest which is copied to a target ref.
+type copyManifest struct {
+	desc   ocispecv1.Descriptor
+	raw    []byte
+	tgtRef string
+}
 
-		for _, manifestDesc := range index.Manifests {
-			subManifestSrcRef := fmt.Sprintf("%s@%s", srcRepo, manifestDesc.Digest)
-			subManifestTgtRef := fmt.Sprintf("%s@%s", tgtRepo, manifestDesc.Digest)
+// copier copies the blobs and manifests of an artifact with a bounded number of parallel uploads.
+type copier struct {
+	client Client
+	srcRef string
+	tgtRef string
+	store  Store
+
+	// semaphore limits the number of parallel uploads.
+	semaphore chan struct{}
+
+	mux            sync.Mutex
+	progress       CopyProgressFunc
+	completedCount int
+	total          int
+	// transferred contains the number of bytes which have been streamed from the source per blob.
+	transferred map[digest.Digest]int64
+}
 
-			if err := Copy(ctx, client, subManifestSrcRef, subManifestTgtRef); err != nil {
-				return fmt.Errorf("unable to copy sub manifest: %w", err)
+// copy copies the blobs of the given single arch manifests, the manifests and the image index if given.
+func (c *copier) copy(ctx context.Context, manifests []copyManifest, index *copyManifest) error {
+	blobs := []ocispecv1.Descriptor{}
+	seen := map[digest.Digest]bool{}
+	for _, m := range manifests {
+		manifestBlobs, err := c.blobs(m)
+		if err != nil {
+			return err
+		}
+		for _, blob := range manifestBlobs {
+			if seen[blob.Digest] {
+				continue
 			}
+			seen[blob.Digest] = true
+			blobs = append(blobs, blob)
 		}
 	}
 
-	if err := client.PushRawManifest(ctx, tgtRef, desc, rawManifest, WithStore(store)); err != nil {
-		return fmt.Errorf("unable to push manifest: %w", err)
+	c.total = len(blobs) + len(manifests)
+	if index != nil {
+		c.total++
+	}
+
+	err := c.parallel(ctx, len(blobs), func(ctx context.Context, i int) error {
+		if err := c.client.PushBlob(ctx, c.tgtRef, blobs[i], WithStore(c.store), WithMountFrom(c.srcRef)); err != nil {
+			return fmt.Errorf("unable to copy blob %s: %w", blobs[i].Digest, err)
+		}
+		c.complete(blobs[i], c.popTransferred(blobs[i].Digest))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.parallel(ctx, len(manifests), func(ctx context.Context, i int) error {
+		return c.pushManifest(ctx, manifests[i])
+	})
+	if err != nil {
+		return err
+	}
+
+	if index == nil {
+		return nil
+	}
+	return c.pushManifest(ctx, *index)
+}
+
+// blobs returns the config and layers of a single arch manifest.
+func (c *copier) blobs(m copyManifest) ([]ocispecv1.Descriptor, error) {
+	manifest := ocispecv1.Manifest{}
+	if err := json.Unmarshal(m.raw, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+	blobs := make([]ocispecv1.Descriptor, 0, len(manifest.Layers)+1)
+	// a missing config is replaced by a dummy config when the manifest is pushed
+	if manifest.Config.Size != 0 {
+		blobs = append(blobs, manifest.Config)
 	}
+	return append(blobs, manifest.Layers...), nil
+}
 
+func (c *copier) pushManifest(ctx context.Context, m copyManifest) error {
+	if err := c.client.PushRawManifest(ctx, m.tgtRef, m.desc, m.raw, WithStore(c.store)); err != nil {
+		return fmt.Errorf("unable to push manifest: %w", err)
+	}
+	c.complete(m.desc, int64(len(m.raw)))
 	return nil
 }
 
+func (c *copier) complete(desc ocispecv1.Descriptor, transferred int64) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.completedCount++
+	if c.progress != nil {
+		c.progress(CopyProgress{
+			Descriptor:  desc,
+			Transferred: transferred,
+			Completed:   c.completedCount,
+			Total:       c.total,
+		})
+	}
+}
+
+// addTransferred records the bytes which have been streamed from the source for a blob.
+// Retried uploads stream the blob again, therefore their bytes are added.
+func (c *copier) addTransferred(dgst digest.Digest, bytes int64) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.transferred[dgst] += bytes
+}
+
+// popTransferred returns and resets the bytes which have been streamed from the source for a blob.
+func (c *copier) popTransferred(dgst digest.Digest) int64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	bytes := c.transferred[dgst]
+	delete(c.transferred, dgst)
+	return bytes
+}
+
+// parallel runs fn for every index in parallel, whereas the number of running functions is limited by the semaphore.
+// The context of all functions is cancelled as soon as one of them fails. The first error is returned.
+func (c *copier) parallel(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i := 0; i < n; i++ {
+		select {
+		case c.semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-c.semaphore }()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// countingWriter counts the bytes which are written to the underlying writer.
+type countingWriter struct {
+	w     io.Writer
+	bytes int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
 // GenericStore is a helper struct to implement a custom oci blob store.
 type GenericStore func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error
 
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ociclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	remoteserrors "github.com/containerd/containerd/remotes/errors"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
+	"github.com/gardener/component-cli/ociclient/oci"
+)
+
+// deleteScope is the action that is required by some registries to delete manifests.
+const deleteScope = "delete"
+
+// tagDeletionUnsupportedStatusCodes are the status codes of registries that do not support the deletion of tags.
+var tagDeletionUnsupportedStatusCodes = map[int]bool{
+	http.StatusBadRequest:       true,
+	http.StatusMethodNotAllowed: true,
+	http.StatusNotImplemented:   true,
+}
+
+// DeleteManifest deletes the manifest of the given ref.
+// A tag is resolved to the digest of its manifest, so that the manifest and all tags referencing it are deleted.
+// Implements the distribution spec defined in https://github.com/opencontainers/distribution-spec/blob/main/spec.md#deleting-manifests.
+func (c *client) DeleteManifest(ctx context.Context, ref string) error {
+	refspec, err := oci.ParseRef(ref)
+	if err != nil {
+		return fmt.Errorf("unable to parse ref: %w", err)
+	}
+	if refspec.Digest == nil {
+		_, desc, err := c.Resolve(ctx, refspec.String())
+		if err != nil {
+			return fmt.Errorf("unable to resolve %q: %w", refspec.String(), err)
+		}
+		refspec.Tag = nil
+		refspec.Digest = &desc.Digest
+	}
+	return c.deleteManifest(ctx, refspec, refspec.Digest.String())
+}
+
+// DeleteTag deletes the tag of the given ref without deleting the referenced manifest.
+// Registries that do not support the deletion of tags only support the deletion of the manifest by its digest,
+// therefore the manifest and all tags referencing it are deleted instead.
+func (c *client) DeleteTag(ctx context.Context, ref string) error {
+	refspec, err := oci.ParseRef(ref)
+	if err != nil {
+		return fmt.Errorf("unable to parse ref: %w", err)
+	}
+	if refspec.Tag == nil {
+		return fmt.Errorf("ref %q does not contain a tag", ref)
+	}
+
+	err = c.deleteManifest(ctx, refspec, *refspec.Tag)
+	if err == nil {
+		return nil
+	}
+	var statusErr remoteserrors.ErrUnexpectedStatus
+	if !errors.As(err, &statusErr) || !tagDeletionUnsupportedStatusCodes[statusErr.StatusCode] {
+		return err
+	}
+	c.log.V(5).Info("registry does not support the deletion of tags, deleting the manifest instead", "ref", refspec.String(), "status", statusErr.StatusCode)
+	return c.DeleteManifest(ctx, refspec.String())
+}
+
+// deleteManifest deletes the manifest with the given reference, which is either a digest or a tag, from the repository of the refspec.
+func (c *client) deleteManifest(ctx context.Context, refspec oci.RefSpec, reference string) error {
+	hosts, err := c.getHostConfig(refspec.Host)
+	if err != nil {
+		return fmt.Errorf("unable to find registry host: %w", err)
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("no host configuration found: %w", err)
+	}
+	hostConfig := hosts[0]
+
+	trp, err := c.getTransportForRef(ctx, refspec.String(), transport.PullScope, transport.PushScope, deleteScope)
+	if err != nil {
+		return fmt.Errorf("unable to create transport: %w", err)
+	}
+	httpClient := c.getHttpClient()
+	httpClient.Transport = trp
+
+	u := &url.URL{
+		Scheme: hostConfig.Scheme,
+		Host:   hostConfig.Host,
+		Path:   path.Join(hostConfig.Path, refspec.Repository, "manifests", reference),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to delete %q: %w", u.String(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to delete manifest %q: %w", reference, remoteserrors.NewUnexpectedStatusErr(resp))
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	c.log.V(5).Info("deleted manifest", "ref", refspec.Name(), "reference", reference)
+	return nil
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ociclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient/oci"
+)
+
+// mountBlob makes a blob of the repository of fromRef available in the repository of ref without uploading it.
+// The blob is skipped if it already exists in the target repository,
+// otherwise it is mounted from the source repository if both repositories are located in the same registry.
+// False is returned if the blob has to be uploaded.
+func (c *client) mountBlob(ctx context.Context, ref, fromRef string, desc ocispecv1.Descriptor) (bool, error) {
+	refspec, err := oci.ParseRef(ref)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse ref: %w", err)
+	}
+	fromRefspec, err := oci.ParseRef(fromRef)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse ref: %w", err)
+	}
+	if refspec.Host != fromRefspec.Host || refspec.Repository == fromRefspec.Repository {
+		return false, nil
+	}
+
+	hosts, err := c.getHostConfig(refspec.Host)
+	if err != nil {
+		return false, fmt.Errorf("unable to find registry host: %w", err)
+	}
+	if len(hosts) == 0 {
+		return false, fmt.Errorf("no host configuration found: %w", err)
+	}
+	hostConfig := hosts[0]
+
+	parseOptions, err := c.getRefParserOptions(fromRef)
+	if err != nil {
+		return false, fmt.Errorf("unable to get ref parser options: %w", err)
+	}
+	fromRepo, err := name.ParseReference(fromRefspec.String(), parseOptions...)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse ref: %w", err)
+	}
+	trp, err := c.getTransportWithScopes(ctx, ref, []string{transport.PushScope}, []string{fromRepo.Scope(transport.PullScope)})
+	if err != nil {
+		return false, fmt.Errorf("unable to create transport: %w", err)
+	}
+	httpClient := c.getHttpClient()
+	httpClient.Transport = trp
+
+	blobURL := &url.URL{
+		Scheme: hostConfig.Scheme,
+		Host:   hostConfig.Host,
+		Path:   path.Join(hostConfig.Path, refspec.Repository, "blobs", desc.Digest.String()),
+	}
+	resp, err := c.doBlobRequest(ctx, httpClient, http.MethodHead, blobURL)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		c.log.V(7).Info("blob already exists", "ref", ref, "digest", desc.Digest.String())
+		return true, nil
+	}
+
+	mountURL := &url.URL{
+		Scheme: hostConfig.Scheme,
+		Host:   hostConfig.Host,
+		// the trailing slash is required by the distribution spec
+		Path: path.Join(hostConfig.Path, refspec.Repository, "blobs", "uploads") + "/",
+		RawQuery: url.Values{
+			"mount": []string{desc.Digest.String()},
+			"from":  []string{fromRefspec.Repository},
+		}.Encode(),
+	}
+	resp, err = c.doBlobRequest(ctx, httpClient, http.MethodPost, mountURL)
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		c.log.V(7).Info("mounted blob", "ref", ref, "from", fromRefspec.Repository, "digest", desc.Digest.String())
+		return true, nil
+	case http.StatusAccepted:
+		// the registry does not support mounts or the blob is not accessible, so that a regular upload has been started instead.
+		// The upload session is cancelled as the blob is uploaded with a new session.
+		if location := resp.Header.Get("Location"); len(location) != 0 {
+			if uploadURL, err := mountURL.Parse(location); err == nil {
+				_, _ = c.doBlobRequest(ctx, httpClient, http.MethodDelete, uploadURL)
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status code %d when mounting blob %s from %s", resp.StatusCode, desc.Digest, fromRefspec.Repository)
+	}
+}
+
+// doBlobRequest does a request without body to the blob api and discards the response body.
+func (c *client) doBlobRequest(ctx context.Context, httpClient *http.Client, method string, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to %s %q: %w", method, u.String(), err)
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if err := resp.Body.Close(); err != nil {
+		return nil, fmt.Errorf("unable to close body reader: %w", err)
+	}
+	return resp, nil
+}
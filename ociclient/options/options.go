@@ -32,6 +32,8 @@ type Options struct {
 	RegistryConfigPath string
 	// ConcourseConfigPath is the path to the local concourse config file.
 	ConcourseConfigPath string
+	// Retry configures the retries of registry requests and uploads which failed with a transient error.
+	Retry ociclient.RetryOptions
 }
 
 func (o *Options) AddFlags(fs *pflag.FlagSet) {
@@ -43,6 +45,9 @@ func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&o.SkipTLSVerify, "insecure-skip-tls-verify", false, "If true, the server's certificate will not be checked for validity. This will make your HTTPS connections insecure")
 	fs.StringVar(&o.RegistryConfigPath, "registry-config", "", "path to the dockerconfig.json with the oci registry authentication information")
 	fs.StringVar(&o.ConcourseConfigPath, "cc-config", "", "path to the local concourse config file")
+	fs.IntVar(&o.Retry.MaxAttempts, "registry-max-attempts", ociclient.DefaultRetryOptions.MaxAttempts, "maximal number of attempts of a registry request or upload that fails with a transient error. A value of 1 disables retries")
+	fs.DurationVar(&o.Retry.InitialBackoff, "registry-retry-backoff", ociclient.DefaultRetryOptions.InitialBackoff, "time to wait before the first retry of a registry request. The backoff is doubled for every further retry")
+	fs.DurationVar(&o.Retry.MaxBackoff, "registry-max-retry-backoff", ociclient.DefaultRetryOptions.MaxBackoff, "maximal time to wait between two attempts of a registry request. A Retry-After header of the registry takes precedence")
 }
 
 // Build builds a new oci client based on the given options
@@ -58,6 +63,7 @@ func (o *Options) Build(log logr.Logger, fs vfs.FileSystem) (ociclient.ExtendedC
 		ociclient.WithKnownMediaType(cdoci.ComponentDescriptorTarMimeType),
 		ociclient.WithKnownMediaType(cdoci.ComponentDescriptorJSONMimeType),
 		ociclient.AllowPlainHttp(o.AllowPlainHttp),
+		ociclient.WithRetryOptions(o.Retry),
 	}
 
 	if o.SkipTLSVerify {
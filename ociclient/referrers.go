@@ -0,0 +1,349 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ociclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/containerd/containerd/errdefs"
+	remoteserrors "github.com/containerd/containerd/remotes/errors"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient/oci"
+)
+
+// ReferrerManifest is an oci image manifest with the artifact type and subject of the image-spec v1.1.
+// The subject defines the manifest the artifact refers to, e.g. a signature or a SBOM of an image.
+type ReferrerManifest struct {
+	specs.Versioned
+
+	// MediaType is the media type of the manifest.
+	MediaType string `json:"mediaType,omitempty"`
+	// ArtifactType is the type of the artifact.
+	// The media type of the config is used as artifact type if it is not set.
+	ArtifactType string `json:"artifactType,omitempty"`
+	// Config references the configuration object of the artifact.
+	Config ocispecv1.Descriptor `json:"config"`
+	// Layers is the list of blobs of the artifact.
+	Layers []ocispecv1.Descriptor `json:"layers"`
+	// Subject is the manifest the artifact refers to.
+	Subject *ocispecv1.Descriptor `json:"subject,omitempty"`
+	// Annotations contains arbitrary metadata for the manifest.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Referrer describes a manifest that refers to a subject.
+type Referrer struct {
+	ocispecv1.Descriptor
+	// ArtifactType is the artifact type of the referring manifest.
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// referrersIndex is the image index which lists the referrers of a subject.
+// It is returned by the referrers api and stored in the referrers tag of registries which do not support the referrers api.
+type referrersIndex struct {
+	specs.Versioned
+	MediaType string     `json:"mediaType,omitempty"`
+	Manifests []Referrer `json:"manifests"`
+}
+
+// CreateDescriptorFromReferrerManifest creates the descriptor of a referrer manifest.
+// The marshaled manifest is returned together with the descriptor.
+func CreateDescriptorFromReferrerManifest(manifest *ReferrerManifest) (ocispecv1.Descriptor, []byte, error) {
+	if manifest.SchemaVersion == 0 {
+		manifest.SchemaVersion = 2
+	}
+	if len(manifest.MediaType) == 0 {
+		manifest.MediaType = ocispecv1.MediaTypeImageManifest
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispecv1.Descriptor{}, nil, err
+	}
+	manifestDescriptor := ocispecv1.Descriptor{
+		MediaType:   manifest.MediaType,
+		Digest:      digest.FromBytes(manifestBytes),
+		Size:        int64(len(manifestBytes)),
+		Annotations: manifest.Annotations,
+	}
+	return manifestDescriptor, manifestBytes, nil
+}
+
+// referrersTags serialises the updates of referrers tags and remembers which registry hosts support the referrers api.
+// Referrers tags are updated via read-modify-write, therefore concurrent pushes of referrers of the same subject
+// would otherwise overwrite each other's entries. Updates of other clients or processes are not synchronised.
+type referrersTags struct {
+	mux       sync.Mutex
+	locks     map[string]*sync.Mutex
+	supported map[string]bool
+}
+
+// lock locks the referrers tag of a subject and returns the function to unlock it.
+func (r *referrersTags) lock(tagRef string) func() {
+	r.mux.Lock()
+	if r.locks == nil {
+		r.locks = map[string]*sync.Mutex{}
+	}
+	l, ok := r.locks[tagRef]
+	if !ok {
+		l = &sync.Mutex{}
+		r.locks[tagRef] = l
+	}
+	r.mux.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// isSupported returns whether the referrers api of a host is supported and whether this is already known.
+func (r *referrersTags) isSupported(host string) (supported, known bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	supported, known = r.supported[host]
+	return supported, known
+}
+
+func (r *referrersTags) setSupported(host string, supported bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.supported == nil {
+		r.supported = map[string]bool{}
+	}
+	r.supported[host] = supported
+}
+
+// ReferrersTag returns the tag of the fallback referrers index of a subject as defined by the distribution spec.
+func ReferrersTag(subject digest.Digest) string {
+	return fmt.Sprintf("%s-%s", subject.Algorithm(), subject.Encoded())
+}
+
+// IsReferrersTag returns whether a tag is the referrers tag of a subject, e.g. to distinguish it from version tags.
+func IsReferrersTag(tag string) bool {
+	i := strings.Index(tag, "-")
+	if i < 0 {
+		return false
+	}
+	return digest.Digest(tag[:i]+":"+tag[i+1:]).Validate() == nil
+}
+
+// ListReferrers lists all manifests which refer to the manifest of the given ref.
+// Only referrers of the given artifact type are returned if the artifact type is not empty.
+// The referrers tag is read if the registry does not support the referrers api.
+// Implements the distribution spec defined in https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers.
+func (c *client) ListReferrers(ctx context.Context, ref, artifactType string) ([]Referrer, error) {
+	refspec, err := oci.ParseRef(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse ref: %w", err)
+	}
+	if refspec.Digest == nil {
+		_, desc, err := c.Resolve(ctx, refspec.String())
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve %q: %w", refspec.String(), err)
+		}
+		refspec.Tag = nil
+		refspec.Digest = &desc.Digest
+	}
+
+	referrers, supported, err := c.fetchReferrers(ctx, refspec, *refspec.Digest, artifactType)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		c.log.V(7).Info("registry does not support the referrers api, reading the referrers tag", "ref", refspec.Name())
+		index, err := c.getReferrersIndex(ctx, refspec, *refspec.Digest)
+		if err != nil {
+			return nil, err
+		}
+		referrers = index.Manifests
+	}
+
+	if len(artifactType) == 0 {
+		return referrers, nil
+	}
+	// registries may ignore the artifact type filter
+	filtered := make([]Referrer, 0, len(referrers))
+	for _, referrer := range referrers {
+		if referrer.ArtifactType == artifactType {
+			filtered = append(filtered, referrer)
+		}
+	}
+	return filtered, nil
+}
+
+// fetchReferrers lists the referrers of a subject with the referrers api.
+// False is returned if the registry does not support the referrers api.
+func (c *client) fetchReferrers(ctx context.Context, refspec oci.RefSpec, subject digest.Digest, artifactType string) ([]Referrer, bool, error) {
+	hosts, err := c.getHostConfig(refspec.Host)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to find registry host: %w", err)
+	}
+	if len(hosts) == 0 {
+		return nil, false, fmt.Errorf("no host configuration found: %w", err)
+	}
+	hostConfig := hosts[0]
+
+	trp, err := c.getTransportForRef(ctx, refspec.String(), transport.PullScope)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to create transport: %w", err)
+	}
+	httpClient := c.getHttpClient()
+	httpClient.Transport = trp
+
+	u := &url.URL{
+		Scheme: hostConfig.Scheme,
+		Host:   hostConfig.Host,
+		Path:   path.Join(hostConfig.Path, refspec.Repository, "referrers", subject.String()),
+	}
+	if len(artifactType) != 0 {
+		u.RawQuery = url.Values{"artifactType": []string{artifactType}}.Encode()
+	}
+
+	supported := true
+	referrers := []Referrer{}
+	err = doRequestWithPaging(ctx, u, func(ctx context.Context, u *url.URL) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create request: %w", err)
+		}
+		req.Header.Set("Accept", ocispecv1.MediaTypeImageIndex)
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get %q: %w", u.String(), err)
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound:
+			// the response of an unsupported api does not contain a link to a next page
+			supported = false
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			return resp, nil
+		default:
+			return nil, fmt.Errorf("unable to list referrers: %w", remoteserrors.NewUnexpectedStatusErr(resp))
+		}
+
+		index := &referrersIndex{}
+		if err := json.NewDecoder(resp.Body).Decode(index); err != nil {
+			return nil, fmt.Errorf("unable to decode referrers: %w", err)
+		}
+		referrers = append(referrers, index.Manifests...)
+		return resp, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return referrers, supported, nil
+}
+
+// getReferrersIndex returns the index of the referrers tag of a subject.
+// An empty index is returned if the tag does not exist.
+func (c *client) getReferrersIndex(ctx context.Context, refspec oci.RefSpec, subject digest.Digest) (*referrersIndex, error) {
+	index := &referrersIndex{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispecv1.MediaTypeImageIndex,
+		Manifests: []Referrer{},
+	}
+	tagRef := fmt.Sprintf("%s:%s", refspec.Name(), ReferrersTag(subject))
+	_, rawIndex, err := c.GetRawManifest(ctx, tagRef)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return index, nil
+		}
+		return nil, fmt.Errorf("unable to get referrers tag %q: %w", tagRef, err)
+	}
+	if err := json.Unmarshal(rawIndex, index); err != nil {
+		return nil, fmt.Errorf("unable to decode referrers tag %q: %w", tagRef, err)
+	}
+	return index, nil
+}
+
+// addReferrer adds a pushed manifest to the referrers tag of its subject if the registry does not support the referrers api.
+// Registries which support the referrers api index the subject themselves. Whether a registry supports the
+// referrers api is only checked once per host.
+// Manifests without a subject are ignored.
+func (c *client) addReferrer(ctx context.Context, ref string, desc ocispecv1.Descriptor, rawManifest []byte) error {
+	if desc.MediaType != ocispecv1.MediaTypeImageManifest && desc.MediaType != ocispecv1.MediaTypeImageIndex {
+		// only oci manifests and indexes can define a subject
+		return nil
+	}
+	manifest := &ReferrerManifest{}
+	if err := json.Unmarshal(rawManifest, manifest); err != nil {
+		return fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+	if manifest.Subject == nil {
+		return nil
+	}
+	refspec, err := oci.ParseRef(ref)
+	if err != nil {
+		return fmt.Errorf("unable to parse ref: %w", err)
+	}
+
+	supported, known := c.referrersTags.isSupported(refspec.Host)
+	if !known {
+		_, supported, err = c.fetchReferrers(ctx, refspec, manifest.Subject.Digest, "")
+		if err != nil {
+			return err
+		}
+		c.referrersTags.setSupported(refspec.Host, supported)
+	}
+	if supported {
+		return nil
+	}
+
+	tagRef := fmt.Sprintf("%s:%s", refspec.Name(), ReferrersTag(manifest.Subject.Digest))
+	unlock := c.referrersTags.lock(tagRef)
+	defer unlock()
+
+	index, err := c.getReferrersIndex(ctx, refspec, manifest.Subject.Digest)
+	if err != nil {
+		return err
+	}
+	for _, referrer := range index.Manifests {
+		if referrer.Digest == desc.Digest {
+			return nil
+		}
+	}
+	referrer := Referrer{
+		Descriptor: ocispecv1.Descriptor{
+			MediaType:   desc.MediaType,
+			Digest:      desc.Digest,
+			Size:        desc.Size,
+			Annotations: manifest.Annotations,
+		},
+		ArtifactType: manifest.ArtifactType,
+	}
+	if len(referrer.ArtifactType) == 0 {
+		referrer.ArtifactType = manifest.Config.MediaType
+	}
+	index.Manifests = append(index.Manifests, referrer)
+
+	rawIndex, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("unable to marshal referrers index: %w", err)
+	}
+	indexDesc := ocispecv1.Descriptor{
+		MediaType: ocispecv1.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(rawIndex),
+		Size:      int64(len(rawIndex)),
+	}
+	c.log.V(7).Info("update referrers tag", "ref", tagRef, "referrer", desc.Digest.String())
+	if err := c.PushRawManifest(ctx, tagRef, indexDesc, rawIndex); err != nil {
+		return fmt.Errorf("unable to push referrers tag %q: %w", tagRef, err)
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package ociclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	remoteserrors "github.com/containerd/containerd/remotes/errors"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// RetryOptions configures the retries of requests and operations which failed with a transient registry error.
+type RetryOptions struct {
+	// MaxAttempts is the maximal number of attempts of a request or operation.
+	// A value of 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry.
+	// The backoff is doubled for every further retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximal time to wait between two attempts.
+	// A Retry-After header of the registry takes precedence.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryOptions are the retry options that are used if a client is created without explicit retry options.
+var DefaultRetryOptions = RetryOptions{
+	MaxAttempts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// Default returns the options with the default values for all unset values.
+func (o RetryOptions) Default() RetryOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultRetryOptions.MaxAttempts
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = DefaultRetryOptions.InitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultRetryOptions.MaxBackoff
+	}
+	return o
+}
+
+// backoff returns the time to wait after the given failed attempt.
+func (o RetryOptions) backoff(attempt int) time.Duration {
+	backoff := o.InitialBackoff
+	for i := 1; i < attempt && backoff < o.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > o.MaxBackoff {
+		return o.MaxBackoff
+	}
+	return backoff
+}
+
+// retryableStatusCodes are the http status codes of responses which indicate a transient registry error.
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// IsTransientError returns whether the error is a transient registry or network error
+// so that the failed request or operation can be retried.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr remoteserrors.ErrUnexpectedStatus
+	if errors.As(err, &statusErr) {
+		return retryableStatusCodes[statusErr.StatusCode]
+	}
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		return retryableStatusCodes[transportErr.StatusCode]
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+// NewRetryingTransport returns a http.RoundTripper that retries idempotent requests
+// which failed with a transient error or a retryable status code.
+// Requests are retried with an exponential backoff. A Retry-After header of the response takes precedence.
+// Requests with a non-idempotent method or a body that cannot be replayed are never retried.
+func NewRetryingTransport(log logr.Logger, next http.RoundTripper, opts RetryOptions) http.RoundTripper {
+	return &retryingTransport{
+		log:  log,
+		next: next,
+		opts: opts.Default(),
+	}
+}
+
+type retryingTransport struct {
+	log  logr.Logger
+	next http.RoundTripper
+	opts RetryOptions
+}
+
+func (t *retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isRetryableRequest(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.opts.MaxAttempts {
+			return resp, err
+		}
+		if err != nil && !IsTransientError(err) {
+			return resp, err
+		}
+		if err == nil && !retryableStatusCodes[resp.StatusCode] {
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			// some clients refuse to replay a body which has already been sent
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		backoff := t.opts.backoff(attempt)
+		keysAndValues := []interface{}{"method", req.Method, "url", req.URL.String(), "attempt", attempt}
+		if err != nil {
+			keysAndValues = append(keysAndValues, "error", err.Error())
+		} else {
+			keysAndValues = append(keysAndValues, "status", resp.Status)
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				backoff = retryAfter
+			}
+			// the body is read and closed so that the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		t.log.V(5).Info("retrying request", append(keysAndValues, "backoff", backoff.String())...)
+
+		if err := sleep(req.Context(), backoff); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+}
+
+// isRetryableRequest returns whether a request is idempotent and can be sent again.
+func isRetryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header which is either a number of seconds or a http date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if wait := time.Until(date); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retry runs an operation until it succeeds, fails with a permanent error or all attempts are used.
+// It is used for operations that consist of multiple requests which cannot be retried on their own,
+// like blob uploads which are started with a POST request and streamed afterwards.
+func (c *client) retry(ctx context.Context, operation string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.retryOptions.MaxAttempts || !IsTransientError(err) || retriedByTransport(err) {
+			return err
+		}
+
+		backoff := c.retryOptions.backoff(attempt)
+		c.log.V(5).Info("retrying operation", "operation", operation, "attempt", attempt, "backoff", backoff.String(), "error", err.Error())
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+	}
+}
+
+// retriedByTransport returns whether the error is the status of a request that has already been retried by the transport.
+func retriedByTransport(err error) bool {
+	var statusErr remoteserrors.ErrUnexpectedStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return statusErr.RequestMethod == http.MethodGet || statusErr.RequestMethod == http.MethodHead
+}
+
+// resumableReader resumes a download from the current offset if reading fails with a transient error.
+// The underlying reader must reopen the download on a seek, like the readers of the containerd fetcher
+// which request the remaining content with a range request.
+type resumableReader struct {
+	io.ReadSeeker
+	closer io.Closer
+
+	ctx    context.Context
+	log    logr.Logger
+	opts   RetryOptions
+	offset int64
+	// failures is the number of failed reads since data has been read the last time
+	failures int
+}
+
+// newResumableReader returns a reader that resumes the download of the given reader.
+// Readers which cannot seek are returned unchanged.
+func newResumableReader(ctx context.Context, log logr.Logger, opts RetryOptions, rc io.ReadCloser) io.ReadCloser {
+	rs, ok := rc.(io.ReadSeeker)
+	if !ok {
+		return rc
+	}
+	return &resumableReader{
+		ReadSeeker: rs,
+		closer:     rc,
+		ctx:        ctx,
+		log:        log,
+		opts:       opts,
+	}
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.ReadSeeker.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.failures = 0
+		}
+		if err == nil || err == io.EOF || !IsTransientError(err) {
+			return n, err
+		}
+		r.failures++
+		if r.failures >= r.opts.MaxAttempts {
+			return n, err
+		}
+
+		backoff := r.opts.backoff(r.failures)
+		r.log.V(5).Info("resuming download", "offset", r.offset, "attempt", r.failures, "backoff", backoff.String(), "error", err.Error())
+		if err := sleep(r.ctx, backoff); err != nil {
+			return n, err
+		}
+		if err := r.resume(); err != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume forces the underlying reader to open a new download at the current offset.
+// A seek to the current offset is a noop, therefore the reader is rewound first.
+func (r *resumableReader) resume() error {
+	if _, err := r.ReadSeeker.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := r.ReadSeeker.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *resumableReader) Close() error {
+	return r.closer.Close()
+}
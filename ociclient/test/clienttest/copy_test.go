@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clienttest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/credentials"
+	"github.com/gardener/component-cli/ociclient/test/faultyregistry"
+	"github.com/gardener/component-cli/pkg/testutils"
+)
+
+var _ = Describe("Copy", func() {
+
+	var (
+		// blobs are mounted within a registry, therefore a separate target registry is used to test uploads
+		srcRegistry *faultyregistry.Registry
+		tgtRegistry *faultyregistry.Registry
+		c           ociclient.Client
+	)
+
+	BeforeEach(func() {
+		srcRegistry = faultyregistry.New()
+		tgtRegistry = faultyregistry.New()
+		var err error
+		c, err = ociclient.NewClient(logr.Discard(),
+			ociclient.WithKeyring(credentials.New()),
+			ociclient.WithCache(cache.NewInMemoryCache()),
+			ociclient.AllowPlainHttp(true))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		srcRegistry.Close()
+		tgtRegistry.Close()
+	})
+
+	It("should copy the platforms of an image index in parallel and copy shared blobs only once", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		srcRepo := fmt.Sprintf("%s/source/image", srcRegistry.Addr)
+		tgtRepo := fmt.Sprintf("%s/target/image", tgtRegistry.Addr)
+
+		platforms := []string{"amd64", "arm64", "s390x"}
+		index := ocispecv1.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+		}
+		for _, arch := range platforms {
+			configData := []byte("config-" + arch)
+			layersData := [][]byte{
+				[]byte("shared-layer"),
+				[]byte("layer-" + arch),
+			}
+			_, manifestDesc, blobMap := testutils.CreateImage(ocispecv1.MediaTypeImageManifest, configData, layersData)
+			store := ociclient.GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
+				_, err := writer.Write(blobMap[desc.Digest])
+				return err
+			})
+			manifestRef := fmt.Sprintf("%s@%s", srcRepo, manifestDesc.Digest)
+			Expect(c.PushRawManifest(ctx, manifestRef, manifestDesc, blobMap[manifestDesc.Digest], ociclient.WithStore(store))).To(Succeed())
+
+			manifestDesc.Platform = &ocispecv1.Platform{
+				Architecture: arch,
+				OS:           "linux",
+			}
+			index.Manifests = append(index.Manifests, manifestDesc)
+		}
+		indexDesc, indexBytes := testutils.UploadTestIndex(ctx, c, srcRepo+":v0.1.0", ocispecv1.MediaTypeImageIndex, index)
+
+		trackingClient := &concurrencyTrackingClient{Client: c}
+		progress := []ociclient.CopyProgress{}
+		Expect(ociclient.Copy(ctx, trackingClient, srcRepo+":v0.1.0", tgtRepo+":v0.1.0",
+			ociclient.WithCopyConcurrency(2),
+			ociclient.WithCopyProgress(func(p ociclient.CopyProgress) {
+				progress = append(progress, p)
+			}))).To(Succeed())
+
+		actualIndexDesc, actualIndexBytes, err := c.GetRawManifest(ctx, tgtRepo+":v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actualIndexDesc).To(Equal(indexDesc))
+		Expect(actualIndexBytes).To(Equal(indexBytes))
+
+		// 3 configs, 3 platform layers and 1 shared layer
+		Expect(tgtRegistry.Requests(http.MethodPost, "/v2/target/image/blobs/uploads/")).To(Equal(7))
+		Expect(trackingClient.maxInFlight).To(Equal(2))
+
+		// 7 blobs, 3 manifests and the index
+		Expect(progress).To(HaveLen(11))
+		for i, p := range progress {
+			Expect(p.Completed).To(Equal(i + 1))
+			Expect(p.Total).To(Equal(11))
+		}
+		Expect(progress[10].Descriptor.Digest).To(Equal(indexDesc.Digest))
+	})
+
+	It("should skip blobs which already exist in the target repository", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		srcRef := fmt.Sprintf("%s/source/image:v0.1.0", srcRegistry.Addr)
+		tgtRef := fmt.Sprintf("%s/target/image:v0.1.0", tgtRegistry.Addr)
+		testutils.UploadTestImage(ctx, c, srcRef, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		_, manifest, err := c.GetRawManifest(ctx, srcRef)
+		Expect(err).ToNot(HaveOccurred())
+		var transferred int64
+		countTransferred := ociclient.WithCopyProgress(func(p ociclient.CopyProgress) {
+			transferred += p.Transferred
+		})
+
+		Expect(ociclient.Copy(ctx, c, srcRef, tgtRef, countTransferred)).To(Succeed())
+		Expect(tgtRegistry.Requests(http.MethodPut, "/v2/target/image/blobs/uploads/")).To(Equal(2))
+		Expect(transferred).To(Equal(int64(len("config") + len("layer") + len(manifest))))
+
+		transferred = 0
+		Expect(ociclient.Copy(ctx, c, srcRef, fmt.Sprintf("%s/target/image:v0.2.0", tgtRegistry.Addr), countTransferred)).To(Succeed())
+		Expect(tgtRegistry.Requests(http.MethodPut, "/v2/target/image/blobs/uploads/")).To(Equal(2))
+		// only the manifest is uploaded again
+		Expect(transferred).To(Equal(int64(len(manifest))))
+	})
+
+	It("should mount blobs from the source repository if both repositories are located in the same registry", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		srcRef := fmt.Sprintf("%s/staging/image:v0.1.0", srcRegistry.Addr)
+		tgtRef := fmt.Sprintf("%s/release/image:v0.1.0", srcRegistry.Addr)
+		manifestDesc, manifestBytes := testutils.UploadTestImage(ctx, c, srcRef, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		Expect(ociclient.Copy(ctx, c, srcRef, tgtRef)).To(Succeed())
+		Expect(srcRegistry.Requests(http.MethodGet, "/v2/staging/image/blobs/")).To(Equal(0))
+		Expect(srcRegistry.Requests(http.MethodPut, "/v2/release/image/blobs/uploads/")).To(Equal(0))
+		testutils.CompareRemoteManifest(ctx, c, tgtRef, manifestDesc, manifestBytes, []byte("config"), [][]byte{[]byte("layer")})
+	})
+
+	It("should upload a blob if it cannot be mounted", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		data := []byte("blob-data")
+		desc := ocispecv1.Descriptor{
+			MediaType: "application/octet-stream",
+			Digest:    digest.FromBytes(data),
+			Size:      int64(len(data)),
+		}
+		store := ociclient.GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
+			_, err := writer.Write(data)
+			return err
+		})
+
+		// the blob does not exist in the staging repository
+		Expect(c.PushBlob(ctx, fmt.Sprintf("%s/release/image", srcRegistry.Addr), desc,
+			ociclient.WithStore(store),
+			ociclient.WithMountFrom(fmt.Sprintf("%s/staging/image:v0.1.0", srcRegistry.Addr)))).To(Succeed())
+		Expect(srcRegistry.Requests(http.MethodPut, "/v2/release/image/blobs/uploads/")).To(Equal(1))
+
+		var buf bytes.Buffer
+		Expect(c.Fetch(ctx, fmt.Sprintf("%s/release/image", srcRegistry.Addr), desc, &buf)).To(Succeed())
+		Expect(buf.Bytes()).To(Equal(data))
+	})
+
+	It("should fail if a blob cannot be copied", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		srcRef := fmt.Sprintf("%s/source/image:v0.1.0", srcRegistry.Addr)
+		tgtRef := fmt.Sprintf("%s/target/image:v0.1.0", tgtRegistry.Addr)
+		testutils.UploadTestImage(ctx, c, srcRef, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		tgtRegistry.Inject(faultyregistry.Fault{Method: http.MethodPost, Path: "/blobs/uploads/", StatusCode: http.StatusForbidden, Times: 1})
+		err := ociclient.Copy(ctx, c, srcRef, tgtRef)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unable to copy blob"))
+
+		_, _, err = c.GetRawManifest(ctx, tgtRef)
+		Expect(err).To(HaveOccurred())
+	})
+
+})
+
+// concurrencyTrackingClient records the maximal number of parallel uploads.
+type concurrencyTrackingClient struct {
+	ociclient.Client
+
+	mux         sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *concurrencyTrackingClient) PushBlob(ctx context.Context, ref string, desc ocispecv1.Descriptor, opts ...ociclient.PushOption) error {
+	c.start()
+	defer c.done()
+	return c.Client.PushBlob(ctx, ref, desc, opts...)
+}
+
+func (c *concurrencyTrackingClient) PushRawManifest(ctx context.Context, ref string, desc ocispecv1.Descriptor, rawManifest []byte, opts ...ociclient.PushOption) error {
+	c.start()
+	defer c.done()
+	return c.Client.PushRawManifest(ctx, ref, desc, rawManifest, opts...)
+}
+
+func (c *concurrencyTrackingClient) start() {
+	c.mux.Lock()
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	c.mux.Unlock()
+	// uploads to the in-memory registry are too fast to overlap otherwise
+	time.Sleep(20 * time.Millisecond)
+}
+
+func (c *concurrencyTrackingClient) done() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.inFlight--
+}
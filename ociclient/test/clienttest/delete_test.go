@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clienttest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/credentials"
+	"github.com/gardener/component-cli/ociclient/test/faultyregistry"
+	"github.com/gardener/component-cli/pkg/testutils"
+)
+
+var _ = Describe("Delete", func() {
+
+	var (
+		registry *faultyregistry.Registry
+		c        ociclient.ExtendedClient
+	)
+
+	BeforeEach(func() {
+		registry = faultyregistry.New()
+		var err error
+		c, err = ociclient.NewClient(logr.Discard(),
+			ociclient.WithKeyring(credentials.New()),
+			ociclient.WithCache(cache.NewInMemoryCache()),
+			ociclient.AllowPlainHttp(true))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		registry.Close()
+	})
+
+	It("should delete the manifest of a tag by its digest", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		ref := fmt.Sprintf("%s/test/image:v0.1.0", registry.Addr)
+		manifestDesc, _ := testutils.UploadTestImage(ctx, c, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		Expect(c.DeleteManifest(ctx, ref)).To(Succeed())
+		Expect(registry.Requests(http.MethodDelete, "/v2/test/image/manifests/"+manifestDesc.Digest.String())).To(Equal(1))
+
+		_, _, err := c.GetRawManifest(ctx, fmt.Sprintf("%s/test/image@%s", registry.Addr, manifestDesc.Digest))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should delete a tag without deleting its manifest", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		ref := fmt.Sprintf("%s/test/image:v0.1.0", registry.Addr)
+		manifestDesc, _ := testutils.UploadTestImage(ctx, c, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		Expect(c.DeleteTag(ctx, ref)).To(Succeed())
+		Expect(registry.Requests(http.MethodDelete, "/v2/test/image/manifests/v0.1.0")).To(Equal(1))
+
+		_, _, err := c.GetRawManifest(ctx, ref)
+		Expect(err).To(HaveOccurred())
+		_, _, err = c.GetRawManifest(ctx, fmt.Sprintf("%s/test/image@%s", registry.Addr, manifestDesc.Digest))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should delete the manifest if the registry does not support the deletion of tags", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		ref := fmt.Sprintf("%s/test/image:v0.1.0", registry.Addr)
+		manifestDesc, _ := testutils.UploadTestImage(ctx, c, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		registry.Inject(faultyregistry.Fault{Method: http.MethodDelete, Path: "/manifests/v0.1.0", StatusCode: http.StatusMethodNotAllowed, Times: 1})
+		Expect(c.DeleteTag(ctx, ref)).To(Succeed())
+		Expect(registry.Requests(http.MethodDelete, "/v2/test/image/manifests/"+manifestDesc.Digest.String())).To(Equal(1))
+
+		_, _, err := c.GetRawManifest(ctx, fmt.Sprintf("%s/test/image@%s", registry.Addr, manifestDesc.Digest))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail if the manifest does not exist", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		err := c.DeleteTag(ctx, fmt.Sprintf("%s/test/image:v0.1.0", registry.Addr))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail to delete a tag of a ref without a tag", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		err := c.DeleteTag(ctx, fmt.Sprintf("%s/test/image@%s", registry.Addr, digest.FromString("manifest")))
+		Expect(err).To(HaveOccurred())
+	})
+
+})
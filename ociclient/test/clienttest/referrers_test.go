@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clienttest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/credentials"
+	"github.com/gardener/component-cli/ociclient/test/faultyregistry"
+	"github.com/gardener/component-cli/pkg/testutils"
+)
+
+const (
+	signatureArtifactType = "application/vnd.example.signature.v1+json"
+	sbomArtifactType      = "application/vnd.example.sbom.v1+json"
+)
+
+var _ = Describe("Referrers", func() {
+
+	var (
+		registry *faultyregistry.Registry
+		c        ociclient.ExtendedClient
+	)
+
+	BeforeEach(func() {
+		registry = faultyregistry.New()
+		var err error
+		c, err = ociclient.NewClient(logr.Discard(),
+			ociclient.WithKeyring(credentials.New()),
+			ociclient.WithCache(cache.NewInMemoryCache()),
+			ociclient.AllowPlainHttp(true))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		registry.Close()
+	})
+
+	It("should list the referrers of an artifact from the referrers tag", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		ref := fmt.Sprintf("%s/test/image:v0.1.0", registry.Addr)
+		subject, _ := testutils.UploadTestImage(ctx, c, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+		signature := pushReferrer(ctx, c, fmt.Sprintf("%s/test/image", registry.Addr), subject, signatureArtifactType, []byte("signature"))
+		sbom := pushReferrer(ctx, c, fmt.Sprintf("%s/test/image", registry.Addr), subject, sbomArtifactType, []byte("sbom"))
+
+		referrers, err := c.ListReferrers(ctx, ref, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(HaveLen(2))
+		Expect(referrers[0].Digest).To(Equal(signature.Digest))
+		Expect(referrers[0].ArtifactType).To(Equal(signatureArtifactType))
+		Expect(referrers[1].Digest).To(Equal(sbom.Digest))
+
+		referrers, err = c.ListReferrers(ctx, ref, sbomArtifactType)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(HaveLen(1))
+		Expect(referrers[0].Digest).To(Equal(sbom.Digest))
+
+		tags, err := c.ListTags(ctx, ref)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(ContainElement("sha256-" + subject.Digest.Encoded()))
+	})
+
+	It("should not lose referrers which are pushed concurrently to the referrers tag", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		ref := fmt.Sprintf("%s/test/image:v0.1.0", registry.Addr)
+		subject, _ := testutils.UploadTestImage(ctx, c, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				pushReferrer(ctx, c, fmt.Sprintf("%s/test/image", registry.Addr), subject, signatureArtifactType, []byte(fmt.Sprintf("signature-%d", i)))
+			}(i)
+		}
+		wg.Wait()
+
+		referrers, err := c.ListReferrers(ctx, ref, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(HaveLen(5))
+	})
+
+	It("should distinguish referrers tags from other tags", func() {
+		Expect(ociclient.IsReferrersTag(ociclient.ReferrersTag(digest.FromString("subject")))).To(BeTrue())
+		Expect(ociclient.IsReferrersTag("v0.1.0")).To(BeFalse())
+		Expect(ociclient.IsReferrersTag("v0.1.0-dev")).To(BeFalse())
+		Expect(ociclient.IsReferrersTag("sha256-abc")).To(BeFalse())
+	})
+
+	It("should return no referrers if an artifact is not referred to", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		ref := fmt.Sprintf("%s/test/image:v0.1.0", registry.Addr)
+		testutils.UploadTestImage(ctx, c, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+		referrers, err := c.ListReferrers(ctx, ref, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(BeEmpty())
+	})
+
+	It("should list the referrers of an artifact with the referrers api", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		subject := digest.FromString("subject")
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/v2/" {
+				// first auth discovery call by the library
+				w.WriteHeader(200)
+				return
+			}
+			Expect(req.URL.Path).To(Equal("/v2/test/image/referrers/" + subject.String()))
+			Expect(req.URL.Query().Get("artifactType")).To(Equal(sbomArtifactType))
+			// the registry does not apply the filter
+			w.Header().Set("Content-Type", ocispecv1.MediaTypeImageIndex)
+			_, _ = w.Write([]byte(`{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + digest.FromString("signature").String() + `", "size": 10, "artifactType": "` + signatureArtifactType + `"},
+    {"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "` + digest.FromString("sbom").String() + `", "size": 10, "artifactType": "` + sbomArtifactType + `"}
+  ]
+}`))
+		}))
+		defer server.Close()
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		referrers, err := c.ListReferrers(ctx, fmt.Sprintf("%s/test/image@%s", serverURL.Host, subject), sbomArtifactType)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(HaveLen(1))
+		Expect(referrers[0].Digest).To(Equal(digest.FromString("sbom")))
+	})
+
+	It("should copy the referrers of an artifact", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		tgtRegistry := faultyregistry.New()
+		defer tgtRegistry.Close()
+		srcRef := fmt.Sprintf("%s/source/image:v0.1.0", registry.Addr)
+		tgtRef := fmt.Sprintf("%s/target/image:v0.1.0", tgtRegistry.Addr)
+		subject, _ := testutils.UploadTestImage(ctx, c, srcRef, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+		signature := pushReferrer(ctx, c, fmt.Sprintf("%s/source/image", registry.Addr), subject, signatureArtifactType, []byte("signature"))
+
+		Expect(ociclient.Copy(ctx, c, srcRef, tgtRef)).To(Succeed())
+		referrers, err := c.ListReferrers(ctx, tgtRef, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(BeEmpty())
+
+		Expect(ociclient.Copy(ctx, c, srcRef, tgtRef, ociclient.WithCopyReferrers(true))).To(Succeed())
+		referrers, err = c.ListReferrers(ctx, tgtRef, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(referrers).To(HaveLen(1))
+		Expect(referrers[0].Digest).To(Equal(signature.Digest))
+		Expect(referrers[0].ArtifactType).To(Equal(signatureArtifactType))
+	})
+
+})
+
+// pushReferrer pushes an artifact with the given data as its only layer which refers to the subject.
+func pushReferrer(ctx context.Context, client ociclient.Client, repo string, subject ocispecv1.Descriptor, artifactType string, data []byte) ocispecv1.Descriptor {
+	config := []byte("{}")
+	blobs := map[digest.Digest][]byte{
+		digest.FromBytes(config): config,
+		digest.FromBytes(data):   data,
+	}
+	manifest := &ociclient.ReferrerManifest{
+		ArtifactType: artifactType,
+		Config: ocispecv1.Descriptor{
+			MediaType: "application/vnd.oci.empty.v1+json",
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		},
+		Layers: []ocispecv1.Descriptor{
+			{
+				MediaType: "application/octet-stream",
+				Digest:    digest.FromBytes(data),
+				Size:      int64(len(data)),
+			},
+		},
+		Subject: &subject,
+	}
+	desc, rawManifest, err := ociclient.CreateDescriptorFromReferrerManifest(manifest)
+	Expect(err).ToNot(HaveOccurred())
+	store := ociclient.GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
+		_, err := writer.Write(blobs[desc.Digest])
+		return err
+	})
+	Expect(client.PushRawManifest(ctx, fmt.Sprintf("%s@%s", repo, desc.Digest), desc, rawManifest, ociclient.WithStore(store))).To(Succeed())
+	return desc
+}
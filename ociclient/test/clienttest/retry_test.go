@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package clienttest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/credentials"
+	"github.com/gardener/component-cli/ociclient/test/faultyregistry"
+	"github.com/gardener/component-cli/pkg/testutils"
+)
+
+var _ = Describe("Retry", func() {
+
+	var (
+		registry     *faultyregistry.Registry
+		retryOptions ociclient.RetryOptions
+	)
+
+	BeforeEach(func() {
+		registry = faultyregistry.New()
+		retryOptions = ociclient.RetryOptions{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+		}
+	})
+
+	AfterEach(func() {
+		registry.Close()
+	})
+
+	newClient := func() ociclient.Client {
+		c, err := ociclient.NewClient(logr.Discard(),
+			ociclient.WithKeyring(credentials.New()),
+			ociclient.WithCache(cache.NewInMemoryCache()),
+			ociclient.AllowPlainHttp(true),
+			ociclient.WithRetryOptions(retryOptions))
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	Context("Transport", func() {
+
+		It("should retry idempotent requests which fail with a retryable status code", func() {
+			registry.Inject(faultyregistry.Fault{Method: http.MethodGet, Path: "/v2/", StatusCode: http.StatusBadGateway, Times: 2})
+			trp := ociclient.NewRetryingTransport(logr.Discard(), http.DefaultTransport, retryOptions)
+
+			req, err := http.NewRequest(http.MethodGet, registry.URL()+"/v2/", nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := trp.RoundTrip(req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(registry.Requests(http.MethodGet, "/v2/")).To(Equal(3))
+		})
+
+		It("should retry idempotent requests whose connection is reset", func() {
+			registry.Inject(faultyregistry.Fault{Method: http.MethodGet, Path: "/v2/", Reset: true, Times: 1})
+			trp := ociclient.NewRetryingTransport(logr.Discard(), http.DefaultTransport, retryOptions)
+
+			req, err := http.NewRequest(http.MethodGet, registry.URL()+"/v2/", nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := trp.RoundTrip(req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(registry.Requests(http.MethodGet, "/v2/")).To(Equal(2))
+		})
+
+		It("should return the last response if all attempts fail", func() {
+			registry.Inject(faultyregistry.Fault{Method: http.MethodGet, Path: "/v2/", StatusCode: http.StatusServiceUnavailable, Times: 10})
+			trp := ociclient.NewRetryingTransport(logr.Discard(), http.DefaultTransport, retryOptions)
+
+			req, err := http.NewRequest(http.MethodGet, registry.URL()+"/v2/", nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := trp.RoundTrip(req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(registry.Requests(http.MethodGet, "/v2/")).To(Equal(3))
+		})
+
+		It("should not retry non-idempotent requests", func() {
+			registry.Inject(faultyregistry.Fault{Method: http.MethodPost, Path: "/blobs/uploads/", StatusCode: http.StatusBadGateway, Times: 1})
+			trp := ociclient.NewRetryingTransport(logr.Discard(), http.DefaultTransport, retryOptions)
+
+			req, err := http.NewRequest(http.MethodPost, registry.URL()+"/v2/test/blobs/uploads/", nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := trp.RoundTrip(req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+			Expect(registry.Requests(http.MethodPost, "/blobs/uploads/")).To(Equal(1))
+		})
+
+		It("should honour the Retry-After header", func() {
+			registry.Inject(faultyregistry.Fault{Method: http.MethodGet, Path: "/v2/", StatusCode: http.StatusTooManyRequests, RetryAfter: "1", Times: 1})
+			trp := ociclient.NewRetryingTransport(logr.Discard(), http.DefaultTransport, retryOptions)
+
+			req, err := http.NewRequest(http.MethodGet, registry.URL()+"/v2/", nil)
+			Expect(err).ToNot(HaveOccurred())
+			start := time.Now()
+			resp, err := trp.RoundTrip(req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		})
+
+		It("should stop retrying if the context is canceled", func() {
+			registry.Inject(faultyregistry.Fault{Method: http.MethodGet, Path: "/v2/", StatusCode: http.StatusTooManyRequests, RetryAfter: "60", Times: 1})
+			trp := ociclient.NewRetryingTransport(logr.Discard(), http.DefaultTransport, retryOptions)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, registry.URL()+"/v2/", nil)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = trp.RoundTrip(req)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+
+	Context("Client", func() {
+
+		It("should retry uploads and downloads which fail with transient errors", func() {
+			ctx := context.Background()
+			defer ctx.Done()
+			ref := fmt.Sprintf("%s/test/artifact:v0.1.0", registry.Addr)
+			configData := []byte("config-data")
+			layersData := [][]byte{
+				[]byte("layer-1-data"),
+				[]byte("layer-2-data"),
+			}
+
+			registry.Inject(faultyregistry.Fault{Method: http.MethodPost, Path: "/blobs/uploads/", StatusCode: http.StatusBadGateway, Times: 2})
+			registry.Inject(faultyregistry.Fault{Method: http.MethodPut, Path: "/manifests/", StatusCode: http.StatusServiceUnavailable, Times: 1})
+			registry.Inject(faultyregistry.Fault{Method: http.MethodHead, Path: "/manifests/", Reset: true, Times: 1})
+			c := newClient()
+			manifestDesc, manifestBytes := testutils.UploadTestImage(ctx, c, ref, ocispecv1.MediaTypeImageManifest, configData, layersData)
+			Expect(registry.Requests(http.MethodPut, "/manifests/")).To(Equal(2))
+
+			registry.Inject(faultyregistry.Fault{Method: http.MethodGet, Path: "/blobs/", Truncate: true, Times: 2})
+			testutils.CompareRemoteManifest(ctx, newClient(), ref, manifestDesc, manifestBytes, configData, layersData)
+		})
+
+		It("should resume an interrupted blob download", func() {
+			ctx := context.Background()
+			defer ctx.Done()
+			ref := fmt.Sprintf("%s/test/blob", registry.Addr)
+			data := make([]byte, 1024*1024)
+			_, err := rand.Read(data)
+			Expect(err).ToNot(HaveOccurred())
+			desc := ocispecv1.Descriptor{
+				MediaType: "application/octet-stream",
+				Digest:    digest.FromBytes(data),
+				Size:      int64(len(data)),
+			}
+			store := ociclient.GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
+				_, err := writer.Write(data)
+				return err
+			})
+			c := newClient()
+			Expect(c.PushBlob(ctx, ref, desc, ociclient.WithStore(store))).To(Succeed())
+
+			registry.Inject(faultyregistry.Fault{Method: http.MethodGet, Path: "/blobs/", Truncate: true, Times: 2})
+			var buf bytes.Buffer
+			Expect(c.Fetch(ctx, ref, desc, &buf)).To(Succeed())
+			Expect(buf.Bytes()).To(Equal(data))
+			Expect(registry.Requests(http.MethodGet, "/blobs/")).To(Equal(3))
+		})
+
+		It("should fail if an upload fails with a permanent error", func() {
+			ctx := context.Background()
+			defer ctx.Done()
+			ref := fmt.Sprintf("%s/test/blob", registry.Addr)
+			data := []byte("blob-data")
+			desc := ocispecv1.Descriptor{
+				MediaType: "application/octet-stream",
+				Digest:    digest.FromBytes(data),
+				Size:      int64(len(data)),
+			}
+			store := ociclient.GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
+				_, err := writer.Write(data)
+				return err
+			})
+
+			registry.Inject(faultyregistry.Fault{Method: http.MethodPost, Path: "/blobs/uploads/", StatusCode: http.StatusForbidden, Times: 1})
+			Expect(newClient().PushBlob(ctx, ref, desc, ociclient.WithStore(store))).ToNot(Succeed())
+			Expect(registry.Requests(http.MethodPost, "/blobs/uploads/")).To(Equal(1))
+		})
+	})
+
+})
@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package faultyregistry
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+)
+
+// Fault describes a fault that is injected into the responses of matching requests.
+type Fault struct {
+	// Method is the http method of the matching requests. All methods match if empty.
+	Method string
+	// Path must be contained in the url path of the matching requests.
+	Path string
+	// Times is the number of matching requests the fault is injected into.
+	Times int
+
+	// StatusCode is the status code that is returned instead of the response of the registry.
+	StatusCode int
+	// RetryAfter is the value of the Retry-After header that is returned with the status code.
+	RetryAfter string
+	// Reset closes the connection without writing a response.
+	Reset bool
+	// Truncate writes only the first half of the response body of the registry and closes the connection afterwards.
+	Truncate bool
+}
+
+func (f *Fault) matches(req *http.Request) bool {
+	if f.Times <= 0 {
+		return false
+	}
+	if len(f.Method) != 0 && f.Method != req.Method {
+		return false
+	}
+	return strings.Contains(req.URL.Path, f.Path)
+}
+
+// Registry is an in-memory oci registry which injects faults into the responses of matching requests.
+// Blobs are only accessible in the repositories they have been uploaded or mounted to
+// and the deletion of a manifest also deletes its tags, like in a distribution registry.
+type Registry struct {
+	server   *httptest.Server
+	registry http.Handler
+
+	// Addr is the host of the registry in the format "ip:port"
+	Addr string
+
+	mux      sync.Mutex
+	faults   []*Fault
+	requests []*http.Request
+	// blobs contains the digests of the blobs of every repository
+	blobs map[string]map[string]bool
+	// tags contains the manifest digest of the tags of every repository
+	tags map[string]map[string]string
+}
+
+// New starts a new registry that has to be closed with Close().
+func New() *Registry {
+	r := &Registry{
+		registry: registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))),
+		blobs:    map[string]map[string]bool{},
+		tags:     map[string]map[string]string{},
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.handle))
+	r.Addr = strings.TrimPrefix(r.server.URL, "http://")
+	return r
+}
+
+// Close stops the registry.
+func (r *Registry) Close() {
+	r.server.Close()
+}
+
+// URL returns the base url of the registry.
+func (r *Registry) URL() string {
+	return r.server.URL
+}
+
+// Inject adds a fault which is injected into the next matching requests.
+func (r *Registry) Inject(fault Fault) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.faults = append(r.faults, &fault)
+}
+
+// Requests returns the number of received requests with the given method whose url path contains the given path.
+// Requests of all methods are counted if the method is empty.
+func (r *Registry) Requests(method, path string) int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	count := 0
+	for _, req := range r.requests {
+		if (len(method) == 0 || req.Method == method) && strings.Contains(req.URL.Path, path) {
+			count++
+		}
+	}
+	return count
+}
+
+// nextFault returns the first fault that matches the request and records the request.
+func (r *Registry) nextFault(req *http.Request) *Fault {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.requests = append(r.requests, req)
+	for _, fault := range r.faults {
+		if fault.matches(req) {
+			fault.Times--
+			return fault
+		}
+	}
+	return nil
+}
+
+// hasBlob returns whether a blob has been uploaded or mounted to a repository.
+func (r *Registry) hasBlob(repo, digest string) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.blobs[repo][digest]
+}
+
+func (r *Registry) addBlob(repo, digest string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.blobs[repo] == nil {
+		r.blobs[repo] = map[string]bool{}
+	}
+	r.blobs[repo][digest] = true
+}
+
+func (r *Registry) handle(w http.ResponseWriter, req *http.Request) {
+	fault := r.nextFault(req)
+	switch {
+	case fault == nil:
+		r.serve(w, req)
+	case fault.Reset:
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			panic(err)
+		}
+		conn.Close()
+	case fault.Truncate:
+		r.registry.ServeHTTP(&truncatingResponseWriter{ResponseWriter: w}, req)
+	default:
+		if len(fault.RetryAfter) != 0 {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		w.WriteHeader(fault.StatusCode)
+	}
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	if repo, reference, ok := parsePath(req.URL.Path, "manifests"); ok {
+		r.serveManifests(w, req, repo, reference)
+		return
+	}
+	r.serveRepositoryBlobs(w, req)
+}
+
+// serveManifests deletes the tags of a manifest when the manifest is deleted by its digest,
+// as the in-memory registry stores tags and digests independently.
+func (r *Registry) serveManifests(w http.ResponseWriter, req *http.Request, repo, reference string) {
+	sw := &statusResponseWriter{ResponseWriter: w}
+	r.registry.ServeHTTP(sw, req)
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	switch {
+	case req.Method == http.MethodPut && sw.status == http.StatusCreated && !strings.Contains(reference, ":"):
+		if r.tags[repo] == nil {
+			r.tags[repo] = map[string]string{}
+		}
+		r.tags[repo][reference] = sw.Header().Get("Docker-Content-Digest")
+	case req.Method == http.MethodDelete && sw.status == http.StatusAccepted && !strings.Contains(reference, ":"):
+		delete(r.tags[repo], reference)
+	case req.Method == http.MethodDelete && sw.status == http.StatusAccepted:
+		for tag, digest := range r.tags[repo] {
+			if digest != reference {
+				continue
+			}
+			tagReq := httptest.NewRequest(http.MethodDelete, path.Join("/v2", repo, "manifests", tag), nil)
+			r.registry.ServeHTTP(httptest.NewRecorder(), tagReq)
+			delete(r.tags[repo], tag)
+		}
+	}
+}
+
+// serveRepositoryBlobs scopes the blobs of the in-memory registry, which are shared by all repositories, to single repositories.
+// It also implements cross repository blob mounts.
+func (r *Registry) serveRepositoryBlobs(w http.ResponseWriter, req *http.Request) {
+	repo, target, ok := parsePath(req.URL.Path, "blobs")
+	if !ok {
+		r.registry.ServeHTTP(w, req)
+		return
+	}
+
+	switch {
+	case (req.Method == http.MethodHead || req.Method == http.MethodGet) && target != "uploads" && !r.hasBlob(repo, target):
+		w.WriteHeader(http.StatusNotFound)
+	case req.Method == http.MethodPost && len(req.URL.Query().Get("mount")) != 0 && len(req.URL.Query().Get("from")) != 0:
+		digest := req.URL.Query().Get("mount")
+		if !r.hasBlob(req.URL.Query().Get("from"), digest) {
+			// the registry falls back to a regular upload
+			r.registry.ServeHTTP(w, req)
+			return
+		}
+		r.addBlob(repo, digest)
+		w.Header().Set("Location", path.Join("/v2", repo, "blobs", digest))
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		sw := &statusResponseWriter{ResponseWriter: w}
+		r.registry.ServeHTTP(sw, req)
+		if sw.status == http.StatusCreated && len(sw.Header().Get("Docker-Content-Digest")) != 0 {
+			r.addBlob(repo, sw.Header().Get("Docker-Content-Digest"))
+		}
+	}
+}
+
+// parsePath returns the repository and the following path element of a path like /v2/{name}/{kind}/{reference}/...
+func parsePath(urlPath, kind string) (string, string, bool) {
+	elem := strings.Split(strings.Trim(urlPath, "/"), "/")
+	for i := len(elem) - 2; i > 1; i-- {
+		if elem[i] == kind && elem[0] == "v2" {
+			return strings.Join(elem[1:i], "/"), elem[i+1], true
+		}
+	}
+	return "", "", false
+}
+
+// statusResponseWriter records the status code of a response.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// truncatingResponseWriter writes the first half of the announced body and aborts the response afterwards.
+type truncatingResponseWriter struct {
+	http.ResponseWriter
+	written int
+}
+
+func (w *truncatingResponseWriter) Write(p []byte) (int, error) {
+	size, err := strconv.Atoi(w.Header().Get("Content-Length"))
+	if err != nil {
+		size = len(p)
+	}
+	remaining := size/2 - w.written
+	if remaining > len(p) {
+		n, err := w.ResponseWriter.Write(p)
+		w.written += n
+		return n, err
+	}
+	if remaining > 0 {
+		_, _ = w.ResponseWriter.Write(p[:remaining])
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	// the server closes the connection without completing the response
+	panic(http.ErrAbortHandler)
+}
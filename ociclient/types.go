@@ -33,6 +33,8 @@ type Client interface {
 	// PushRawManifest uploads the given raw manifest to the given reference.
 	// If the manifest is multi arch (image index/manifest list), only the multi arch manifest is pushed.
 	// The referenced single arch manifests must be pushed individiually before.
+	// A manifest with a subject is added to the referrers tag of the subject if the registry does not support the referrers api.
+	// The updates of a referrers tag are only synchronised within the client, concurrent pushes of other clients may overwrite them.
 	PushRawManifest(ctx context.Context, ref string, desc ocispecv1.Descriptor, rawManifest []byte, opts ...PushOption) error
 
 	// GetManifest returns the ocispec Manifest for a reference
@@ -59,6 +61,15 @@ type ExtendedClient interface {
 	ListTags(ctx context.Context, ref string) ([]string, error)
 	// ListRepositories lists all repositories for the given registry host.
 	ListRepositories(ctx context.Context, registryHost string) ([]string, error)
+	// DeleteManifest deletes the manifest of the given ref.
+	// Tags are resolved to their digest so that the manifest and all of its tags are deleted.
+	DeleteManifest(ctx context.Context, ref string) error
+	// DeleteTag deletes the tag of the given ref.
+	// The referenced manifest is deleted instead if the registry does not support the deletion of tags.
+	DeleteTag(ctx context.Context, ref string) error
+	// ListReferrers lists all manifests whose subject is the manifest of the given ref.
+	// The referrers are filtered by the artifact type if it is not empty.
+	ListReferrers(ctx context.Context, ref, artifactType string) ([]Referrer, error)
 }
 
 // Resolver provides remotes based on a locator.
@@ -91,6 +102,9 @@ type PushOption interface {
 type PushOptions struct {
 	// Store is the oci cache to be used by the client
 	Store Store
+	// MountFrom is the reference of an artifact in another repository of the same registry.
+	// Blobs are mounted from its repository instead of being uploaded if the registry supports cross repository mounts.
+	MountFrom string
 }
 
 // ApplyOptions applies the given list options on these options,
@@ -120,6 +134,14 @@ func (c WithStoreOption) ApplyPushOption(options *PushOptions) {
 	options.Store = c.Store
 }
 
+// WithMountFrom configures the reference of an artifact whose repository blobs are mounted from.
+// The option is ignored if the artifact is located in another registry.
+type WithMountFrom string
+
+func (c WithMountFrom) ApplyPushOption(options *PushOptions) {
+	options.MountFrom = string(c)
+}
+
 // Options contains all client options to configure the oci client.
 type Options struct {
 	// Paths configures local paths to search for docker configuration files
@@ -144,6 +166,10 @@ type Options struct {
 	CustomMediaTypes sets.String
 
 	HTTPClient *http.Client
+
+	// RetryOptions configures the retries of requests and operations which failed with a transient error.
+	// The DefaultRetryOptions are used if not given.
+	RetryOptions *RetryOptions
 }
 
 // Option is the interface to specify different cache options
@@ -224,3 +250,11 @@ func (c WithHTTPClient) ApplyOption(options *Options) {
 	client := http.Client(c)
 	options.HTTPClient = &client
 }
+
+// WithRetryOptions configures the retries of requests and operations which failed with a transient error.
+type WithRetryOptions RetryOptions
+
+func (c WithRetryOptions) ApplyOption(options *Options) {
+	retryOptions := RetryOptions(c)
+	options.RetryOptions = &retryOptions
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
 	"github.com/gardener/component-spec/bindings-go/ctf"
@@ -34,6 +35,8 @@ import (
 
 	ociopts "github.com/gardener/component-cli/ociclient/options"
 	"github.com/gardener/component-cli/pkg/logger"
+	"github.com/gardener/component-cli/pkg/transport/journal"
+	"github.com/gardener/component-cli/pkg/transport/report"
 	"github.com/gardener/component-cli/pkg/utils"
 )
 
@@ -69,6 +72,15 @@ type CopyOptions struct {
 	// ReplaceOCIRefs contains replace expressions for manipulating upload refs of resources with accessType == ociRegistry
 	ReplaceOCIRefs []string
 
+	// JournalPath is the path to the journal file which records copied artifacts and component descriptors.
+	// Defaults to a file in the cache dir which is derived from the arguments.
+	JournalPath string
+	// Resume specifies if the work recorded in the journal of a previous run should be skipped.
+	Resume bool
+	// ReportPath is the path to the file the copy report is written to.
+	// +optional
+	ReportPath string
+
 	// OciOptions contains all exposed options to configure the oci client.
 	OciOptions ociopts.Options
 }
@@ -122,6 +134,13 @@ func (o *CopyOptions) Run(ctx context.Context, log logr.Logger, fs vfs.FileSyste
 		replaceOCIRefs[splittedReplace[0]] = splittedReplace[1]
 	}
 
+	j, err := journal.Open(o.JournalPath, o.Resume)
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+	log.V(3).Info("use journal", "path", o.JournalPath)
+
 	c := Copier{
 		SrcRepoCtx:                     cdv2.NewOCIRegistryRepository(o.SourceRepository, ""),
 		TargetRepoCtx:                  cdv2.NewOCIRegistryRepository(o.TargetRepository, ""),
@@ -136,10 +155,24 @@ func (o *CopyOptions) Run(ctx context.Context, log logr.Logger, fs vfs.FileSyste
 		TargetArtifactRepository:       o.TargetArtifactRepository,
 		ConvertToRelativeOCIReferences: o.ConvertToRelativeOCIReferences,
 		ReplaceOCIRefs:                 replaceOCIRefs,
+		Journal:                        j,
+	}
+	if len(o.ReportPath) != 0 {
+		c.Report = report.New()
 	}
 
-	if err := c.Copy(ctx, o.ComponentName, o.ComponentVersion); err != nil {
-		return err
+	copyErr := c.Copy(ctx, o.ComponentName, o.ComponentVersion)
+	if c.Report != nil {
+		c.Report.Finish()
+		if err := c.Report.Write(fs, o.ReportPath); err != nil {
+			if copyErr == nil {
+				return err
+			}
+			log.Error(err, "unable to write report")
+		}
+	}
+	if copyErr != nil {
+		return copyErr
 	}
 
 	fmt.Printf("Successfully copied component descriptor %s:%s from %s to %s\n", o.ComponentName, o.ComponentVersion, o.SourceRepository, o.TargetRepository)
@@ -156,6 +189,10 @@ func (o *CopyOptions) Complete(args []string) error {
 		return fmt.Errorf("unable to get oci cache directory: %w", err)
 	}
 
+	if len(o.JournalPath) == 0 {
+		o.JournalPath = journal.DefaultPath(o.OciOptions.CacheDir, "copy", o.ComponentName, o.ComponentVersion, o.SourceRepository, o.TargetRepository)
+	}
+
 	if err := o.Validate(); err != nil {
 		return err
 	}
@@ -176,6 +213,11 @@ func (o *CopyOptions) Validate() error {
 	if len(o.TargetRepository) == 0 {
 		return errors.New("a target repository has to be specified")
 	}
+	if len(o.ReportPath) != 0 {
+		if _, err := report.FormatFromPath(o.ReportPath); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -192,6 +234,9 @@ func (o *CopyOptions) AddFlags(fs *pflag.FlagSet) {
 		"source repository where realtiove oci artifacts are copied from. This is only relevant if artifacts are copied by value and it will be defaulted to the source component repository")
 	fs.BoolVar(&o.ConvertToRelativeOCIReferences, "relative-urls", false, "converts all copied oci artifacts to relative urls")
 	fs.StringSliceVar(&o.ReplaceOCIRefs, "replace-oci-ref", []string{}, "list of replace expressions in the format left:right. For every resource with accessType == "+cdv2.OCIRegistryType+", all occurences of 'left' in the target ref are replaced with 'right' before the upload")
+	fs.StringVar(&o.JournalPath, "journal", "", "path to the journal file which records every copied oci artifact and component descriptor. defaults to a file in the cache dir which is derived from the arguments.")
+	fs.BoolVar(&o.Resume, "resume", false, "resume an interrupted copy by skipping the work recorded in the journal. recorded entries are only skipped if the digests of their source and target are unchanged.")
+	fs.StringVar(&o.ReportPath, "report", "", "path to a file the copy report is written to. the report contains the outcome, digests, transferred bytes, and duration of every resource. the format is derived from the file extension, one of .json, .yaml, .yml.")
 	o.OciOptions.AddFlags(fs)
 }
 
@@ -222,10 +267,53 @@ type Copier struct {
 	ConvertToRelativeOCIReferences bool
 	// ReplaceOCIRefs contains replace expressions for manipulating upload refs of resources with accessType == ociRegistry
 	ReplaceOCIRefs map[string]string
+	// Journal records copied oci artifacts and component descriptors.
+	// Work which is already recorded in the journal is skipped.
+	// +optional
+	Journal *journal.Journal
+	// Report collects the outcome of all component descriptors and resources.
+	// +optional
+	Report *report.Report
 }
 
+// Copy copies a component descriptor and, if configured, its references and oci artifacts.
 func (c *Copier) Copy(ctx context.Context, name, version string) error {
+	start := time.Now()
+	err := c.copy(ctx, name, version)
+	c.reportComponent(name, version, func(cr *report.ComponentReport) {
+		if err != nil || len(cr.Status) == 0 {
+			cr.Status, cr.Error = report.StatusFromError(err)
+		}
+		cr.Duration = report.Since(start)
+	})
+	return err
+}
+
+func (c *Copier) copy(ctx context.Context, name, version string) error {
 	log := logr.FromContextOrDiscard(ctx).WithValues("component", name, "version", version)
+	sourceRef, _ := components.OCIRef(c.SrcRepoCtx, name, version)
+	c.reportComponent(name, version, func(cr *report.ComponentReport) {
+		cr.SourceRef = sourceRef
+	})
+
+	// the manifest digest of the source component descriptor is recorded in the journal to detect changed sources on resume
+	var sourceDigest string
+	if c.Journal != nil {
+		desc, _, err := c.OciClient.GetRawManifest(ctx, sourceRef)
+		if err != nil {
+			return fmt.Errorf("unable to get manifest of component descriptor %s: %w", sourceRef, err)
+		}
+		sourceDigest = desc.Digest.String()
+	}
+
+	if c.isRecorded(ctx, journal.ComponentKey(name, version), sourceDigest) {
+		log.Info("skip component descriptor which has already been copied")
+		c.reportComponent(name, version, func(cr *report.ComponentReport) {
+			cr.Status = report.StatusSkipped
+		})
+		return nil
+	}
+
 	log.Info("copy component descriptor")
 	cd, blobs, err := c.CompResolver.ResolveWithBlobResolver(ctx, c.SrcRepoCtx, name, version)
 	if err != nil {
@@ -245,6 +333,9 @@ func (c *Copier) Copy(ctx context.Context, name, version string) error {
 	if !c.Force && !c.CopyByValue {
 		if _, err := c.CompResolver.Resolve(ctx, c.TargetRepoCtx, name, version); err == nil {
 			log.V(3).Info("Component already exists. Nothing to copy.")
+			c.reportComponent(name, version, func(cr *report.ComponentReport) {
+				cr.Status = report.StatusSkipped
+			})
 			return nil
 		}
 	}
@@ -253,8 +344,33 @@ func (c *Copier) Copy(ctx context.Context, name, version string) error {
 		return fmt.Errorf("unble to inject target repository: %w", err)
 	}
 
+	ref, err := components.OCIRef(c.TargetRepoCtx, name, version)
+	if err != nil {
+		return fmt.Errorf("invalid component reference: %w", err)
+	}
+
+	// the resource reports are added to the report when the copy has finished, even if it failed
+	resReports := make([]report.ResourceReport, len(cd.Resources))
+	for i, res := range cd.Resources {
+		resReports[i] = report.ResourceReport{
+			Name:          res.Name,
+			Version:       res.Version,
+			ExtraIdentity: res.ExtraIdentity,
+			Status:        report.StatusSkipped,
+		}
+	}
+	defer func() {
+		if c.Report == nil {
+			return
+		}
+		for _, resReport := range resReports {
+			c.Report.AddResource(name, version, resReport)
+		}
+	}()
+
 	var layers []ocispecv1.Descriptor
 	blobToResource := map[string]*cdv2.Resource{}
+	blobToReport := map[string]*report.ResourceReport{}
 	// todo: parallelize upload with
 	// todo: retry copy on failure
 	// todo: track if something has been uploaded otherwise only upload the component descriptor if "c.Force == true"
@@ -282,6 +398,11 @@ func (c *Copier) Copy(ctx context.Context, name, version string) error {
 				},
 			})
 			blobToResource[blobInfo.Digest] = res.DeepCopy()
+			resReports[i].SourceRef = sourceRef
+			resReports[i].SourceDigest = blobInfo.Digest
+			resReports[i].TargetRef = ref
+			resReports[i].TargetDigest = blobInfo.Digest
+			blobToReport[blobInfo.Digest] = &resReports[i]
 		case cdv2.OCIRegistryType:
 			if !c.CopyByValue {
 				log.V(7).Info("skip oci artifact copy by value", "resource", res.Name)
@@ -303,7 +424,7 @@ func (c *Copier) Copy(ctx context.Context, name, version string) error {
 			}
 
 			log.V(4).Info(fmt.Sprintf("copy oci artifact %s to %s", ociRegistryAcc.ImageReference, target))
-			if err := ociclient.Copy(ctx, c.OciClient, ociRegistryAcc.ImageReference, target); err != nil {
+			if err := c.copyArtifact(ctx, ociRegistryAcc.ImageReference, target, &resReports[i]); err != nil {
 				return fmt.Errorf("unable to copy oci artifact %s from %s to %s: %w", res.Name, ociRegistryAcc.ImageReference, target, err)
 			}
 
@@ -343,7 +464,7 @@ func (c *Copier) Copy(ctx context.Context, name, version string) error {
 			}
 
 			log.V(4).Info(fmt.Sprintf("copy oci artifact %s to %s", src, target))
-			if err := ociclient.Copy(ctx, c.OciClient, src, target); err != nil {
+			if err := c.copyArtifact(ctx, src, target, &resReports[i]); err != nil {
 				return fmt.Errorf("unable to copy oci artifact %s from %s to %s: %w", res.Name, src, target, err)
 			}
 
@@ -365,11 +486,6 @@ func (c *Copier) Copy(ctx context.Context, name, version string) error {
 	}
 	manifest.Layers = append(manifest.Layers, layers...)
 
-	ref, err := components.OCIRef(c.TargetRepoCtx, name, version)
-	if err != nil {
-		return fmt.Errorf("invalid component reference: %w", err)
-	}
-
 	store := ociclient.GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
 		log := log.WithValues("digest", desc.Digest.String(), "mediaType", desc.MediaType)
 		res, ok := blobToResource[desc.Digest.String()]
@@ -392,18 +508,155 @@ func (c *Copier) Copy(ctx context.Context, name, version string) error {
 		}
 
 		log.V(5).Info("copying resource", "resource", res.Name)
-		_, err := blobs.Resolve(ctx, *res, writer)
+		resReport := blobToReport[desc.Digest.String()]
+		blobStart := time.Now()
+		cw := &countingWriter{w: writer}
+		_, err := blobs.Resolve(ctx, *res, cw)
+		resReport.BytesTransferred += cw.bytes
+		resReport.Duration = report.Since(blobStart)
 		return err
 	})
 
 	log.V(3).Info("Upload component.", "ref", ref)
-	if err := c.OciClient.PushManifest(ctx, ref, manifest, ociclient.WithStore(store)); err != nil {
+	err = c.pushLocalBlobs(ctx, ref, sourceRef, layers, store)
+	if err == nil {
+		err = c.OciClient.PushManifest(ctx, ref, manifest, ociclient.WithStore(store))
+	}
+	for _, resReport := range blobToReport {
+		resReport.Status, resReport.Error = report.StatusFromError(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.Report != nil {
+		var targetDigest string
+		if desc, _, err := c.OciClient.GetRawManifest(ctx, ref); err == nil {
+			targetDigest = desc.Digest.String()
+		}
+		c.reportComponent(name, version, func(cr *report.ComponentReport) {
+			cr.TargetRef = ref
+			cr.TargetDigest = targetDigest
+		})
+	}
+
+	if c.Journal != nil {
+		entry := journal.Entry{
+			Key:          journal.ComponentKey(name, version),
+			SourceDigest: sourceDigest,
+			TargetRef:    ref,
+		}
+		if err := c.Journal.RecordManifest(ctx, c.OciClient, entry); err != nil {
+			return fmt.Errorf("unable to record component descriptor in journal: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// pushLocalBlobs pushes the local blobs of a component descriptor before its manifest is pushed.
+// The blobs are mounted from the source component descriptor if it is located in the same registry,
+// otherwise they are uploaded from the store.
+func (c *Copier) pushLocalBlobs(ctx context.Context, ref, sourceRef string, layers []ocispecv1.Descriptor, store ociclient.Store) error {
+	for _, layer := range layers {
+		if err := c.OciClient.PushBlob(ctx, ref, layer, ociclient.WithStore(store), ociclient.WithMountFrom(sourceRef)); err != nil {
+			return fmt.Errorf("unable to push blob %s: %w", layer.Digest.String(), err)
+		}
+	}
+	return nil
+}
+
+// copyArtifact copies an oci artifact unless the copy is already recorded in the journal.
+// The outcome is written to the given resource report.
+func (c *Copier) copyArtifact(ctx context.Context, src, target string, resReport *report.ResourceReport) (err error) {
+	start := time.Now()
+	resReport.SourceRef = src
+	resReport.TargetRef = target
+	defer func() {
+		resReport.Duration = report.Since(start)
+		if err != nil {
+			resReport.Status, resReport.Error = report.StatusFromError(err)
+		}
+	}()
+
+	if c.Journal != nil {
+		desc, _, err := c.OciClient.GetRawManifest(ctx, src)
+		if err != nil {
+			return fmt.Errorf("unable to get manifest of %s: %w", src, err)
+		}
+		resReport.SourceDigest = desc.Digest.String()
+	}
+
+	key := journal.ArtifactKey(src, target)
+	if c.isRecorded(ctx, key, resReport.SourceDigest) {
+		logr.FromContextOrDiscard(ctx).V(4).Info("skip oci artifact which has already been copied", "src", src, "target", target)
+		entry, _ := c.Journal.Get(key)
+		resReport.TargetDigest = entry.TargetDigest
+		resReport.Status = report.StatusSkipped
+		return nil
+	}
+
+	// the progress of a copy is reported serialized, therefore the bytes can be added without synchronisation
+	countTransferred := ociclient.WithCopyProgress(func(progress ociclient.CopyProgress) {
+		resReport.BytesTransferred += progress.Transferred
+	})
+	if err := ociclient.Copy(ctx, c.OciClient, src, target, countTransferred); err != nil {
 		return err
 	}
+	resReport.Status = report.StatusSucceeded
 
+	if c.Journal == nil {
+		if c.Report == nil {
+			return nil
+		}
+		if desc, _, err := c.OciClient.GetRawManifest(ctx, src); err == nil {
+			resReport.SourceDigest = desc.Digest.String()
+		}
+		if desc, _, err := c.OciClient.GetRawManifest(ctx, target); err == nil {
+			resReport.TargetDigest = desc.Digest.String()
+		}
+		return nil
+	}
+	entry := journal.Entry{
+		Key:          key,
+		SourceDigest: resReport.SourceDigest,
+		TargetRef:    target,
+	}
+	if err := c.Journal.RecordManifest(ctx, c.OciClient, entry); err != nil {
+		return fmt.Errorf("unable to record oci artifact in journal: %w", err)
+	}
+	entry, _ = c.Journal.Get(key)
+	resReport.TargetDigest = entry.TargetDigest
 	return nil
 }
 
+func (c *Copier) reportComponent(name, version string, update func(cr *report.ComponentReport)) {
+	if c.Report == nil {
+		return
+	}
+	c.Report.UpdateComponent(name, version, update)
+}
+
+func (c *Copier) isRecorded(ctx context.Context, key, sourceDigest string) bool {
+	if c.Journal == nil {
+		return false
+	}
+	_, ok := c.Journal.Lookup(ctx, c.OciClient, key, sourceDigest)
+	return ok
+}
+
+// countingWriter counts the bytes which are written to the underlying writer.
+type countingWriter struct {
+	w     io.Writer
+	bytes int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
 func targetOCIArtifactRef(targetRepo, ref string, keepOrigHost bool) (string, error) {
 	if !strings.Contains(targetRepo, "://") {
 		// add dummy protocol to correctly parse the url
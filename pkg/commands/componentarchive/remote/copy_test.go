@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package remote_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	cdoci "github.com/gardener/component-spec/bindings-go/oci"
+	"github.com/go-logr/logr"
+	"github.com/mandelsoft/vfs/pkg/memoryfs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/credentials"
+	"github.com/gardener/component-cli/ociclient/test/faultyregistry"
+	"github.com/gardener/component-cli/pkg/commands/componentarchive/remote"
+	"github.com/gardener/component-cli/pkg/transport/journal"
+)
+
+var _ = Describe("Copy", func() {
+
+	var (
+		srcRegistry *faultyregistry.Registry
+		tgtRegistry *faultyregistry.Registry
+		ociCache    cache.Cache
+		ociClient   ociclient.ExtendedClient
+		srcRepoCtx  cdv2.OCIRegistryRepository
+		blobData    = []byte("blob content")
+
+		pushComponent func(ctx context.Context, provider cdv2.ProviderType)
+	)
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		srcRegistry = faultyregistry.New()
+		// blobs are mounted within a registry, therefore a separate target registry is used to test uploads
+		tgtRegistry = faultyregistry.New()
+		ociCache = cache.NewInMemoryCache()
+		var err error
+		ociClient, err = ociclient.NewClient(logr.Discard(),
+			ociclient.WithKeyring(credentials.New()),
+			ociclient.WithCache(ociCache),
+			ociclient.AllowPlainHttp(true))
+		Expect(err).ToNot(HaveOccurred())
+		srcRepoCtx = *cdv2.NewOCIRegistryRepository(srcRegistry.Addr+"/source", "")
+		pushComponent(ctx, cdv2.InternalProvider)
+	})
+
+	AfterEach(func() {
+		srcRegistry.Close()
+		tgtRegistry.Close()
+	})
+
+	pushComponent = func(ctx context.Context, provider cdv2.ProviderType) {
+		// the source component is pushed with another cache, so that its blobs are not cached for the copy
+		pushCache := cache.NewInMemoryCache()
+		pushClient, err := ociclient.NewClient(logr.Discard(),
+			ociclient.WithKeyring(credentials.New()),
+			ociclient.WithCache(pushCache),
+			ociclient.AllowPlainHttp(true))
+		Expect(err).ToNot(HaveOccurred())
+
+		cd := &cdv2.ComponentDescriptor{
+			Metadata: cdv2.Metadata{Version: cdv2.SchemaVersion},
+			ComponentSpec: cdv2.ComponentSpec{
+				ObjectMeta: cdv2.ObjectMeta{Name: "example.com/a", Version: "v0.1.0"},
+				Provider:   provider,
+			},
+		}
+		Expect(cdv2.InjectRepositoryContext(cd, &srcRepoCtx)).To(Succeed())
+		ca := ctf.NewComponentArchive(cd, memoryfs.New())
+		Expect(ca.AddResource(&cdv2.Resource{
+			IdentityObjectMeta: cdv2.IdentityObjectMeta{
+				Name:    "blob",
+				Version: "v0.1.0",
+				Type:    "plain-text",
+			},
+			Relation: cdv2.LocalRelation,
+		}, ctf.BlobInfo{
+			MediaType: "text/plain",
+			Digest:    digest.FromBytes(blobData).String(),
+			Size:      int64(len(blobData)),
+		}, bytes.NewReader(blobData))).To(Succeed())
+		manifest, err := cdoci.NewManifestBuilder(pushCache, ca).Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		ref, err := cdoci.OCIRef(srcRepoCtx, cd.Name, cd.Version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pushClient.PushManifest(ctx, ref, manifest)).To(Succeed())
+	}
+
+	newCopier := func(tgtRepoCtx cdv2.OCIRegistryRepository) *remote.Copier {
+		return &remote.Copier{
+			SrcRepoCtx:    &srcRepoCtx,
+			TargetRepoCtx: &tgtRepoCtx,
+			CompResolver:  cdoci.NewResolver(ociClient),
+			OciClient:     ociClient,
+			Cache:         ociCache,
+		}
+	}
+
+	copyComponent := func(ctx context.Context, tgtRepoCtx cdv2.OCIRegistryRepository) {
+		Expect(newCopier(tgtRepoCtx).Copy(ctx, "example.com/a", "v0.1.0")).To(Succeed())
+	}
+
+	expectBlob := func(ctx context.Context, tgtRepoCtx cdv2.OCIRegistryRepository) {
+		cd, blobs, err := cdoci.NewResolver(ociClient).ResolveWithBlobResolver(ctx, &tgtRepoCtx, "example.com/a", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cd.Resources[0].Access.Type).To(Equal(cdv2.LocalOCIBlobType))
+		var buf bytes.Buffer
+		_, err = blobs.Resolve(ctx, cd.Resources[0], &buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buf.Bytes()).To(Equal(blobData))
+	}
+
+	It("should mount local blobs from the source repository if both repositories are located in the same registry", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		tgtRepoCtx := *cdv2.NewOCIRegistryRepository(srcRegistry.Addr+"/target", "")
+		copyComponent(ctx, tgtRepoCtx)
+
+		Expect(srcRegistry.Requests(http.MethodGet, "/blobs/"+digest.FromBytes(blobData).String())).To(Equal(0))
+		expectBlob(ctx, tgtRepoCtx)
+	})
+
+	It("should upload local blobs if the target repository is located in another registry", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		tgtRepoCtx := *cdv2.NewOCIRegistryRepository(tgtRegistry.Addr+"/target", "")
+		copyComponent(ctx, tgtRepoCtx)
+
+		Expect(srcRegistry.Requests(http.MethodGet, "/blobs/"+digest.FromBytes(blobData).String())).To(Equal(1))
+		expectBlob(ctx, tgtRepoCtx)
+	})
+
+	It("should record the source digest in the journal and copy a component descriptor again whose source has changed", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		dir, err := os.MkdirTemp("", "copy-")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		j, err := journal.Open(filepath.Join(dir, "journal.jsonl"), false)
+		Expect(err).ToNot(HaveOccurred())
+		defer j.Close()
+		tgtRepoCtx := *cdv2.NewOCIRegistryRepository(tgtRegistry.Addr+"/target", "")
+		srcRef, err := cdoci.OCIRef(srcRepoCtx, "example.com/a", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+
+		copier := newCopier(tgtRepoCtx)
+		copier.Journal = j
+		Expect(copier.Copy(ctx, "example.com/a", "v0.1.0")).To(Succeed())
+		srcDesc, _, err := ociClient.GetRawManifest(ctx, srcRef)
+		Expect(err).ToNot(HaveOccurred())
+		entry, ok := j.Get(journal.ComponentKey("example.com/a", "v0.1.0"))
+		Expect(ok).To(BeTrue())
+		Expect(entry.SourceDigest).To(Equal(srcDesc.Digest.String()))
+
+		// the existing target component descriptor is overwritten, so that only the journal could skip the copy
+		pushComponent(ctx, cdv2.ExternalProvider)
+		copier = newCopier(tgtRepoCtx)
+		copier.Journal = j
+		copier.Force = true
+		Expect(copier.Copy(ctx, "example.com/a", "v0.1.0")).To(Succeed())
+		cd, err := cdoci.NewResolver(ociClient).Resolve(ctx, &tgtRepoCtx, "example.com/a", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cd.Provider).To(Equal(cdv2.ExternalProvider))
+	})
+
+})
@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/containerd/containerd/errdefs"
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	cdoci "github.com/gardener/component-spec/bindings-go/oci"
+	"github.com/go-logr/logr"
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/oci"
+	ociopts "github.com/gardener/component-cli/ociclient/options"
+	"github.com/gardener/component-cli/pkg/logger"
+	"github.com/gardener/component-cli/pkg/utils"
+)
+
+// DeleteOptions contains all options to delete a component descriptor.
+type DeleteOptions struct {
+	// BaseUrl is the oci registry where the component is stored.
+	BaseUrl string
+	// ComponentName is the unique name of the component in the registry.
+	ComponentName string
+	// Version is the component Version in the oci registry.
+	Version string
+
+	ComponentNameMapping string
+
+	// Recursive specifies if referenced components which are not referenced by any other component should also be deleted.
+	Recursive bool
+
+	// OciOptions contains all exposed options to configure the oci client.
+	OciOptions ociopts.Options
+}
+
+// NewDeleteCommand creates a new command to delete component descriptors.
+func NewDeleteCommand(ctx context.Context) *cobra.Command {
+	opts := &DeleteOptions{}
+	cmd := &cobra.Command{
+		Use:   "delete BASE_URL COMPONENT_NAME VERSION",
+		Args:  cobra.ExactArgs(3),
+		Short: "deletes a component descriptor from a oci registry",
+		Long: `
+delete deletes the component descriptor with the given name and version from a baseurl.
+The referrers tag of the component descriptor, which lists e.g. its signatures in registries that do not support the referrers api, is also deleted.
+
+With "--recursive" all referenced components are also deleted unless they are still referenced by another component in the baseurl.
+Therefore all component descriptors of the baseurl are read, which requires the registry to support the listing of repositories.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(args); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if err := opts.Run(ctx, logger.Log, osfs.New()); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (o *DeleteOptions) Run(ctx context.Context, log logr.Logger, fs vfs.FileSystem) error {
+	ociClient, _, err := o.OciOptions.Build(log, fs)
+	if err != nil {
+		return fmt.Errorf("unable to build oci client: %s", err.Error())
+	}
+
+	d := Deleter{
+		RepoCtx: cdv2.OCIRegistryRepository{
+			ObjectType: cdv2.ObjectType{
+				Type: cdv2.OCIRegistryType,
+			},
+			BaseURL:              o.BaseUrl,
+			ComponentNameMapping: cdv2.ComponentNameMapping(o.ComponentNameMapping),
+		},
+		OciClient:    ociClient,
+		CompResolver: cdoci.NewResolver(ociClient),
+		Recursive:    o.Recursive,
+		Log:          log,
+	}
+	deleted, err := d.Delete(ctx, o.ComponentName, o.Version)
+	for _, cd := range deleted {
+		fmt.Printf("Successfully deleted component descriptor %s:%s from %s\n", cd.Name, cd.Version, o.BaseUrl)
+	}
+	return err
+}
+
+func (o *DeleteOptions) Complete(args []string) error {
+	o.BaseUrl = args[0]
+	o.ComponentName = args[1]
+	o.Version = args[2]
+
+	var err error
+	o.OciOptions.CacheDir, err = utils.CacheDir()
+	if err != nil {
+		return fmt.Errorf("unable to get oci cache directory: %w", err)
+	}
+
+	return o.Validate()
+}
+
+// Validate validates delete options
+func (o *DeleteOptions) Validate() error {
+	if len(o.BaseUrl) == 0 {
+		return errors.New("the base url must be defined")
+	}
+	if len(o.ComponentName) == 0 {
+		return errors.New("a component name must be defined")
+	}
+	if len(o.Version) == 0 {
+		return errors.New("a component's Version must be defined")
+	}
+	return nil
+}
+
+func (o *DeleteOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.ComponentNameMapping, "component-name-mapping", string(cdv2.OCIRegistryURLPathMapping), "[OPTIONAL] repository context name mapping")
+	fs.BoolVar(&o.Recursive, "recursive", false, "Recursively delete the referenced components which are not referenced by any other component.")
+	o.OciOptions.AddFlags(fs)
+}
+
+// Deleter deletes component descriptors from a repository.
+type Deleter struct {
+	RepoCtx      cdv2.OCIRegistryRepository
+	OciClient    ociclient.ExtendedClient
+	CompResolver ctf.ComponentResolver
+	Log          logr.Logger
+
+	// Recursive specifies if referenced components which are not referenced by any other component should also be deleted.
+	Recursive bool
+}
+
+// Delete deletes a component descriptor and, if recursive, all of its references that have no other referrers.
+// The component descriptors are deleted before the components they reference.
+// The deleted component descriptors are returned, also if the deletion of one of them fails.
+func (d *Deleter) Delete(ctx context.Context, name, version string) ([]*cdv2.ComponentDescriptor, error) {
+	cd, err := d.CompResolver.Resolve(ctx, &d.RepoCtx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve component descriptor %s:%s: %w", name, version, err)
+	}
+
+	toDelete := []*cdv2.ComponentDescriptor{cd}
+	if d.Recursive {
+		toDelete, err = d.unreferencedComponents(ctx, cd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deleted := make([]*cdv2.ComponentDescriptor, 0, len(toDelete))
+	for _, cd := range toDelete {
+		ref, err := cdoci.OCIRef(d.RepoCtx, cd.Name, cd.Version)
+		if err != nil {
+			return deleted, fmt.Errorf("invalid component reference: %w", err)
+		}
+		if err := d.deleteManifest(ctx, ref); err != nil {
+			return deleted, fmt.Errorf("unable to delete component descriptor %s:%s: %w", cd.Name, cd.Version, err)
+		}
+		d.Log.V(3).Info("deleted component descriptor", "ref", ref)
+		deleted = append(deleted, cd)
+	}
+	return deleted, nil
+}
+
+// deleteManifest deletes the manifest of a component descriptor and its referrers tag.
+// The referrers tag lists e.g. the signatures of the manifest in registries which do not support the referrers api,
+// the referring manifests themselves are kept.
+func (d *Deleter) deleteManifest(ctx context.Context, ref string) error {
+	refspec, err := oci.ParseRef(ref)
+	if err != nil {
+		return fmt.Errorf("unable to parse ref: %w", err)
+	}
+	_, desc, err := d.OciClient.Resolve(ctx, refspec.String())
+	if err != nil {
+		return fmt.Errorf("unable to resolve %q: %w", refspec.String(), err)
+	}
+	refspec.Tag = nil
+	refspec.Digest = &desc.Digest
+	if err := d.OciClient.DeleteManifest(ctx, refspec.String()); err != nil {
+		return err
+	}
+
+	tagRef := fmt.Sprintf("%s:%s", refspec.Name(), ociclient.ReferrersTag(desc.Digest))
+	if err := d.OciClient.DeleteManifest(ctx, tagRef); err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to delete referrers tag %q: %w", tagRef, err)
+	}
+	d.Log.V(3).Info("deleted referrers tag", "ref", tagRef)
+	return nil
+}
+
+// unreferencedComponents returns the given component descriptor and all transitively referenced component descriptors
+// which are only referenced by the returned component descriptors.
+func (d *Deleter) unreferencedComponents(ctx context.Context, cd *cdv2.ComponentDescriptor) ([]*cdv2.ComponentDescriptor, error) {
+	referrers, err := d.referrers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*cdv2.ComponentDescriptor{cd}
+	selected := map[string]bool{componentKey(cd.Name, cd.Version): true}
+	// a component might only become unreferenced after another of its referrers has been selected,
+	// therefore the references are checked until no further component is selected.
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(result); i++ {
+			for _, ref := range result[i].ComponentReferences {
+				key := componentKey(ref.ComponentName, ref.Version)
+				if selected[key] {
+					continue
+				}
+				if !allSelected(referrers[key], selected) {
+					d.Log.V(5).Info("component is still referenced by other components", "component", key)
+					continue
+				}
+				refCD, err := d.CompResolver.Resolve(ctx, &d.RepoCtx, ref.ComponentName, ref.Version)
+				if err != nil {
+					return nil, fmt.Errorf("unable to resolve component reference %s: %w", key, err)
+				}
+				selected[key] = true
+				result = append(result, refCD)
+				changed = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// referrers reads all component descriptors of the repository
+// and returns the referring components for every referenced component.
+func (d *Deleter) referrers(ctx context.Context) (map[string][]string, error) {
+	if d.RepoCtx.ComponentNameMapping != cdv2.OCIRegistryURLPathMapping && len(d.RepoCtx.ComponentNameMapping) != 0 {
+		return nil, fmt.Errorf("recursive deletion is only supported for the component name mapping %q", cdv2.OCIRegistryURLPathMapping)
+	}
+	baseUrl := d.RepoCtx.BaseURL
+	if !strings.Contains(baseUrl, "://") {
+		// add dummy protocol to correctly parse the the url
+		baseUrl = "http://" + baseUrl
+	}
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse base url: %w", err)
+	}
+	namespace := path.Join(u.Host, u.Path, cdoci.ComponentDescriptorNamespace)
+
+	repos, err := d.OciClient.ListRepositories(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list component repositories: %w", err)
+	}
+
+	referrers := map[string][]string{}
+	for _, repo := range repos {
+		if !strings.HasPrefix(repo, namespace+"/") {
+			continue
+		}
+		name := strings.TrimPrefix(repo, namespace+"/")
+		versions, err := d.OciClient.ListTags(ctx, repo)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list versions of component %s: %w", name, err)
+		}
+		for _, version := range versions {
+			if ociclient.IsReferrersTag(version) {
+				continue
+			}
+			cd, err := d.CompResolver.Resolve(ctx, &d.RepoCtx, name, version)
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve component descriptor %s:%s: %w", name, version, err)
+			}
+			for _, ref := range cd.ComponentReferences {
+				key := componentKey(ref.ComponentName, ref.Version)
+				referrers[key] = append(referrers[key], componentKey(cd.Name, cd.Version))
+			}
+		}
+	}
+	return referrers, nil
+}
+
+func componentKey(name, version string) string {
+	return fmt.Sprintf("%s:%s", name, version)
+}
+
+func allSelected(keys []string, selected map[string]bool) bool {
+	for _, key := range keys {
+		if !selected[key] {
+			return false
+		}
+	}
+	return true
+}
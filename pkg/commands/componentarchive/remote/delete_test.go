@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package remote_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	cdoci "github.com/gardener/component-spec/bindings-go/oci"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/credentials"
+	"github.com/gardener/component-cli/ociclient/oci"
+	"github.com/gardener/component-cli/ociclient/test/faultyregistry"
+	"github.com/gardener/component-cli/pkg/commands/componentarchive/remote"
+)
+
+var _ = Describe("Delete", func() {
+
+	var (
+		registry  *faultyregistry.Registry
+		ociCache  cache.Cache
+		ociClient ociclient.ExtendedClient
+		repoCtx   cdv2.OCIRegistryRepository
+	)
+
+	BeforeEach(func() {
+		registry = faultyregistry.New()
+		ociCache = cache.NewInMemoryCache()
+		var err error
+		ociClient, err = ociclient.NewClient(logr.Discard(),
+			ociclient.WithKeyring(credentials.New()),
+			ociclient.WithCache(ociCache),
+			ociclient.AllowPlainHttp(true))
+		Expect(err).ToNot(HaveOccurred())
+		repoCtx = *cdv2.NewOCIRegistryRepository(registry.Addr+"/components", "")
+	})
+
+	AfterEach(func() {
+		registry.Close()
+	})
+
+	uploadComponent := func(ctx context.Context, name string, refs ...string) {
+		cd := &cdv2.ComponentDescriptor{
+			Metadata: cdv2.Metadata{Version: cdv2.SchemaVersion},
+			ComponentSpec: cdv2.ComponentSpec{
+				ObjectMeta: cdv2.ObjectMeta{Name: "example.com/" + name, Version: "v0.1.0"},
+				Provider:   cdv2.InternalProvider,
+			},
+		}
+		for _, ref := range refs {
+			cd.ComponentReferences = append(cd.ComponentReferences, cdv2.ComponentReference{
+				Name:          "ref-" + ref,
+				ComponentName: "example.com/" + ref,
+				Version:       "v0.1.0",
+			})
+		}
+		Expect(cdv2.InjectRepositoryContext(cd, &repoCtx)).To(Succeed())
+		manifest, err := cdoci.NewManifestBuilder(ociCache, ctf.NewComponentArchive(cd, nil)).Build(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		ref, err := cdoci.OCIRef(repoCtx, cd.Name, cd.Version)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ociClient.PushManifest(ctx, ref, manifest)).To(Succeed())
+	}
+
+	newDeleter := func(recursive bool) *remote.Deleter {
+		return &remote.Deleter{
+			RepoCtx:      repoCtx,
+			OciClient:    ociClient,
+			CompResolver: cdoci.NewResolver(ociClient),
+			Log:          logr.Discard(),
+			Recursive:    recursive,
+		}
+	}
+
+	deletedNames := func(cds []*cdv2.ComponentDescriptor) []string {
+		names := make([]string, len(cds))
+		for i, cd := range cds {
+			names[i] = cd.Name
+		}
+		return names
+	}
+
+	exists := func(ctx context.Context, name string) bool {
+		_, err := cdoci.NewResolver(ociClient).Resolve(ctx, &repoCtx, "example.com/"+name, "v0.1.0")
+		return err == nil
+	}
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		// a references b and c, b and c reference d and e references c
+		uploadComponent(ctx, "d")
+		uploadComponent(ctx, "c", "d")
+		uploadComponent(ctx, "b", "d")
+		uploadComponent(ctx, "a", "b", "c")
+		uploadComponent(ctx, "e", "c")
+	})
+
+	It("should only delete the given component descriptor", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		deleted, err := newDeleter(false).Delete(ctx, "example.com/a", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedNames(deleted)).To(Equal([]string{"example.com/a"}))
+		Expect(exists(ctx, "a")).To(BeFalse())
+		Expect(exists(ctx, "b")).To(BeTrue())
+	})
+
+	It("should recursively delete the references which are not referenced by other components", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		deleted, err := newDeleter(true).Delete(ctx, "example.com/a", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedNames(deleted)).To(Equal([]string{"example.com/a", "example.com/b"}))
+		Expect(exists(ctx, "c")).To(BeTrue())
+		Expect(exists(ctx, "d")).To(BeTrue())
+		Expect(exists(ctx, "e")).To(BeTrue())
+
+		deleted, err = newDeleter(true).Delete(ctx, "example.com/e", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedNames(deleted)).To(Equal([]string{"example.com/e", "example.com/c", "example.com/d"}))
+	})
+
+	It("should ignore referrers tags and delete the referrers tag of a deleted component descriptor", func() {
+		ctx := context.Background()
+		defer ctx.Done()
+		ref, err := cdoci.OCIRef(repoCtx, "example.com/b", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		subject, _, err := ociClient.GetRawManifest(ctx, ref)
+		Expect(err).ToNot(HaveOccurred())
+
+		// the registry does not support the referrers api, therefore the signature is listed in the referrers tag of b
+		signature := []byte("signature")
+		config := []byte("{}")
+		blobs := map[digest.Digest][]byte{
+			digest.FromBytes(config):    config,
+			digest.FromBytes(signature): signature,
+		}
+		desc, rawManifest, err := ociclient.CreateDescriptorFromReferrerManifest(&ociclient.ReferrerManifest{
+			ArtifactType: "application/vnd.example.signature.v1+json",
+			Config: ocispecv1.Descriptor{
+				MediaType: "application/vnd.oci.empty.v1+json",
+				Digest:    digest.FromBytes(config),
+				Size:      int64(len(config)),
+			},
+			Layers: []ocispecv1.Descriptor{
+				{
+					MediaType: "application/octet-stream",
+					Digest:    digest.FromBytes(signature),
+					Size:      int64(len(signature)),
+				},
+			},
+			Subject: &subject,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		store := ociclient.GenericStore(func(ctx context.Context, desc ocispecv1.Descriptor, writer io.Writer) error {
+			_, err := writer.Write(blobs[desc.Digest])
+			return err
+		})
+		refspec, err := oci.ParseRef(ref)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ociClient.PushRawManifest(ctx, fmt.Sprintf("%s@%s", refspec.Name(), desc.Digest), desc, rawManifest, ociclient.WithStore(store))).To(Succeed())
+		tags, err := ociClient.ListTags(ctx, ref)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(ContainElement(ociclient.ReferrersTag(subject.Digest)))
+
+		deleted, err := newDeleter(true).Delete(ctx, "example.com/a", "v0.1.0")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedNames(deleted)).To(Equal([]string{"example.com/a", "example.com/b"}))
+
+		tags, err = ociClient.ListTags(ctx, ref)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tags).To(BeEmpty())
+	})
+
+})
@@ -20,6 +20,7 @@ func NewRemoteCommand(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(NewPushCommand(ctx))
 	cmd.AddCommand(NewGetCommand(ctx))
 	cmd.AddCommand(NewCopyCommand(ctx))
+	cmd.AddCommand(NewDeleteCommand(ctx))
 
 	return cmd
 }
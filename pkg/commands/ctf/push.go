@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
 	"github.com/gardener/component-spec/bindings-go/ctf"
@@ -16,10 +17,15 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/mandelsoft/vfs/pkg/osfs"
 	"github.com/mandelsoft/vfs/pkg/vfs"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
 	"github.com/gardener/component-cli/pkg/components"
+	"github.com/gardener/component-cli/pkg/transport/report"
 
 	ociopts "github.com/gardener/component-cli/ociclient/options"
 	"github.com/gardener/component-cli/pkg/logger"
@@ -33,6 +39,9 @@ type PushOptions struct {
 	BaseUrl string
 	// AdditionalTags defines additional tags that the oci artifact should be tagged with.
 	AdditionalTags []string
+	// ReportPath is the path to the file the push report is written to.
+	// +optional
+	ReportPath string
 
 	// OciOptions contains all exposed options to configure the oci client.
 	OciOptions ociopts.Options
@@ -82,7 +91,7 @@ func (o *PushOptions) Run(ctx context.Context, log logr.Logger, fs vfs.FileSyste
 It is expected that the given path points to a CTF Archive`, o.CTFPath)
 	}
 
-	ociClient, cache, err := o.OciOptions.Build(log, fs)
+	ociClient, ociCache, err := o.OciOptions.Build(log, fs)
 	if err != nil {
 		return fmt.Errorf("unable to build oci client: %s", err.Error())
 	}
@@ -92,46 +101,128 @@ It is expected that the given path points to a CTF Archive`, o.CTFPath)
 		return fmt.Errorf("unable to open ctf at %q: %s", o.CTFPath, err.Error())
 	}
 
+	var rep *report.Report
+	if len(o.ReportPath) != 0 {
+		rep = report.New()
+	}
+
 	err = ctfArchive.Walk(func(ca *ctf.ComponentArchive) error {
-		// update repository context
-		if len(o.BaseUrl) != 0 {
-			if err := cdv2.InjectRepositoryContext(ca.ComponentDescriptor, cdv2.NewOCIRegistryRepository(o.BaseUrl, "")); err != nil {
-				return fmt.Errorf("unable to add repository context: %w", err)
+		start := time.Now()
+		err := o.pushComponentArchive(ctx, log, ociClient, ociCache, ca, rep)
+		if rep != nil {
+			rep.UpdateComponent(ca.ComponentDescriptor.GetName(), ca.ComponentDescriptor.GetVersion(), func(c *report.ComponentReport) {
+				c.SourceRef = o.CTFPath
+				c.Status, c.Error = report.StatusFromError(err)
+				c.Duration = report.Since(start)
+			})
+		}
+		return err
+	})
+	if rep != nil {
+		rep.Finish()
+		if reportErr := rep.Write(fs, o.ReportPath); reportErr != nil {
+			if err == nil {
+				return reportErr
 			}
+			log.Error(reportErr, "unable to write report")
 		}
+	}
+	if err != nil {
+		return fmt.Errorf("error while reading component archives in ctf: %w", err)
+	}
 
-		manifest, err := cdoci.NewManifestBuilder(cache, ca).Build(ctx)
-		if err != nil {
-			return fmt.Errorf("unable to build oci artifact for component acrchive: %w", err)
+	return ctfArchive.Close()
+}
+
+func (o *PushOptions) pushComponentArchive(ctx context.Context, log logr.Logger, ociClient ociclient.Client, ociCache cache.Cache, ca *ctf.ComponentArchive, rep *report.Report) error {
+	// update repository context
+	if len(o.BaseUrl) != 0 {
+		if err := cdv2.InjectRepositoryContext(ca.ComponentDescriptor, cdv2.NewOCIRegistryRepository(o.BaseUrl, "")); err != nil {
+			return fmt.Errorf("unable to add repository context: %w", err)
+		}
+	}
+
+	// the source location of local blobs has to be read before the manifest builder converts them to oci blobs
+	sourceRefs := make([]string, len(ca.ComponentDescriptor.Resources))
+	for i, res := range ca.ComponentDescriptor.Resources {
+		acc := cdv2.LocalFilesystemBlobAccess{}
+		if res.Access != nil && res.Access.Type == cdv2.LocalFilesystemBlobType && res.Access.DecodeInto(&acc) == nil {
+			sourceRefs[i] = acc.Filename
 		}
+	}
 
-		ref, err := components.OCIRef(ca.ComponentDescriptor.GetEffectiveRepositoryContext(), ca.ComponentDescriptor.GetName(), ca.ComponentDescriptor.GetVersion())
+	manifest, err := cdoci.NewManifestBuilder(ociCache, ca).Build(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to build oci artifact for component acrchive: %w", err)
+	}
+
+	ref, err := components.OCIRef(ca.ComponentDescriptor.GetEffectiveRepositoryContext(), ca.ComponentDescriptor.GetName(), ca.ComponentDescriptor.GetVersion())
+	if err != nil {
+		return fmt.Errorf("unable to calculate oci ref for %q: %s", ca.ComponentDescriptor.GetName(), err.Error())
+	}
+	start := time.Now()
+	err = ociClient.PushManifest(ctx, ref, manifest)
+	if rep != nil {
+		reportResources(rep, ca.ComponentDescriptor, manifest, sourceRefs, ref, report.Since(start), err)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to upload component archive to %q: %s", ref, err.Error())
+	}
+	log.Info(fmt.Sprintf("Successfully uploaded component archive to %q", ref))
+
+	if rep != nil {
+		var targetDigest string
+		if desc, _, err := ociClient.GetRawManifest(ctx, ref); err == nil {
+			targetDigest = desc.Digest.String()
+		}
+		rep.UpdateComponent(ca.ComponentDescriptor.GetName(), ca.ComponentDescriptor.GetVersion(), func(c *report.ComponentReport) {
+			c.TargetRef = ref
+			c.TargetDigest = targetDigest
+		})
+	}
+
+	for _, tag := range o.AdditionalTags {
+		ref, err := components.OCIRef(ca.ComponentDescriptor.GetEffectiveRepositoryContext(), ca.ComponentDescriptor.GetName(), tag)
 		if err != nil {
 			return fmt.Errorf("unable to calculate oci ref for %q: %s", ca.ComponentDescriptor.GetName(), err.Error())
 		}
 		if err := ociClient.PushManifest(ctx, ref, manifest); err != nil {
 			return fmt.Errorf("unable to upload component archive to %q: %s", ref, err.Error())
 		}
-		log.Info(fmt.Sprintf("Successfully uploaded component archive to %q", ref))
+		log.Info(fmt.Sprintf("Successfully tagged component archive with %q", ref))
+	}
 
-		for _, tag := range o.AdditionalTags {
-			ref, err := components.OCIRef(ca.ComponentDescriptor.GetEffectiveRepositoryContext(), ca.ComponentDescriptor.GetName(), tag)
-			if err != nil {
-				return fmt.Errorf("unable to calculate oci ref for %q: %s", ca.ComponentDescriptor.GetName(), err.Error())
-			}
-			if err := ociClient.PushManifest(ctx, ref, manifest); err != nil {
-				return fmt.Errorf("unable to upload component archive to %q: %s", ref, err.Error())
-			}
-			log.Info(fmt.Sprintf("Successfully tagged component archive with %q", ref))
-		}
+	return nil
+}
 
-		return nil
-	})
-	if err != nil {
-		return fmt.Errorf("error while reading component archives in ctf: %w", err)
+// reportResources adds the resources of a pushed component archive to the report.
+// Only local blobs are uploaded, all other resources are reported as skipped.
+func reportResources(rep *report.Report, cd *cdv2.ComponentDescriptor, manifest *ocispecv1.Manifest, sourceRefs []string, ref string, duration metav1.Duration, pushErr error) {
+	layers := map[string]ocispecv1.Descriptor{}
+	for _, layer := range manifest.Layers {
+		layers[layer.Digest.String()] = layer
 	}
 
-	return ctfArchive.Close()
+	for i, res := range cd.Resources {
+		resReport := report.ResourceReport{
+			Name:          res.Name,
+			Version:       res.Version,
+			ExtraIdentity: res.ExtraIdentity,
+			Status:        report.StatusSkipped,
+		}
+
+		acc := cdv2.LocalOCIBlobAccess{}
+		if len(sourceRefs[i]) != 0 && res.Access != nil && res.Access.Type == cdv2.LocalOCIBlobType && res.Access.DecodeInto(&acc) == nil {
+			resReport.SourceRef = sourceRefs[i]
+			resReport.SourceDigest = acc.Digest
+			resReport.TargetRef = ref
+			resReport.TargetDigest = acc.Digest
+			resReport.BytesTransferred = layers[acc.Digest].Size
+			resReport.Duration = duration
+			resReport.Status, resReport.Error = report.StatusFromError(pushErr)
+		}
+		rep.AddResource(cd.GetName(), cd.GetVersion(), resReport)
+	}
 }
 
 func (o *PushOptions) Complete(args []string) error {
@@ -155,12 +246,18 @@ func (o *PushOptions) Validate() error {
 	if len(o.CTFPath) == 0 {
 		return errors.New("a path to the component descriptor must be defined")
 	}
+	if len(o.ReportPath) != 0 {
+		if _, err := report.FormatFromPath(o.ReportPath); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (o *PushOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.BaseUrl, "repo-ctx", "", "repository context url for component to upload. The repository url will be automatically added to the repository contexts.")
 	fs.StringArrayVarP(&o.AdditionalTags, "tag", "t", []string{}, "set additional tags on the oci artifact")
+	fs.StringVar(&o.ReportPath, "report", "", "path to a file the push report is written to. the report contains the outcome, digests, transferred bytes, and duration of every resource. the format is derived from the file extension, one of .json, .yaml, .yml.")
 
 	o.OciOptions.AddFlags(fs)
 }
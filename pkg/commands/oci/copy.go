@@ -27,6 +27,10 @@ type CopyOptions struct {
 	SourceRef string
 	// TargetRef is the target oci artifact reference where the artifact is copied to.
 	TargetRef string
+	// Concurrency is the number of blobs and manifests which are copied in parallel.
+	Concurrency int
+	// Referrers specifies if the referrers of the artifact should also be copied.
+	Referrers bool
 
 	// OCIOptions contains all oci client related options.
 	OCIOptions ociopts.Options
@@ -41,6 +45,11 @@ func NewCopyCommand(ctx context.Context) *cobra.Command {
 		Long: `
 Copy copies a artifact from a source to a target registry.
 The artifact is copied without modification.
+
+The blobs of all manifests of an image index are copied in parallel,
+whereas blobs which are shared by multiple platforms are only copied once.
+
+With "--referrers" the artifacts which refer to the copied artifact, e.g. signatures or SBOMs, are also copied.
 `,
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := opts.Complete(args); err != nil {
@@ -59,6 +68,8 @@ The artifact is copied without modification.
 }
 
 func (o *CopyOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.Concurrency, "concurrency", ociclient.DefaultCopyConcurrency, "number of blobs and manifests which are copied in parallel")
+	fs.BoolVar(&o.Referrers, "referrers", false, "also copy the artifacts which refer to the artifact")
 	o.OCIOptions.AddFlags(fs)
 }
 
@@ -68,6 +79,9 @@ func (o *CopyOptions) Complete(args []string) error {
 	}
 	o.SourceRef = args[0]
 	o.TargetRef = args[1]
+	if o.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be greater than 0")
+	}
 	return nil
 }
 
@@ -76,7 +90,12 @@ func (o *CopyOptions) Run(ctx context.Context, log logr.Logger, fs vfs.FileSyste
 	if err != nil {
 		return fmt.Errorf("unable to build oci client: %s", err.Error())
 	}
-	if err := ociclient.Copy(ctx, ociClient, o.SourceRef, o.TargetRef); err != nil {
+	progress := func(progress ociclient.CopyProgress) {
+		log.V(3).Info("copied", "digest", progress.Descriptor.Digest.String(), "mediaType", progress.Descriptor.MediaType,
+			"completed", progress.Completed, "total", progress.Total)
+	}
+	if err := ociclient.Copy(ctx, ociClient, o.SourceRef, o.TargetRef,
+		ociclient.WithCopyConcurrency(o.Concurrency), ociclient.WithCopyProgress(progress), ociclient.WithCopyReferrers(o.Referrers)); err != nil {
 		return err
 	}
 	fmt.Printf("Successfully copied %q to %q", o.SourceRef, o.TargetRef)
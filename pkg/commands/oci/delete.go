@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	ociopts "github.com/gardener/component-cli/ociclient/options"
+	"github.com/gardener/component-cli/pkg/logger"
+)
+
+type DeleteOptions struct {
+	// Ref is the oci artifact reference.
+	Ref string
+	// TagOnly specifies that only the tag of the reference is deleted.
+	TagOnly bool
+
+	// OCIOptions contains all oci client related options.
+	OCIOptions ociopts.Options
+}
+
+// NewDeleteCommand creates a new command to delete oci artifacts.
+func NewDeleteCommand(ctx context.Context) *cobra.Command {
+	opts := &DeleteOptions{}
+	cmd := &cobra.Command{
+		Use:   "delete ARTIFACT_REFERENCE",
+		Args:  cobra.ExactArgs(1),
+		Short: "Deletes an artifact from a registry",
+		Long: `
+delete deletes the manifest of an artifact reference from the registry.
+A tag is resolved to the digest of its manifest, so that the manifest and all of its tags are deleted.
+
+With "--tag-only" only the tag of the reference is deleted.
+Registries that do not support the deletion of tags delete the referenced manifest instead.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(args); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if err := opts.Run(ctx, logger.Log, osfs.New()); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	opts.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func (o *DeleteOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.TagOnly, "tag-only", false, "only delete the tag of the reference")
+	o.OCIOptions.AddFlags(fs)
+}
+
+func (o *DeleteOptions) Complete(args []string) error {
+	o.Ref = args[0]
+	if len(o.Ref) == 0 {
+		return errors.New("an artifact reference must be defined")
+	}
+	return nil
+}
+
+func (o *DeleteOptions) Run(ctx context.Context, log logr.Logger, fs vfs.FileSystem) error {
+	ociClient, _, err := o.OCIOptions.Build(log, fs)
+	if err != nil {
+		return fmt.Errorf("unable to build oci client: %s", err.Error())
+	}
+
+	if o.TagOnly {
+		if err := ociClient.DeleteTag(ctx, o.Ref); err != nil {
+			return fmt.Errorf("unable to delete tag %q: %w", o.Ref, err)
+		}
+		log.Info("deleted tag", "ref", o.Ref)
+		return nil
+	}
+
+	if err := ociClient.DeleteManifest(ctx, o.Ref); err != nil {
+		return fmt.Errorf("unable to delete manifest %q: %w", o.Ref, err)
+	}
+	log.Info("deleted manifest", "ref", o.Ref)
+	return nil
+}
@@ -19,5 +19,7 @@ func NewOCICommand(ctx context.Context) *cobra.Command {
 	cmd.AddCommand(NewCopyCommand(ctx))
 	cmd.AddCommand(NewTagsCommand(ctx))
 	cmd.AddCommand(NewRepositoriesCommand(ctx))
+	cmd.AddCommand(NewDeleteCommand(ctx))
+	cmd.AddCommand(NewReferrersCommand(ctx))
 	return cmd
 }
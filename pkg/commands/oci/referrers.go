@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/go-logr/logr"
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	ociopts "github.com/gardener/component-cli/ociclient/options"
+	"github.com/gardener/component-cli/pkg/logger"
+)
+
+type ReferrersOptions struct {
+	// Ref is the oci artifact reference.
+	Ref string
+	// ArtifactType filters the referrers by their artifact type.
+	ArtifactType string
+
+	// OCIOptions contains all oci client related options.
+	OCIOptions ociopts.Options
+}
+
+// NewReferrersCommand creates a new command to list the referrers of oci artifacts.
+func NewReferrersCommand(ctx context.Context) *cobra.Command {
+	opts := &ReferrersOptions{}
+	cmd := &cobra.Command{
+		Use:   "referrers ARTIFACT_REFERENCE",
+		Args:  cobra.ExactArgs(1),
+		Short: "Lists all artifacts which refer to an artifact",
+		Long: `
+referrers lists all artifacts whose subject is the given artifact reference, e.g. signatures or SBOMs.
+
+The referrers are read from the referrers tag of the artifact if the registry does not support the referrers api.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(args); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if err := opts.Run(ctx, logger.Log, osfs.New()); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	opts.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func (o *ReferrersOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.ArtifactType, "artifact-type", "", "only list referrers of the given artifact type")
+	o.OCIOptions.AddFlags(fs)
+}
+
+func (o *ReferrersOptions) Complete(args []string) error {
+	o.Ref = args[0]
+	if len(o.Ref) == 0 {
+		return errors.New("an artifact reference must be defined")
+	}
+	return nil
+}
+
+func (o *ReferrersOptions) Run(ctx context.Context, log logr.Logger, fs vfs.FileSystem) error {
+	ociClient, _, err := o.OCIOptions.Build(log, fs)
+	if err != nil {
+		return fmt.Errorf("unable to build oci client: %s", err.Error())
+	}
+
+	referrers, err := ociClient.ListReferrers(ctx, o.Ref, o.ArtifactType)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIGEST\tARTIFACT TYPE\tMEDIA TYPE\tSIZE")
+	for _, referrer := range referrers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", referrer.Digest, referrer.ArtifactType, referrer.MediaType, referrer.Size)
+	}
+	return tw.Flush()
+}
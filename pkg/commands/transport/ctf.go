@@ -0,0 +1,34 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"context"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+// ctfResolver resolves component descriptors from the component archives of a CTF.
+// The repository context is ignored.
+type ctfResolver struct {
+	ctf *processutils.CTFReader
+}
+
+var _ ctf.ComponentResolver = &ctfResolver{}
+
+func (r *ctfResolver) Resolve(ctx context.Context, repoCtx cdv2.Repository, name, version string) (*cdv2.ComponentDescriptor, error) {
+	cd, _, err := r.ResolveWithBlobResolver(ctx, repoCtx, name, version)
+	return cd, err
+}
+
+func (r *ctfResolver) ResolveWithBlobResolver(ctx context.Context, _ cdv2.Repository, name, version string) (*cdv2.ComponentDescriptor, ctf.BlobResolver, error) {
+	ca, err := r.ctf.ComponentArchive(name, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ca.ComponentDescriptor, ca.BlobResolver, nil
+}
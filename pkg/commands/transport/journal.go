@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"context"
+	"fmt"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/pkg/transport/journal"
+	"github.com/gardener/component-cli/pkg/transport/process"
+)
+
+// journalingPipeline records the processed resource of a successful pipeline run in a journal.
+type journalingPipeline struct {
+	pipeline process.ResourceProcessingPipeline
+	journal  *journal.Journal
+	client   ociclient.Client
+	// target is the name of the target of the pipeline, empty for the main target
+	target string
+}
+
+func (p *journalingPipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	// the source digest is determined before the resource is processed, so that a source which changes meanwhile is processed again on resume
+	sourceDigest, err := resourceSourceDigest(ctx, p.client, cd, res)
+	if err != nil {
+		return nil, cdv2.Resource{}, err
+	}
+
+	processedCD, processedRes, err := p.pipeline.Process(ctx, cd, res)
+	if err != nil {
+		return nil, cdv2.Resource{}, err
+	}
+
+	entry := journal.Entry{
+		Key:          journal.TargetKey(journal.ResourceKey(cd, res), p.target),
+		SourceDigest: sourceDigest,
+		Resource:     &processedRes,
+	}
+
+	// the target digest of oci artifacts is recorded to be able to detect stale entries on resume
+	if processedRes.Access != nil && processedRes.Access.Type == cdv2.OCIRegistryType {
+		acc := cdv2.OCIRegistryAccess{}
+		if err := processedRes.Access.DecodeInto(&acc); err != nil {
+			return nil, cdv2.Resource{}, fmt.Errorf("unable to decode access: %w", err)
+		}
+		entry.TargetRef = acc.ImageReference
+		err = p.journal.RecordManifest(ctx, p.client, entry)
+	} else {
+		err = p.journal.Record(entry)
+	}
+	if err != nil {
+		return nil, cdv2.Resource{}, fmt.Errorf("unable to record resource in journal: %w", err)
+	}
+
+	return processedCD, processedRes, nil
+}
+
+// resourceSourceDigest returns the source digest of a resource which is recorded in the journal.
+// It is the digest of the oci artifact or local oci blob of the resource, otherwise the digest of the resource itself.
+func resourceSourceDigest(ctx context.Context, client ociclient.Client, cd cdv2.ComponentDescriptor, res cdv2.Resource) (string, error) {
+	if _, dgst := resourceLocation(ctx, client, cd.GetEffectiveRepositoryContext(), cd, res); len(dgst) != 0 {
+		return dgst, nil
+	}
+	return journal.ResourceDigest(res)
+}
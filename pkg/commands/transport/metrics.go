@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gardener/component-cli/pkg/transport/metrics"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/tracing"
+)
+
+// tracingPipeline traces every pipeline run in a span which is the parent of the spans of its processors.
+type tracingPipeline struct {
+	pipeline process.ResourceProcessingPipeline
+	// target is the name of the target of the pipeline, empty for the main target
+	target string
+}
+
+func (p *tracingPipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	ctx, span := tracing.Start(ctx, "pipeline", "component", cd.Name, "version", cd.Version, "resource", res.Name, "target", p.target)
+	defer span.End()
+
+	processedCD, processedRes, err := p.pipeline.Process(ctx, cd, res)
+	span.RecordError(err)
+	return processedCD, processedRes, err
+}
+
+// instrumentedProcessor traces every run of a processor and records its metrics.
+// A processor is only used by the pipeline of a single resource, therefore every run but the first one is a retry.
+type instrumentedProcessor struct {
+	processor process.ResourceStreamProcessor
+	// kind is one of downloader, processor, uploader
+	kind string
+	name string
+	typ  string
+	runs int32
+}
+
+func instrumentProcessor(processor process.ResourceStreamProcessor, kind, name, typ string) process.ResourceStreamProcessor {
+	return &instrumentedProcessor{
+		processor: processor,
+		kind:      kind,
+		name:      name,
+		typ:       typ,
+	}
+}
+
+func (p *instrumentedProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	attempt := atomic.AddInt32(&p.runs, 1)
+	if attempt > 1 {
+		metrics.ProcessorRetries.WithLabelValues(p.kind, p.typ).Inc()
+	}
+
+	ctx, span := tracing.Start(ctx, p.kind, "name", p.name, "type", p.typ, "attempt", attempt)
+	defer span.End()
+
+	switch p.kind {
+	case metrics.DownloaderKind:
+		w = &metricWriter{w: w, counter: metrics.DownloadedBytes.WithLabelValues(p.typ)}
+	case metrics.UploaderKind:
+		r = &metricReader{r: r, counter: metrics.UploadedBytes.WithLabelValues(p.typ)}
+	}
+
+	start := time.Now()
+	err := p.processor.Process(ctx, r, w)
+	metrics.ProcessorDuration.WithLabelValues(p.kind, p.typ).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		metrics.ProcessorFailures.WithLabelValues(p.kind, p.typ).Inc()
+		span.RecordError(err)
+		return err
+	}
+	metrics.ResourcesProcessed.WithLabelValues(p.kind, p.typ).Inc()
+	return nil
+}
+
+// metricReader adds the bytes which are read to a counter.
+type metricReader struct {
+	r       io.Reader
+	counter prometheus.Counter
+}
+
+func (r *metricReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.counter.Add(float64(n))
+	return n, err
+}
+
+// metricWriter adds the bytes which are written to a counter.
+type metricWriter struct {
+	w       io.Writer
+	counter prometheus.Counter
+}
+
+func (w *metricWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.counter.Add(float64(n))
+	return n, err
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+)
+
+const (
+	// TablePlanFormat prints the execution plan as table
+	TablePlanFormat = "table"
+	// JSONPlanFormat prints the execution plan as json
+	JSONPlanFormat = "json"
+)
+
+// Plan describes which downloaders, processing rules and uploaders would be executed for the resources
+// of a set of component descriptors.
+type Plan struct {
+	Resources []ResourcePlan `json:"resources"`
+}
+
+// ResourcePlan describes the pipeline of a single resource.
+type ResourcePlan struct {
+	ComponentName    string               `json:"componentName"`
+	ComponentVersion string               `json:"componentVersion"`
+	ResourceName     string               `json:"resourceName"`
+	ResourceVersion  string               `json:"resourceVersion"`
+	Downloaders      []string             `json:"downloaders"`
+	ProcessingRules  []ProcessingRulePlan `json:"processingRules"`
+	Uploaders        []string             `json:"uploaders"`
+	// Problems contains the reasons why no pipeline can be created for the resource,
+	// e.g. unmatched or ambiguous downloaders and unmatched uploaders.
+	Problems []string `json:"problems,omitempty"`
+	// Warnings contains ambiguities which don't prevent the transport of the resource,
+	// e.g. several uploaders of a target, which are executed one after another.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ProcessingRulePlan describes a matching processing rule and its processors in execution order.
+type ProcessingRulePlan struct {
+	Name       string   `json:"name"`
+	Processors []string `json:"processors"`
+}
+
+// CreatePlan matches all resources of the component descriptors against the transport config of the transporter.
+// A resource is uploaded by all matching uploaders of a target in order, like in Transport,
+// therefore several uploaders of a target are reported as warning. It doesn't access any registry.
+func (t *Transporter) CreatePlan(cds ...*cdv2.ComponentDescriptor) *Plan {
+	plan := Plan{
+		Resources: []ResourcePlan{},
+	}
+
+	for _, cd := range cds {
+		for _, res := range cd.Resources {
+			resPlan := ResourcePlan{
+				ComponentName:    cd.Name,
+				ComponentVersion: cd.Version,
+				ResourceName:     res.Name,
+				ResourceVersion:  res.Version,
+				Downloaders:      []string{},
+				ProcessingRules:  []ProcessingRulePlan{},
+				Uploaders:        []string{},
+			}
+
+			for _, downloader := range t.Config.MatchDownloaders(*cd, res) {
+				resPlan.Downloaders = append(resPlan.Downloaders, downloader.Name)
+			}
+			for _, rule := range t.Config.MatchProcessingRules(*cd, res) {
+				rulePlan := ProcessingRulePlan{
+					Name:       rule.Name,
+					Processors: []string{},
+				}
+				for _, processor := range rule.Processors {
+					rulePlan.Processors = append(rulePlan.Processors, processor.Name)
+				}
+				resPlan.ProcessingRules = append(resPlan.ProcessingRules, rulePlan)
+			}
+			uploadersPerTarget := map[string][]string{}
+			for _, uploader := range t.Config.MatchUploaders(*cd, res) {
+				resPlan.Uploaders = append(resPlan.Uploaders, uploader.Name)
+				target := t.uploaderTarget(uploader)
+				uploadersPerTarget[target] = append(uploadersPerTarget[target], uploader.Name)
+			}
+
+			if len(resPlan.Downloaders) == 0 {
+				resPlan.Problems = append(resPlan.Problems, "no matching downloader found")
+			}
+			if len(resPlan.Downloaders) > 1 {
+				resPlan.Problems = append(resPlan.Problems, fmt.Sprintf("%d matching downloaders found, but only 1 is allowed", len(resPlan.Downloaders)))
+			}
+			for _, target := range t.targets() {
+				uploaders := uploadersPerTarget[target.name]
+				switch {
+				case len(uploaders) == 0 && len(target.name) != 0:
+					resPlan.Problems = append(resPlan.Problems, fmt.Sprintf("no matching uploader found for target repository %s", target.name))
+				case len(uploaders) == 0:
+					resPlan.Problems = append(resPlan.Problems, "no matching uploader found")
+				case len(uploaders) > 1 && len(target.name) != 0:
+					resPlan.Warnings = append(resPlan.Warnings, fmt.Sprintf("%d matching uploaders found for target repository %s, they are executed in the order %s", len(uploaders), target.name, strings.Join(uploaders, " -> ")))
+				case len(uploaders) > 1:
+					resPlan.Warnings = append(resPlan.Warnings, fmt.Sprintf("%d matching uploaders found, they are executed in the order %s", len(uploaders), strings.Join(uploaders, " -> ")))
+				}
+			}
+
+			plan.Resources = append(plan.Resources, resPlan)
+		}
+	}
+
+	return &plan
+}
+
+// HasProblems returns true if the pipeline of at least one resource can't be created unambiguously.
+// Warnings are not considered.
+func (p *Plan) HasProblems() bool {
+	for _, res := range p.Resources {
+		if len(res.Problems) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the plan in the given format.
+func (p *Plan) Write(w io.Writer, format string) error {
+	switch format {
+	case JSONPlanFormat:
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to marshal plan: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(data)); err != nil {
+			return fmt.Errorf("unable to write plan: %w", err)
+		}
+		return nil
+	case TablePlanFormat:
+		return p.writeTable(w)
+	default:
+		return fmt.Errorf("unknown plan format %s", format)
+	}
+}
+
+func (p *Plan) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tRESOURCE\tDOWNLOADER\tPROCESSING RULES\tUPLOADERS\tPROBLEMS\tWARNINGS")
+	for _, res := range p.Resources {
+		rules := []string{}
+		for _, rule := range res.ProcessingRules {
+			rules = append(rules, fmt.Sprintf("%s[%s]", rule.Name, strings.Join(rule.Processors, ",")))
+		}
+		fmt.Fprintf(tw, "%s:%s\t%s:%s\t%s\t%s\t%s\t%s\t%s\n",
+			res.ComponentName,
+			res.ComponentVersion,
+			res.ResourceName,
+			res.ResourceVersion,
+			orNone(strings.Join(res.Downloaders, ",")),
+			orNone(strings.Join(rules, " -> ")),
+			orNone(strings.Join(res.Uploaders, ",")),
+			orNone(strings.Join(res.Problems, "; ")),
+			orNone(strings.Join(res.Warnings, "; ")),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("unable to write plan: %w", err)
+	}
+	return nil
+}
+
+func orNone(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
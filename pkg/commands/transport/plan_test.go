@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/pkg/commands/transport"
+	"github.com/gardener/component-cli/pkg/transport/config"
+)
+
+var _ = Describe("Plan", func() {
+
+	var cd *cdv2.ComponentDescriptor
+
+	BeforeEach(func() {
+		cd = &cdv2.ComponentDescriptor{}
+		cd.Name = "github.com/component-cli/test-component"
+		cd.Version = "0.1.0"
+		cd.Resources = []cdv2.Resource{
+			{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "my-image",
+					Version: "0.1.0",
+					Type:    cdv2.OCIImageType,
+				},
+				Access: cdv2.NewEmptyUnstructured(cdv2.OCIRegistryType),
+			},
+			{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "my-blob",
+					Version: "0.1.0",
+					Type:    "plain-text",
+				},
+				Access: cdv2.NewEmptyUnstructured(cdv2.LocalOCIBlobType),
+			},
+			{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "my-chart",
+					Version: "0.1.0",
+					Type:    "helm",
+				},
+				Access: cdv2.NewEmptyUnstructured(cdv2.WebType),
+			},
+		}
+	})
+
+	It("should list the matching downloaders, processing rules and uploaders of every resource", func() {
+		transportCfg, err := config.ParseTransportConfig("./testdata/transport-config.yaml")
+		Expect(err).ToNot(HaveOccurred())
+
+		transporter := transport.Transporter{Config: transportCfg}
+		plan := transporter.CreatePlan(cd)
+		Expect(plan.Resources).To(HaveLen(3))
+
+		Expect(plan.Resources[0].Downloaders).To(Equal([]string{"oci-artifact-downloader"}))
+		Expect(plan.Resources[0].ProcessingRules).To(Equal([]transport.ProcessingRulePlan{
+			{
+				Name:       "process-images",
+				Processors: []string{"my-processor"},
+			},
+		}))
+		Expect(plan.Resources[0].Uploaders).To(Equal([]string{"oci-artifact-uploader"}))
+		Expect(plan.Resources[0].Problems).To(BeEmpty())
+
+		Expect(plan.Resources[1].Downloaders).To(Equal([]string{"local-oci-blob-downloader"}))
+		Expect(plan.Resources[1].ProcessingRules).To(BeEmpty())
+		Expect(plan.Resources[1].Problems).To(BeEmpty())
+
+		Expect(plan.Resources[2].Problems).To(ConsistOf("no matching downloader found", "no matching uploader found"))
+		Expect(plan.HasProblems()).To(BeTrue())
+	})
+
+	It("should allow multiple uploaders per target and treat the target repository as main target", func() {
+		dir, err := os.MkdirTemp("", "plan-")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		cfgPath := filepath.Join(dir, "transport-config.yaml")
+		Expect(os.WriteFile(cfgPath, []byte(`
+meta:
+  version: v1
+downloaders:
+- name: 'downloader'
+  type: 'LocalOciBlobDownloader'
+uploaders:
+- name: 'main-uploader'
+  type: 'LocalOciBlobUploader'
+  targetRepository: 'example.com/target'
+- name: 'us-uploader'
+  type: 'LocalOciBlobUploader'
+  targetRepository: 'example.com/us'
+- name: 'additional-us-uploader'
+  type: 'LocalOciBlobUploader'
+  targetRepository: 'example.com/us'
+`), 0644)).To(Succeed())
+		transportCfg, err := config.ParseTransportConfig(cfgPath)
+		Expect(err).ToNot(HaveOccurred())
+		cd.Resources = cd.Resources[1:2]
+
+		transporter := transport.Transporter{
+			Config:        transportCfg,
+			TargetRepoCtx: cdv2.NewOCIRegistryRepository("example.com/target", ""),
+		}
+		plan := transporter.CreatePlan(cd)
+		Expect(plan.Resources).To(HaveLen(1))
+		Expect(plan.Resources[0].Uploaders).To(HaveLen(3))
+		Expect(plan.Resources[0].Problems).To(BeEmpty())
+		Expect(plan.Resources[0].Warnings).To(ConsistOf("2 matching uploaders found for target repository example.com/us, they are executed in the order us-uploader -> additional-us-uploader"))
+		Expect(plan.HasProblems()).To(BeFalse())
+
+		transporter.TargetRepoCtx = cdv2.NewOCIRegistryRepository("example.com/other", "")
+		plan = transporter.CreatePlan(cd)
+		Expect(plan.Resources[0].Problems).To(ConsistOf("no matching uploader found"))
+	})
+
+	It("should write the plan as json and table", func() {
+		transportCfg, err := config.ParseTransportConfig("./testdata/transport-config.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		transporter := transport.Transporter{Config: transportCfg}
+		plan := transporter.CreatePlan(cd)
+
+		jsonBuf := bytes.NewBuffer([]byte{})
+		Expect(plan.Write(jsonBuf, transport.JSONPlanFormat)).To(Succeed())
+		actualPlan := transport.Plan{}
+		Expect(json.Unmarshal(jsonBuf.Bytes(), &actualPlan)).To(Succeed())
+		Expect(&actualPlan).To(Equal(plan))
+
+		tableBuf := bytes.NewBuffer([]byte{})
+		Expect(plan.Write(tableBuf, transport.TablePlanFormat)).To(Succeed())
+		Expect(tableBuf.String()).To(ContainSubstring("process-images[my-processor]"))
+		Expect(tableBuf.String()).To(ContainSubstring("no matching downloader found; no matching uploader found"))
+	})
+
+})
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/pkg/components"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/report"
+)
+
+// reportingPipeline adds the outcome of every pipeline run to a report.
+type reportingPipeline struct {
+	pipeline process.ResourceProcessingPipeline
+	report   *report.Report
+	client   ociclient.Client
+	// target is the name of the target of the pipeline, empty for the main target
+	target     string
+	targetCtx  cdv2.Repository
+	processors []string
+	// bytes is the number of bytes which have been read by the first uploader of the pipeline
+	bytes *int64
+}
+
+func (p *reportingPipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	start := time.Now()
+	processedCD, processedRes, err := p.pipeline.Process(ctx, cd, res)
+
+	resReport := report.ResourceReport{
+		Name:             res.Name,
+		Version:          res.Version,
+		ExtraIdentity:    res.ExtraIdentity,
+		BytesTransferred: atomic.LoadInt64(p.bytes),
+		Duration:         report.Since(start),
+		Processors:       p.processors,
+	}
+	resReport.SourceRef, resReport.SourceDigest = resourceLocation(ctx, p.client, cd.GetEffectiveRepositoryContext(), cd, res)
+	if err == nil {
+		resReport.TargetRef, resReport.TargetDigest = resourceLocation(ctx, p.client, p.targetCtx, cd, processedRes)
+	}
+	resReport.Status, resReport.Error = report.StatusFromError(err)
+	p.report.AddTargetResource(p.target, cd.Name, cd.Version, resReport)
+
+	return processedCD, processedRes, err
+}
+
+// countingProcessor counts the bytes which are read by a processor.
+type countingProcessor struct {
+	processor process.ResourceStreamProcessor
+	bytes     *int64
+}
+
+func (p *countingProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	return p.processor.Process(ctx, &countingReader{r: r, bytes: p.bytes}, w)
+}
+
+type countingReader struct {
+	r     io.Reader
+	bytes *int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(r.bytes, int64(n))
+	return n, err
+}
+
+// resourceLocation returns the reference and digest of a resource.
+// Digests of oci artifacts are resolved from the registry, errors are ignored as the location is only informational.
+func resourceLocation(ctx context.Context, client ociclient.Client, repoCtx cdv2.Repository, cd cdv2.ComponentDescriptor, res cdv2.Resource) (string, string) {
+	if res.Access == nil {
+		return "", ""
+	}
+	switch res.Access.Type {
+	case cdv2.OCIRegistryType:
+		acc := cdv2.OCIRegistryAccess{}
+		if err := res.Access.DecodeInto(&acc); err != nil {
+			return "", ""
+		}
+		desc, _, err := client.GetRawManifest(ctx, acc.ImageReference)
+		if err != nil {
+			return acc.ImageReference, ""
+		}
+		return acc.ImageReference, desc.Digest.String()
+	case cdv2.LocalOCIBlobType:
+		acc := cdv2.LocalOCIBlobAccess{}
+		if err := res.Access.DecodeInto(&acc); err != nil {
+			return "", ""
+		}
+		if repoCtx == nil {
+			return "", acc.Digest
+		}
+		ref, err := components.OCIRef(repoCtx, cd.Name, cd.Version)
+		if err != nil {
+			return "", acc.Digest
+		}
+		return ref, acc.Digest
+	case cdv2.WebType:
+		acc := cdv2.Web{}
+		if err := res.Access.DecodeInto(&acc); err != nil {
+			return "", ""
+		}
+		return acc.URL, ""
+	case cdv2.S3AccessType:
+		acc := cdv2.S3Access{}
+		if err := res.Access.DecodeInto(&acc); err != nil {
+			return "", ""
+		}
+		return fmt.Sprintf("s3://%s/%s", acc.BucketName, acc.ObjectKey), ""
+	default:
+		return "", ""
+	}
+}
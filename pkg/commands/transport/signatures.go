@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	cdv2Sign "github.com/gardener/component-spec/bindings-go/apis/v2/signatures"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	"github.com/go-logr/logr"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/pkg/signatures"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/utils"
+)
+
+// digestVerifyingPipeline verifies that the digest of a resource whose access has been rewritten by the pipeline
+// matches the digest of the resource. The signatures of a component descriptor stay valid as long as the digests
+// of all resources are unchanged, because the normalisation excludes the access.
+// Processors which modify the resource blob, e.g. the oci artifact filter, update the digest, which invalidates
+// the signatures. Such a change is only accepted if the component descriptor is re-signed.
+type digestVerifyingPipeline struct {
+	pipeline     process.ResourceProcessingPipeline
+	client       ociclient.Client
+	cache        cache.Cache
+	blobResolver ctf.BlobResolver
+	// resign defines whether the component descriptor is signed again after the transport.
+	resign bool
+}
+
+func (p *digestVerifyingPipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	processedCD, processedRes, err := p.pipeline.Process(ctx, cd, res)
+	if err != nil {
+		return nil, cdv2.Resource{}, err
+	}
+
+	if processedRes.Digest == nil {
+		processedRes.Digest = res.Digest
+	}
+	if digestChanged(res, processedRes) && !p.resign {
+		return nil, cdv2.Resource{}, fmt.Errorf("the digest of resource %s has been changed by a processor, which invalidates the signatures of the component descriptor: a signer is required to re-sign the component descriptor", res.Name)
+	}
+	if processedRes.Digest == nil || !accessChanged(res, processedRes) {
+		return processedCD, processedRes, nil
+	}
+
+	log := logr.FromContextOrDiscard(ctx).WithValues("component", cd.Name, "version", cd.Version, "resource", res.Name)
+	if res.Access.Type == cdv2.OCIRegistryType && hasLabel(processedRes.Labels, processutils.OriginalAccessLabel) {
+		// the digest of a serialized oci artifact differs from the digest of its manifest,
+		// therefore the manifest digest is calculated from the deserialized oci artifact
+		if err := p.verifySerializedOCIArtifactDigest(ctx, processedRes); err != nil {
+			return nil, cdv2.Resource{}, fmt.Errorf("unable to verify digest of rewritten resource: %w", err)
+		}
+		log.V(3).Info("verified digest of serialized oci artifact")
+		return processedCD, processedRes, nil
+	}
+
+	if err := signatures.VerifyResourceDigest(ctx, p.client, *processedCD, processedRes, *processedRes.Digest, p.blobResolver); err != nil {
+		return nil, cdv2.Resource{}, fmt.Errorf("unable to verify digest of rewritten resource: %w", err)
+	}
+	log.V(3).Info("verified digest of rewritten resource")
+
+	return processedCD, processedRes, nil
+}
+
+// verifySerializedOCIArtifactDigest verifies that the manifest digest of an oci artifact, which has been serialized into
+// a local blob, matches the digest of the resource. The manifest digest is the digest of the manifest or image index which
+// would be pushed for the deserialized oci artifact.
+func (p *digestVerifyingPipeline) verifySerializedOCIArtifactDigest(ctx context.Context, res cdv2.Resource) error {
+	expected := *res.Digest
+	if p.blobResolver == nil {
+		return errors.New("no blob resolver for the local blob of the serialized oci artifact")
+	}
+	if expected.HashAlgorithm != cdv2Sign.SHA256 {
+		return fmt.Errorf("unsupported hash algorithm %s", expected.HashAlgorithm)
+	}
+
+	blob, err := processutils.NewTempFile()
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+	if _, err := p.blobResolver.Resolve(ctx, res, blob); err != nil {
+		return fmt.Errorf("unable to resolve blob: %w", err)
+	}
+	if _, err := blob.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to seek to beginning of tempfile: %w", err)
+	}
+
+	artifact, err := processutils.DeserializeOCIArtifact(blob, p.cache)
+	if err != nil {
+		return fmt.Errorf("unable to deserialize oci artifact: %w", err)
+	}
+	var desc ocispecv1.Descriptor
+	if artifact.IsIndex() {
+		desc, _, err = ociclient.CreateDescriptorFromIndex(artifact.GetIndex())
+	} else {
+		desc, err = ociclient.CreateDescriptorFromManifest(artifact.GetManifest().Data)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create descriptor of oci artifact: %w", err)
+	}
+
+	actual := cdv2.DigestSpec{
+		HashAlgorithm:          cdv2Sign.SHA256,
+		NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+		Value:                  desc.Digest.Encoded(),
+	}
+	if actual.NormalisationAlgorithm != expected.NormalisationAlgorithm || actual.Value != expected.Value {
+		return fmt.Errorf("digest of resource %s:%s does not match: expected %s:%s, got %s:%s", res.Name, res.Version,
+			expected.NormalisationAlgorithm, expected.Value, actual.NormalisationAlgorithm, actual.Value)
+	}
+	return nil
+}
+
+// digestChanged checks whether the digest of a resource has been changed by a processor.
+func digestChanged(res, processedRes cdv2.Resource) bool {
+	if res.Digest == nil || processedRes.Digest == nil {
+		return false
+	}
+	return *res.Digest != *processedRes.Digest
+}
+
+// resourceDigestsChanged checks whether the digest of any resource of the processed component descriptor
+// differs from the digest of the same resource in the source component descriptor.
+func resourceDigestsChanged(cd, processedCD *cdv2.ComponentDescriptor) bool {
+	for i, res := range processedCD.Resources {
+		if i >= len(cd.Resources) || digestChanged(cd.Resources[i], res) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessChanged checks whether the access of a resource has been rewritten.
+func accessChanged(res, processedRes cdv2.Resource) bool {
+	if res.Access == nil || processedRes.Access == nil {
+		return res.Access != processedRes.Access
+	}
+	var access, processedAccess interface{}
+	if err := json.Unmarshal(res.Access.Raw, &access); err != nil {
+		return true
+	}
+	if err := json.Unmarshal(processedRes.Access.Raw, &processedAccess); err != nil {
+		return true
+	}
+	return !reflect.DeepEqual(access, processedAccess)
+}
+
+func hasLabel(labels cdv2.Labels, name string) bool {
+	for _, label := range labels {
+		if label.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// targetBlobResolver reads the local oci blobs of a component from the target repository.
+type targetBlobResolver struct {
+	client ociclient.Client
+	// ref is the reference the local oci blobs have been uploaded to.
+	ref string
+}
+
+// newTargetBlobResolver creates a blob resolver for the local blobs of a component in a target.
+// The local blobs in a target CTF are read from the staged component archive.
+func (t *Transporter) newTargetBlobResolver(target transportTarget, cd cdv2.ComponentDescriptor) (ctf.BlobResolver, error) {
+	if target.ctf != nil {
+		caFs, err := target.ctf.ComponentArchiveFS(cd.Name, cd.Version)
+		if err != nil {
+			return nil, err
+		}
+		return ctf.NewComponentArchiveBlobResolver(caFs), nil
+	}
+	if target.repoCtx == nil {
+		return nil, nil
+	}
+	return &targetBlobResolver{
+		client: t.OciClient,
+		ref:    utils.CalculateBlobUploadRef(*target.repoCtx, cd.Name, cd.Version),
+	}, nil
+}
+
+// Info returns the media type and digest of a local oci blob. The size of the blob is not determined.
+func (r *targetBlobResolver) Info(ctx context.Context, res cdv2.Resource) (*ctf.BlobInfo, error) {
+	if res.Access == nil || res.Access.GetType() != cdv2.LocalOCIBlobType {
+		return nil, ctf.UnsupportedResolveType
+	}
+	acc := cdv2.LocalOCIBlobAccess{}
+	if err := res.Access.DecodeInto(&acc); err != nil {
+		return nil, fmt.Errorf("unable to decode access: %w", err)
+	}
+	dgst, err := digest.Parse(acc.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse digest: %w", err)
+	}
+	return &ctf.BlobInfo{
+		MediaType: res.Type,
+		Digest:    dgst.String(),
+	}, nil
+}
+
+func (r *targetBlobResolver) Resolve(ctx context.Context, res cdv2.Resource, writer io.Writer) (*ctf.BlobInfo, error) {
+	info, err := r.Info(ctx, res)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := ocispecv1.Descriptor{
+		Digest: digest.Digest(info.Digest),
+	}
+	if err := r.client.Fetch(ctx, r.ref, desc, writer); err != nil {
+		return nil, fmt.Errorf("unable to fetch blob from %s: %w", r.ref, err)
+	}
+	return info, nil
+}
+
+// signComponentDescriptor replaces the signature of a component descriptor with a new signature of the signer.
+// The signatures of other signers are kept unless the digest of a resource differs from the source component descriptor,
+// because they cannot be valid anymore.
+func (t *Transporter) signComponentDescriptor(ctx context.Context, sourceCD, cd *cdv2.ComponentDescriptor) error {
+	if len(t.SignatureName) == 0 {
+		return errors.New("signature name must not be empty")
+	}
+
+	kept := []cdv2.Signature{}
+	if !resourceDigestsChanged(sourceCD, cd) {
+		for _, signature := range cd.Signatures {
+			if signature.Name != t.SignatureName {
+				kept = append(kept, signature)
+			}
+		}
+	} else if len(cd.Signatures) != 0 {
+		logr.FromContextOrDiscard(ctx).V(3).Info("drop the signatures of the component descriptor because resource digests have been changed", "component", cd.Name, "version", cd.Version)
+	}
+	cd.Signatures = kept
+
+	hasher, err := cdv2Sign.HasherForName(cdv2Sign.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed creating hasher: %w", err)
+	}
+	if err := cdv2Sign.SignComponentDescriptor(cd, t.Signer, *hasher, t.SignatureName); err != nil {
+		return fmt.Errorf("failed signing component descriptor: %w", err)
+	}
+	return nil
+}
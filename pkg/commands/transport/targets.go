@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/pkg/transport/config"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+// transportTarget is a repository or CTF the component descriptors are transported to.
+type transportTarget struct {
+	// name is the base url of an additional target repository which is declared by uploaders.
+	// It is empty for the main target of the transport.
+	name    string
+	repoCtx *cdv2.OCIRegistryRepository
+	ctf     *processutils.CTFWriter
+}
+
+// repository returns the target repository or nil if the component descriptors are written to a CTF.
+func (t transportTarget) repository() cdv2.Repository {
+	if t.repoCtx == nil {
+		return nil
+	}
+	return t.repoCtx
+}
+
+// targets returns the main target followed by the additional target repositories which are declared by uploaders.
+func (t *Transporter) targets() []transportTarget {
+	targets := []transportTarget{
+		{
+			repoCtx: t.TargetRepoCtx,
+			ctf:     t.TargetCTF,
+		},
+	}
+	if t.Config == nil {
+		return targets
+	}
+	for _, baseURL := range t.Config.TargetRepositories() {
+		if t.isMainTarget(baseURL) {
+			continue
+		}
+		targets = append(targets, transportTarget{
+			name:    baseURL,
+			repoCtx: cdv2.NewOCIRegistryRepository(baseURL, ""),
+		})
+	}
+	return targets
+}
+
+// uploaderTarget returns the name of the target an uploader uploads to.
+func (t *Transporter) uploaderTarget(def config.ParsedUploaderDefinition) string {
+	if t.isMainTarget(def.TargetRepository) {
+		return ""
+	}
+	return def.TargetRepository
+}
+
+func (t *Transporter) isMainTarget(baseURL string) bool {
+	return len(baseURL) == 0 || (t.TargetRepoCtx != nil && t.TargetRepoCtx.BaseURL == baseURL)
+}
+
+// sharedSource downloads and processes a resource only once for the pipelines of multiple targets.
+// The output of the last processor is buffered in a temporary file and replayed to the uploaders of every target.
+type sharedSource struct {
+	pipeline process.ResourceProcessingPipeline
+	// names contains the names of the downloader and the processors in execution order.
+	names []string
+
+	once sync.Once
+	path string
+	err  error
+}
+
+// newSharedSource creates the shared download and processing of a resource.
+// The temporary file must be removed via cleanup().
+func (t *Transporter) newSharedSource(cd cdv2.ComponentDescriptor, res cdv2.Resource) (*sharedSource, error) {
+	processors, names, err := t.createSourceProcessors(cd, res)
+	if err != nil {
+		return nil, err
+	}
+	s := &sharedSource{
+		names: names,
+	}
+	s.pipeline, err = t.newPipeline(append(processors, &capturingProcessor{source: s}))
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// run downloads and processes the resource on the first call. Subsequent calls return the result of the first call.
+func (s *sharedSource) run(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) error {
+	s.once.Do(func() {
+		if _, _, err := s.pipeline.Process(ctx, cd, res); err != nil {
+			s.err = fmt.Errorf("unable to download and process resource: %w", err)
+		}
+	})
+	return s.err
+}
+
+func (s *sharedSource) cleanup() {
+	if len(s.path) != 0 {
+		os.Remove(s.path)
+	}
+}
+
+// sharedSourcePipeline runs the shared source of a resource before the pipeline of a target.
+// The source is run with the context of the job, as the processor timeouts only apply to its single processors.
+type sharedSourcePipeline struct {
+	source   *sharedSource
+	pipeline process.ResourceProcessingPipeline
+}
+
+func (p *sharedSourcePipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	if err := p.source.run(ctx, cd, res); err != nil {
+		return nil, cdv2.Resource{}, err
+	}
+	return p.pipeline.Process(ctx, cd, res)
+}
+
+// capturingProcessor writes its input to the temporary file of a shared source and forwards it unchanged.
+type capturingProcessor struct {
+	source *sharedSource
+}
+
+func (p *capturingProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file: %w", err)
+	}
+	defer f.Close()
+	p.source.cleanup()
+	p.source.path = f.Name()
+
+	if _, err := io.Copy(io.MultiWriter(f, w), r); err != nil {
+		return fmt.Errorf("unable to capture processor message: %w", err)
+	}
+	return nil
+}
+
+// replayingProcessor replaces the downloader and processors in the pipeline of a target.
+// It ignores its input and writes the captured output of the shared source.
+type replayingProcessor struct {
+	source *sharedSource
+}
+
+func (p *replayingProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	f, err := os.Open(p.source.path)
+	if err != nil {
+		return fmt.Errorf("unable to open processor message of shared source: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("unable to replay processor message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,918 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	cdv2Sign "github.com/gardener/component-spec/bindings-go/apis/v2/signatures"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	cdoci "github.com/gardener/component-spec/bindings-go/oci"
+	"github.com/go-logr/logr"
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	ocimetrics "github.com/gardener/component-cli/ociclient/metrics"
+	ociopts "github.com/gardener/component-cli/ociclient/options"
+	"github.com/gardener/component-cli/pkg/components"
+	"github.com/gardener/component-cli/pkg/logger"
+	"github.com/gardener/component-cli/pkg/transport/config"
+	"github.com/gardener/component-cli/pkg/transport/journal"
+	"github.com/gardener/component-cli/pkg/transport/metrics"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/downloaders"
+	"github.com/gardener/component-cli/pkg/transport/process/extensions"
+	"github.com/gardener/component-cli/pkg/transport/process/processors"
+	"github.com/gardener/component-cli/pkg/transport/process/uploaders"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/transport/report"
+	"github.com/gardener/component-cli/pkg/transport/tracing"
+	"github.com/gardener/component-cli/pkg/utils"
+)
+
+// Options contains all options to transport a component descriptor.
+type Options struct {
+	ComponentName    string
+	ComponentVersion string
+	SourceRepository string
+	TargetRepository string
+	// SourceCTFPath is the path to a CTF the component descriptors are read from instead of the source repository.
+	SourceCTFPath string
+	// TargetCTFPath is the path to a CTF the component descriptors are written to instead of the target repository.
+	TargetCTFPath string
+
+	// TransportCfgPath is the path to the transport config file.
+	TransportCfgPath string
+	// Recursive specifies if all component references should also be transported.
+	Recursive bool
+	// Concurrency is the number of resources which are processed in parallel.
+	Concurrency int
+	// Streaming specifies if the processors of a pipeline are connected via pipes instead of temporary files.
+	Streaming bool
+	// DryRun specifies that only the execution plan is printed instead of transporting the resources.
+	DryRun bool
+	// DryRunFormat is the output format of the execution plan.
+	DryRunFormat string
+	// JournalPath is the path to the journal file which records processed resources and uploaded component descriptors.
+	// Defaults to a file in the cache dir which is derived from the arguments.
+	JournalPath string
+	// Resume specifies if the work recorded in the journal of a previous run should be skipped.
+	Resume bool
+	// ReportPath is the path to the file the transport report is written to.
+	// +optional
+	ReportPath string
+	// PrivateKeyPath is the path to a RSA private key which is used to re-sign the transported component descriptors.
+	// The existing signatures are kept if no private key is given.
+	// +optional
+	PrivateKeyPath string
+	// SignatureName is the name of the signature which is created or replaced when re-signing.
+	// +optional
+	SignatureName string
+	// MetricsPath is the path to the file the metrics are written to in the prometheus text exposition format.
+	// +optional
+	MetricsPath string
+
+	// OciOptions contains all exposed options to configure the oci client.
+	OciOptions ociopts.Options
+}
+
+// NewTransportCommand creates a new transport command.
+func NewTransportCommand(ctx context.Context) *cobra.Command {
+	opts := &Options{}
+	cmd := &cobra.Command{
+		Use:   "transport COMPONENT_NAME VERSION --from SOURCE_REPOSITORY --to TARGET_REPOSITORY --transport-config TRANSPORT_CONFIG",
+		Args:  cobra.ExactArgs(2),
+		Short: "[EXPERIMENTAL] transports a component descriptor and its resources from a repository to another",
+		Long: `
+transport resolves a component descriptor from the source repository and processes all of its resources
+as defined in the transport config. For every resource, exactly one matching downloader, all matching
+processing rules and one or more matching uploaders are executed in this order.
+The rewritten component descriptor is finally uploaded to the target repository.
+
+Uploaders can declare additional target repositories via "targetRepository". A resource is then downloaded
+and processed only once and uploaded by the matching uploaders of every target repository. One rewritten
+component descriptor is uploaded to every target repository. Uploaders without a target repository upload to
+the target of "--to" or "--to-ctf".
+
+By default the component descriptor and all its component references are recursively transported.
+This behavior can be overwritten by specifying "--recursive=false"
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(args); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if err := opts.Run(ctx, logger.Log, osfs.New()); err != nil {
+				logger.Log.Error(err, "")
+				os.Exit(1)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	cmd.AddCommand(NewValidateCommand(ctx))
+
+	return cmd
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.SourceRepository, "from", "", "source repository base url.")
+	fs.StringVar(&o.TargetRepository, "to", "", "target repository where the components are transported to.")
+	fs.StringVar(&o.SourceCTFPath, "from-ctf", "", "path to a CTF archive the component descriptors are read from instead of a source repository. local blobs of the component archives are downloaded with a CtfDownloader.")
+	fs.StringVar(&o.TargetCTFPath, "to-ctf", "", "path to a CTF archive the component archives are written to instead of a target repository, an existing CTF archive is extended. resources are written as local blobs with a CtfUploader. blobs are staged in the directory \"<path>.staging\" until the CTF archive is written.")
+	fs.StringVar(&o.TransportCfgPath, "transport-config", "", "path to the transport config file.")
+	fs.BoolVar(&o.Recursive, "recursive", true, "Recursively transport the component descriptor and its references.")
+	fs.IntVar(&o.Concurrency, "concurrency", process.DefaultConcurrency, "number of resources which are processed in parallel.")
+	fs.BoolVar(&o.Streaming, "streaming", false, "stream resources between processors instead of buffering them in temporary files. retries of processors are not supported in streaming mode.")
+	fs.BoolVar(&o.DryRun, "dry-run", false, "only print the downloader, processing rules, and uploaders which would be executed for every resource. component descriptors are resolved from the source repository, but no resource is downloaded or uploaded. exits with an error if a resource matches no or multiple downloaders, or no uploader of a target repository. resources which match several uploaders of a target repository are reported as warning, as the uploaders are executed one after another.")
+	fs.StringVar(&o.DryRunFormat, "dry-run-format", TablePlanFormat, "output format of the dry run plan. one of table, json.")
+	fs.StringVar(&o.JournalPath, "journal", "", "path to the journal file which records every processed resource and uploaded component descriptor. defaults to a file in the cache dir which is derived from the arguments.")
+	fs.BoolVar(&o.Resume, "resume", false, "resume an interrupted transport by skipping the work recorded in the journal. recorded entries are only skipped if their source digest and, for oci artifacts, their target digest are unchanged.")
+	fs.StringVar(&o.ReportPath, "report", "", "path to a file the transport report is written to. the report contains the outcome, digests, transferred bytes, duration, and processors of every resource. the format is derived from the file extension, one of .json, .yaml, .yml.")
+	fs.StringVar(&o.PrivateKeyPath, "private-key", "", "path to a RSA private key which is used to re-sign the transported component descriptors with the signature name. existing signatures are kept if not set, which requires that processors do not change the digests of resources. the signatures of other signers are dropped if a digest has been changed. the digests of rewritten resources are always verified.")
+	fs.StringVar(&o.SignatureName, "signature-name", "", "name of the signature which is created or replaced when re-signing with --private-key.")
+	fs.StringVar(&o.MetricsPath, "metrics-file", "", "path to a file the metrics of the transport are written to at the end of the run in the prometheus text exposition format. the metrics contain the processed resources, durations, retries, and failures per processor type and the downloaded and uploaded bytes.")
+	o.OciOptions.AddFlags(fs)
+}
+
+func (o *Options) Complete(args []string) error {
+	o.ComponentName = args[0]
+	o.ComponentVersion = args[1]
+
+	var err error
+	o.OciOptions.CacheDir, err = utils.CacheDir()
+	if err != nil {
+		return fmt.Errorf("unable to get oci cache directory: %w", err)
+	}
+
+	if len(o.JournalPath) == 0 {
+		o.JournalPath = journal.DefaultPath(o.OciOptions.CacheDir, "transport", o.ComponentName, o.ComponentVersion, o.SourceRepository, o.TargetRepository, o.SourceCTFPath, o.TargetCTFPath, o.TransportCfgPath)
+	}
+
+	return o.Validate()
+}
+
+// Validate validates transport options
+func (o *Options) Validate() error {
+	if len(o.SourceRepository) == 0 && len(o.SourceCTFPath) == 0 {
+		return errors.New("a source repository or a source ctf has to be specified")
+	}
+	if len(o.SourceRepository) != 0 && len(o.SourceCTFPath) != 0 {
+		return errors.New("only one of source repository and source ctf can be specified")
+	}
+	if len(o.TargetRepository) == 0 && len(o.TargetCTFPath) == 0 {
+		return errors.New("a target repository or a target ctf has to be specified")
+	}
+	if len(o.TargetRepository) != 0 && len(o.TargetCTFPath) != 0 {
+		return errors.New("only one of target repository and target ctf can be specified")
+	}
+	if len(o.TransportCfgPath) == 0 {
+		return errors.New("a path to a transport config file has to be specified")
+	}
+	if o.Concurrency < 1 {
+		return errors.New("concurrency must be greater than 0")
+	}
+	if o.DryRunFormat != TablePlanFormat && o.DryRunFormat != JSONPlanFormat {
+		return fmt.Errorf("unknown dry run format %s, must be one of %s, %s", o.DryRunFormat, TablePlanFormat, JSONPlanFormat)
+	}
+	if len(o.ReportPath) != 0 {
+		if _, err := report.FormatFromPath(o.ReportPath); err != nil {
+			return err
+		}
+	}
+	if len(o.PrivateKeyPath) != 0 && len(o.SignatureName) == 0 {
+		return errors.New("a signature name has to be specified to re-sign with a private key")
+	}
+	if len(o.PrivateKeyPath) == 0 && len(o.SignatureName) != 0 {
+		return errors.New("a private key has to be specified to re-sign with a signature name")
+	}
+	return nil
+}
+
+func (o *Options) Run(ctx context.Context, log logr.Logger, fs vfs.FileSystem) error {
+	ctx = logr.NewContext(ctx, log)
+	ociClient, ociCache, err := o.OciOptions.Build(log, fs)
+	if err != nil {
+		return fmt.Errorf("unable to build oci client: %s", err.Error())
+	}
+	defer ociCache.Close()
+
+	transportCfg, err := config.ParseTransportConfig(o.TransportCfgPath)
+	if err != nil {
+		return fmt.Errorf("unable to parse transport config: %w", err)
+	}
+
+	var resolver ctf.ComponentResolver = cdoci.NewResolver(ociClient)
+	sourceCtx := cdv2.NewOCIRegistryRepository(o.SourceRepository, "")
+	downloaderFactory := downloaders.NewDownloaderFactory(ociClient, ociCache)
+	if len(o.SourceCTFPath) != 0 {
+		sourceCTF, err := processutils.ReadCTF(fs, o.SourceCTFPath)
+		if err != nil {
+			return err
+		}
+		defer sourceCTF.Close()
+		resolver = &ctfResolver{ctf: sourceCTF}
+		downloaderFactory = downloaderFactory.WithSourceCTF(sourceCTF)
+	}
+
+	var targetCtx *cdv2.OCIRegistryRepository
+	uploaderTargetCtx := cdv2.OCIRegistryRepository{}
+	if len(o.TargetRepository) != 0 {
+		targetCtx = cdv2.NewOCIRegistryRepository(o.TargetRepository, "")
+		uploaderTargetCtx = *targetCtx
+	}
+
+	cds, err := ResolveRecursive(ctx, resolver, sourceCtx, o.ComponentName, o.ComponentVersion, o.Recursive)
+	if err != nil {
+		return fmt.Errorf("unable to resolve component descriptors: %w", err)
+	}
+
+	if o.DryRun {
+		t := &Transporter{
+			Config:        transportCfg,
+			TargetRepoCtx: targetCtx,
+		}
+		plan := t.CreatePlan(cds...)
+		if err := plan.Write(os.Stdout, o.DryRunFormat); err != nil {
+			return err
+		}
+		if plan.HasProblems() {
+			return errors.New("unable to create the pipelines of all resources unambiguously")
+		}
+		return nil
+	}
+
+	registry := prometheus.NewRegistry()
+	metrics.RegisterTransportMetrics(registry)
+	ocimetrics.RegisterCacheMetrics(registry)
+
+	executor, err := process.NewExecutor(o.Concurrency)
+	if err != nil {
+		return fmt.Errorf("unable to create executor: %w", err)
+	}
+
+	j, err := journal.Open(o.JournalPath, o.Resume)
+	if err != nil {
+		return err
+	}
+	defer j.Close()
+	log.V(3).Info("use journal", "path", o.JournalPath)
+
+	defer func() {
+		if err := extensions.StopWorkers(); err != nil {
+			log.Error(err, "unable to stop extension workers")
+		}
+	}()
+
+	var targetCTF *processutils.CTFWriter
+	if len(o.TargetCTFPath) != 0 {
+		targetCTF, err = processutils.NewCTFWriter(fs, o.TargetCTFPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := targetCTF.Close(); err != nil {
+				log.Error(err, "unable to close target ctf")
+			}
+		}()
+	}
+
+	t := Transporter{
+		Config:            transportCfg,
+		Executor:          executor,
+		DownloaderFactory: downloaderFactory,
+		ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+		UploaderFactory:   uploaders.NewUploaderFactory(ociClient, ociCache, uploaderTargetCtx),
+		OciClient:         ociClient,
+		Cache:             ociCache,
+		TargetRepoCtx:     targetCtx,
+		TargetCTF:         targetCTF,
+		Streaming:         o.Streaming,
+		Journal:           j,
+	}
+	if len(o.ReportPath) != 0 {
+		t.Report = report.New()
+	}
+	if len(o.PrivateKeyPath) != 0 {
+		signer, err := cdv2Sign.CreateRsaSignerFromKeyFile(o.PrivateKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed creating rsa signer: %w", err)
+		}
+		t.Signer = signer
+		t.SignatureName = o.SignatureName
+	}
+
+	transportCtx, span := tracing.Start(ctx, "transport", "component", o.ComponentName, "version", o.ComponentVersion)
+	transportErr := t.Transport(transportCtx, cds...)
+	span.RecordError(transportErr)
+	span.End()
+
+	if len(o.MetricsPath) != 0 {
+		if err := metrics.WriteTextFile(fs, o.MetricsPath, registry); err != nil {
+			if transportErr == nil {
+				return err
+			}
+			log.Error(err, "unable to write metrics")
+		}
+	}
+	if t.Report != nil {
+		t.Report.Finish()
+		if err := t.Report.Write(fs, o.ReportPath); err != nil {
+			if transportErr == nil {
+				return err
+			}
+			log.Error(err, "unable to write report")
+		}
+	}
+	if transportErr != nil {
+		return transportErr
+	}
+
+	fmt.Printf("Successfully transported component descriptor %s:%s from %s to %s\n", o.ComponentName, o.ComponentVersion, o.SourceRepository+o.SourceCTFPath, o.TargetRepository+o.TargetCTFPath)
+	return nil
+}
+
+// ResolveRecursive resolves a component descriptor and, if recursive is set, all its transitively
+// referenced component descriptors. Every component descriptor is only returned once.
+func ResolveRecursive(ctx context.Context, resolver ctf.ComponentResolver, repoCtx cdv2.Repository, name, version string, recursive bool) ([]*cdv2.ComponentDescriptor, error) {
+	cds := []*cdv2.ComponentDescriptor{}
+	visited := map[string]bool{}
+
+	var resolve func(name, version string) error
+	resolve = func(name, version string) error {
+		key := name + ":" + version
+		if visited[key] {
+			return nil
+		}
+		visited[key] = true
+
+		cd, err := resolver.Resolve(ctx, repoCtx, name, version)
+		if err != nil {
+			return fmt.Errorf("unable to resolve component descriptor %s: %w", key, err)
+		}
+		cds = append(cds, cd)
+
+		if !recursive {
+			return nil
+		}
+		for _, ref := range cd.ComponentReferences {
+			if err := resolve(ref.ComponentName, ref.Version); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := resolve(name, version); err != nil {
+		return nil, err
+	}
+	return cds, nil
+}
+
+// Transporter transports component descriptors by running the processing pipelines defined
+// in a transport config for all resources.
+type Transporter struct {
+	Config            *config.ParsedTransportConfig
+	Executor          *process.Executor
+	DownloaderFactory *downloaders.DownloaderFactory
+	ProcessorFactory  *processors.ProcessorFactory
+	UploaderFactory   *uploaders.UploaderFactory
+	OciClient         ociclient.Client
+	Cache             cache.Cache
+	// TargetRepoCtx is the repository the component descriptors are uploaded to.
+	// It is injected into the component descriptors if set.
+	TargetRepoCtx *cdv2.OCIRegistryRepository
+	// TargetCTF is the CTF the component archives are written to instead of the target repository.
+	// CTF uploaders of the main target write into it.
+	// +optional
+	TargetCTF *processutils.CTFWriter
+	// Streaming specifies if the created pipelines connect their processors via pipes.
+	Streaming bool
+	// Journal records processed resources and uploaded component descriptors.
+	// Work which is already recorded in the journal is skipped.
+	// +optional
+	Journal *journal.Journal
+	// Report collects the outcome of all component descriptors and resources.
+	// +optional
+	Report *report.Report
+	// Signer re-signs the transported component descriptors with the signature name.
+	// The existing signatures are kept if no signer is set, as the digests of all rewritten resources are verified.
+	// Without a signer, the transport fails if a processor changes the digest of a resource.
+	// +optional
+	Signer cdv2Sign.Signer
+	// SignatureName is the name of the signature which is created or replaced by the signer.
+	// +optional
+	SignatureName string
+}
+
+// Transport processes the resources of all component descriptors concurrently and uploads the
+// rewritten component descriptors to the target repository. Component descriptors are only
+// uploaded if all resources have been processed successfully.
+// If uploaders declare additional target repositories, every resource is downloaded and processed
+// only once and uploaded by the uploaders of every target. One rewritten component descriptor is
+// uploaded per target.
+func (t *Transporter) Transport(ctx context.Context, cds ...*cdv2.ComponentDescriptor) error {
+	log := logr.FromContextOrDiscard(ctx)
+	start := time.Now()
+	targets := t.targets()
+
+	// jobResources points to the location of the processed resource of every job
+	type resourceIndex struct {
+		target int
+		cd     *cdv2.ComponentDescriptor
+		index  int
+	}
+	jobResources := []resourceIndex{}
+	// processedResources and uploadedCDs are indexed by target
+	processedResources := make([]map[*cdv2.ComponentDescriptor][]cdv2.Resource, len(targets))
+	uploadedCDs := make([]map[*cdv2.ComponentDescriptor]bool, len(targets))
+	for i := range targets {
+		processedResources[i] = map[*cdv2.ComponentDescriptor][]cdv2.Resource{}
+		uploadedCDs[i] = map[*cdv2.ComponentDescriptor]bool{}
+	}
+
+	sources := []*sharedSource{}
+	defer func() {
+		for _, source := range sources {
+			source.cleanup()
+		}
+	}()
+
+	// sourceDigests contains the digests of the source component descriptors, which are recorded in the journal
+	sourceDigests := map[*cdv2.ComponentDescriptor]string{}
+	if t.Journal != nil {
+		for _, cd := range cds {
+			dgst, err := journal.ComponentDescriptorDigest(cd)
+			if err != nil {
+				return err
+			}
+			sourceDigests[cd] = dgst
+		}
+	}
+
+	jobs := []process.ProcessingJob{}
+	for _, cd := range cds {
+		sourceRef, _ := components.OCIRef(cd.GetEffectiveRepositoryContext(), cd.Name, cd.Version)
+
+		// pendingTargets contains the targets the component descriptor hasn't been transported to yet
+		pendingTargets := []int{}
+		for i, target := range targets {
+			t.reportComponent(target, cd, func(c *report.ComponentReport) {
+				c.SourceRef = sourceRef
+			})
+
+			if t.isRecorded(ctx, journal.TargetKey(journal.ComponentKey(cd.Name, cd.Version), target.name), sourceDigests[cd]) {
+				log.Info("skip component descriptor which has already been transported", "component", cd.Name, "version", cd.Version, "target", target.name)
+				uploadedCDs[i][cd] = true
+				t.reportComponent(target, cd, func(c *report.ComponentReport) {
+					c.Status = report.StatusSkipped
+				})
+				continue
+			}
+
+			log.Info("transport component descriptor", "component", cd.Name, "version", cd.Version, "target", target.name)
+			processedResources[i][cd] = make([]cdv2.Resource, len(cd.Resources))
+			pendingTargets = append(pendingTargets, i)
+		}
+
+		for j, res := range cd.Resources {
+			var resSourceDigest string
+			if t.Journal != nil && len(pendingTargets) != 0 {
+				var err error
+				resSourceDigest, err = resourceSourceDigest(ctx, t.OciClient, *cd, res)
+				if err != nil {
+					return err
+				}
+			}
+			resTargets := []int{}
+			for _, i := range pendingTargets {
+				if t.Journal != nil {
+					if entry, ok := t.Journal.Lookup(ctx, t.OciClient, journal.TargetKey(journal.ResourceKey(*cd, res), targets[i].name), resSourceDigest); ok && entry.Resource != nil {
+						log.V(3).Info("skip resource which has already been processed", "component", cd.Name, "version", cd.Version, "resource", res.Name, "target", targets[i].name)
+						processedResources[i][cd][j] = *entry.Resource
+						t.reportSkippedResource(ctx, targets[i], cd, *entry.Resource)
+						continue
+					}
+				}
+				resTargets = append(resTargets, i)
+			}
+			if len(resTargets) == 0 {
+				continue
+			}
+
+			var source *sharedSource
+			if len(resTargets) > 1 {
+				var err error
+				source, err = t.newSharedSource(*cd, res)
+				if err != nil {
+					return fmt.Errorf("unable to create processing pipeline for resource %s of component %s:%s: %w", res.Name, cd.Name, cd.Version, err)
+				}
+				sources = append(sources, source)
+			}
+
+			for _, i := range resTargets {
+				pipeline, err := t.createJobPipeline(*cd, res, targets[i], source)
+				if err != nil {
+					return fmt.Errorf("unable to create processing pipeline for resource %s of component %s:%s: %w", res.Name, cd.Name, cd.Version, err)
+				}
+				jobs = append(jobs, process.ProcessingJob{
+					ComponentDescriptor: cd,
+					Resource:            res,
+					Pipeline:            pipeline,
+				})
+				jobResources = append(jobResources, resourceIndex{target: i, cd: cd, index: j})
+			}
+		}
+	}
+
+	results, err := t.Executor.Execute(ctx, jobs)
+	if err != nil {
+		for i, result := range results {
+			if result.Error == nil {
+				continue
+			}
+			t.reportComponent(targets[jobResources[i].target], result.Job.ComponentDescriptor, func(c *report.ComponentReport) {
+				c.Status = report.StatusFailed
+				c.Error = "unable to process all resources"
+				c.Duration = report.Since(start)
+			})
+		}
+		return fmt.Errorf("unable to process resources: %w", err)
+	}
+
+	// results are returned in the order of the jobs, therefore the resource order is preserved
+	for i, result := range results {
+		idx := jobResources[i]
+		processedResources[idx.target][idx.cd][idx.index] = result.ProcessedResource
+	}
+
+	for i, target := range targets {
+		addedCDs := []*cdv2.ComponentDescriptor{}
+		for _, cd := range cds {
+			if uploadedCDs[i][cd] {
+				continue
+			}
+			processedCD := cd.DeepCopy()
+			processedCD.Resources = processedResources[i][cd]
+			var err error
+			if t.Signer != nil {
+				err = t.signComponentDescriptor(ctx, cd, processedCD)
+			}
+			if err == nil {
+				err = t.uploadComponentDescriptor(ctx, target, processedCD, sourceDigests[cd])
+			}
+			t.reportComponent(target, cd, func(c *report.ComponentReport) {
+				c.Status, c.Error = report.StatusFromError(err)
+				c.Duration = report.Since(start)
+			})
+			if err != nil {
+				return fmt.Errorf("unable to upload component descriptor %s:%s: %w", cd.Name, cd.Version, err)
+			}
+			addedCDs = append(addedCDs, cd)
+		}
+		if target.ctf != nil {
+			if err := t.writeCTF(target, addedCDs, sourceDigests); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createJobPipeline creates the pipeline of a resource for a target, which verifies the digest of the rewritten
+// resource and records the outcome in the journal and report. If a shared source is given, the resource is
+// downloaded and processed by the shared source instead of the pipeline.
+func (t *Transporter) createJobPipeline(cd cdv2.ComponentDescriptor, res cdv2.Resource, target transportTarget, source *sharedSource) (process.ResourceProcessingPipeline, error) {
+	bytes := new(int64)
+	pipeline, processorNames, err := t.createPipeline(cd, res, target, source, bytes)
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		pipeline = &sharedSourcePipeline{
+			source:   source,
+			pipeline: pipeline,
+		}
+	}
+	pipeline = &tracingPipeline{
+		pipeline: pipeline,
+		target:   target.name,
+	}
+	blobResolver, err := t.newTargetBlobResolver(target, cd)
+	if err != nil {
+		return nil, err
+	}
+	pipeline = &digestVerifyingPipeline{
+		pipeline:     pipeline,
+		client:       t.OciClient,
+		cache:        t.Cache,
+		blobResolver: blobResolver,
+		resign:       t.Signer != nil,
+	}
+	if t.Journal != nil {
+		pipeline = &journalingPipeline{
+			pipeline: pipeline,
+			journal:  t.Journal,
+			client:   t.OciClient,
+			target:   target.name,
+		}
+	}
+	if t.Report != nil {
+		pipeline = &reportingPipeline{
+			pipeline:   pipeline,
+			report:     t.Report,
+			client:     t.OciClient,
+			target:     target.name,
+			targetCtx:  target.repository(),
+			processors: processorNames,
+			bytes:      bytes,
+		}
+	}
+	return pipeline, nil
+}
+
+func (t *Transporter) reportComponent(target transportTarget, cd *cdv2.ComponentDescriptor, update func(c *report.ComponentReport)) {
+	if t.Report == nil {
+		return
+	}
+	t.Report.UpdateTargetComponent(target.name, cd.Name, cd.Version, update)
+}
+
+func (t *Transporter) reportSkippedResource(ctx context.Context, target transportTarget, cd *cdv2.ComponentDescriptor, res cdv2.Resource) {
+	if t.Report == nil {
+		return
+	}
+	resReport := report.ResourceReport{
+		Name:          res.Name,
+		Version:       res.Version,
+		ExtraIdentity: res.ExtraIdentity,
+		Status:        report.StatusSkipped,
+	}
+	resReport.TargetRef, resReport.TargetDigest = resourceLocation(ctx, t.OciClient, target.repository(), *cd, res)
+	t.Report.AddTargetResource(target.name, cd.Name, cd.Version, resReport)
+}
+
+func (t *Transporter) isRecorded(ctx context.Context, key, sourceDigest string) bool {
+	if t.Journal == nil {
+		return false
+	}
+	_, ok := t.Journal.Lookup(ctx, t.OciClient, key, sourceDigest)
+	return ok
+}
+
+// uploadComponentDescriptor uploads a processed component descriptor to a target.
+// The source digest is the digest of the source component descriptor which is recorded in the journal.
+func (t *Transporter) uploadComponentDescriptor(ctx context.Context, target transportTarget, cd *cdv2.ComponentDescriptor, sourceDigest string) error {
+	log := logr.FromContextOrDiscard(ctx).WithValues("component", cd.Name, "version", cd.Version)
+	journalKey := journal.TargetKey(journal.ComponentKey(cd.Name, cd.Version), target.name)
+
+	if target.repoCtx != nil {
+		if err := cdv2.InjectRepositoryContext(cd, target.repoCtx); err != nil {
+			return fmt.Errorf("unable to inject target repository: %w", err)
+		}
+	}
+
+	if target.ctf != nil {
+		// the component descriptor is recorded in the journal after the ctf has been written
+		if err := target.ctf.AddComponentDescriptor(cd); err != nil {
+			return fmt.Errorf("unable to add component archive to ctf %s: %w", target.ctf.Path(), err)
+		}
+		ref := target.ctf.ComponentArchiveRef(cd.Name, cd.Version)
+		log.V(3).Info("added component archive", "ref", ref)
+
+		t.reportComponent(target, cd, func(c *report.ComponentReport) {
+			c.TargetRef = ref
+		})
+		return nil
+	}
+
+	manifest, err := cdoci.NewManifestBuilder(t.Cache, ctf.NewComponentArchive(cd, nil)).Build(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to build oci artifact for component archive: %w", err)
+	}
+
+	ref, err := components.OCIRef(target.repository(), cd.Name, cd.Version)
+	if err != nil {
+		return fmt.Errorf("invalid component reference: %w", err)
+	}
+
+	log.V(3).Info("upload component descriptor", "ref", ref)
+	if err := t.OciClient.PushManifest(ctx, ref, manifest); err != nil {
+		return fmt.Errorf("unable to upload component descriptor to %s: %w", ref, err)
+	}
+
+	if t.Report != nil {
+		var targetDigest string
+		if desc, _, err := t.OciClient.GetRawManifest(ctx, ref); err == nil {
+			targetDigest = desc.Digest.String()
+		}
+		t.reportComponent(target, cd, func(c *report.ComponentReport) {
+			c.TargetRef = ref
+			c.TargetDigest = targetDigest
+		})
+	}
+
+	if t.Journal != nil {
+		entry := journal.Entry{
+			Key:          journalKey,
+			SourceDigest: sourceDigest,
+			TargetRef:    ref,
+		}
+		if err := t.Journal.RecordManifest(ctx, t.OciClient, entry); err != nil {
+			return fmt.Errorf("unable to record component descriptor in journal: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeCTF writes the component archives which have been added to the CTF of a target and records the
+// component descriptors with the digests of their sources in the journal.
+func (t *Transporter) writeCTF(target transportTarget, cds []*cdv2.ComponentDescriptor, sourceDigests map[*cdv2.ComponentDescriptor]string) error {
+	if len(cds) == 0 {
+		return nil
+	}
+	if err := target.ctf.Write(); err != nil {
+		for _, cd := range cds {
+			t.reportComponent(target, cd, func(c *report.ComponentReport) {
+				c.Status, c.Error = report.StatusFromError(err)
+			})
+		}
+		return err
+	}
+
+	if t.Journal == nil {
+		return nil
+	}
+	for _, cd := range cds {
+		entry := journal.Entry{
+			Key:          journal.TargetKey(journal.ComponentKey(cd.Name, cd.Version), target.name),
+			SourceDigest: sourceDigests[cd],
+			TargetRef:    target.ctf.ComponentArchiveRef(cd.Name, cd.Version),
+		}
+		if err := t.Journal.Record(entry); err != nil {
+			return fmt.Errorf("unable to record component descriptor in journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreatePipeline creates the processing pipeline for a resource. The pipeline consists of
+// exactly one matching downloader, the processors of all matching processing rules, and
+// all matching uploaders of the main target.
+func (t *Transporter) CreatePipeline(cd cdv2.ComponentDescriptor, res cdv2.Resource) (process.ResourceProcessingPipeline, error) {
+	pipeline, _, err := t.createPipeline(cd, res, t.targets()[0], nil, nil)
+	return pipeline, err
+}
+
+// createPipeline creates the processing pipeline for a resource and a target and returns the names of its processors in execution order.
+// If a shared source is given, the downloader and processors are replaced by the replay of the shared source.
+// If bytes is set, the bytes which are read by the first uploader are counted.
+func (t *Transporter) createPipeline(cd cdv2.ComponentDescriptor, res cdv2.Resource, target transportTarget, source *sharedSource, bytes *int64) (process.ResourceProcessingPipeline, []string, error) {
+	var (
+		processors []process.ResourceStreamProcessor
+		names      []string
+	)
+	if source != nil {
+		processors = []process.ResourceStreamProcessor{&replayingProcessor{source: source}}
+		names = append(names, source.names...)
+	} else {
+		var err error
+		processors, names, err = t.createSourceProcessors(cd, res)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	uploaders, uploaderNames, err := t.createUploaders(cd, res, target, bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	processors = append(processors, uploaders...)
+	names = append(names, uploaderNames...)
+
+	pipeline, err := t.newPipeline(processors)
+	return pipeline, names, err
+}
+
+// createSourceProcessors creates the matching downloader and the processors of all matching processing rules
+// and returns their names in execution order.
+func (t *Transporter) createSourceProcessors(cd cdv2.ComponentDescriptor, res cdv2.Resource) ([]process.ResourceStreamProcessor, []string, error) {
+	downloaderDefs := t.Config.MatchDownloaders(cd, res)
+	if len(downloaderDefs) == 0 {
+		return nil, nil, errors.New("no matching downloader found")
+	}
+	if len(downloaderDefs) > 1 {
+		return nil, nil, fmt.Errorf("%d matching downloaders found, but only 1 is allowed", len(downloaderDefs))
+	}
+
+	downloader, err := t.DownloaderFactory.Create(downloaderDefs[0].Type, downloaderDefs[0].Spec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create downloader %s: %w", downloaderDefs[0].Name, err)
+	}
+	processors := []process.ResourceStreamProcessor{
+		process.WithProcessorOptions(
+			instrumentProcessor(downloader, metrics.DownloaderKind, downloaderDefs[0].Name, downloaderDefs[0].Type),
+			processorOptions(downloaderDefs[0].Timeout, downloaderDefs[0].Retry),
+		),
+	}
+	names := []string{downloaderDefs[0].Name}
+
+	for _, rule := range t.Config.MatchProcessingRules(cd, res) {
+		for _, processorDef := range rule.Processors {
+			processor, err := t.ProcessorFactory.Create(processorDef.Type, processorDef.Spec)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to create processor %s of processing rule %s: %w", processorDef.Name, rule.Name, err)
+			}
+			processor = instrumentProcessor(processor, metrics.ProcessorKind, processorDef.Name, processorDef.Type)
+			processors = append(processors, process.WithProcessorOptions(processor, processorOptions(processorDef.Timeout, processorDef.Retry)))
+			names = append(names, processorDef.Name)
+		}
+	}
+
+	return processors, names, nil
+}
+
+// createUploaders creates all matching uploaders of a target and returns their names in execution order.
+// If bytes is set, the bytes which are read by the first uploader are counted.
+func (t *Transporter) createUploaders(cd cdv2.ComponentDescriptor, res cdv2.Resource, target transportTarget, bytes *int64) ([]process.ResourceStreamProcessor, []string, error) {
+	uploaderDefs := []config.ParsedUploaderDefinition{}
+	for _, uploaderDef := range t.Config.MatchUploaders(cd, res) {
+		if t.uploaderTarget(uploaderDef) == target.name {
+			uploaderDefs = append(uploaderDefs, uploaderDef)
+		}
+	}
+	if len(uploaderDefs) == 0 {
+		if len(target.name) != 0 {
+			return nil, nil, fmt.Errorf("no matching uploader found for target repository %s", target.name)
+		}
+		return nil, nil, errors.New("no matching uploader found")
+	}
+
+	factory := t.UploaderFactory
+	if len(target.name) != 0 {
+		factory = factory.WithTargetRepository(*target.repoCtx)
+	} else if target.ctf != nil {
+		factory = factory.WithTargetCTF(target.ctf)
+	}
+
+	processors := []process.ResourceStreamProcessor{}
+	names := []string{}
+	for i, uploaderDef := range uploaderDefs {
+		uploader, err := factory.Create(uploaderDef.Type, uploaderDef.Spec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create uploader %s: %w", uploaderDef.Name, err)
+		}
+		if i == 0 && bytes != nil {
+			uploader = &countingProcessor{
+				processor: uploader,
+				bytes:     bytes,
+			}
+		}
+		uploader = instrumentProcessor(uploader, metrics.UploaderKind, uploaderDef.Name, uploaderDef.Type)
+		processors = append(processors, process.WithProcessorOptions(uploader, processorOptions(uploaderDef.Timeout, uploaderDef.Retry)))
+		names = append(names, uploaderDef.Name)
+	}
+	return processors, names, nil
+}
+
+func (t *Transporter) newPipeline(processors []process.ResourceStreamProcessor) (process.ResourceProcessingPipeline, error) {
+	if t.Streaming {
+		return process.NewStreamingResourceProcessingPipeline(processors...)
+	}
+	return process.NewResourceProcessingPipeline(processors...), nil
+}
+
+func processorOptions(timeout *time.Duration, retry *config.ParsedRetryDefinition) process.ProcessorOptions {
+	opts := process.ProcessorOptions{}
+	if timeout != nil {
+		opts.Timeout = *timeout
+	}
+	if retry != nil {
+		opts.Retry = &process.RetryOptions{
+			MaxAttempts: retry.MaxAttempts,
+		}
+		if retry.InitialBackoff != nil {
+			opts.Retry.InitialBackoff = *retry.InitialBackoff
+		}
+		if retry.MaxBackoff != nil {
+			opts.Retry.MaxBackoff = *retry.MaxBackoff
+		}
+	}
+	return opts
+}
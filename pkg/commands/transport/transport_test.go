@@ -0,0 +1,1059 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	cdv2Sign "github.com/gardener/component-spec/bindings-go/apis/v2/signatures"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/mandelsoft/vfs/pkg/memoryfs"
+	"github.com/mandelsoft/vfs/pkg/osfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/credentials"
+	mock_ociclient "github.com/gardener/component-cli/ociclient/mock"
+	"github.com/gardener/component-cli/ociclient/test/faultyregistry"
+	"github.com/gardener/component-cli/pkg/commands/transport"
+	"github.com/gardener/component-cli/pkg/testutils"
+	"github.com/gardener/component-cli/pkg/transport/config"
+	"github.com/gardener/component-cli/pkg/transport/filters"
+	"github.com/gardener/component-cli/pkg/transport/journal"
+	"github.com/gardener/component-cli/pkg/transport/metrics"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/downloaders"
+	"github.com/gardener/component-cli/pkg/transport/process/processors"
+	"github.com/gardener/component-cli/pkg/transport/process/uploaders"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/transport/report"
+	"github.com/gardener/component-cli/pkg/transport/tracing"
+)
+
+var _ = Describe("Transport", func() {
+
+	Context("ResolveRecursive", func() {
+
+		var (
+			repoCtx  *cdv2.OCIRegistryRepository
+			resolver ctf.ComponentResolver
+		)
+
+		BeforeEach(func() {
+			repoCtx = cdv2.NewOCIRegistryRepository("example.com/source", "")
+			cds := []cdv2.ComponentDescriptor{
+				newComponentDescriptor(repoCtx, "example.com/a", "v0.1.0", "example.com/b", "example.com/c"),
+				newComponentDescriptor(repoCtx, "example.com/b", "v0.1.0", "example.com/c"),
+				newComponentDescriptor(repoCtx, "example.com/c", "v0.1.0"),
+			}
+			var err error
+			resolver, err = ctf.NewListResolver(&cdv2.ComponentDescriptorList{Components: cds})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should resolve all referenced components exactly once", func() {
+			cds, err := transport.ResolveRecursive(context.TODO(), resolver, repoCtx, "example.com/a", "v0.1.0", true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cds).To(HaveLen(3))
+			Expect(cds[0].Name).To(Equal("example.com/a"))
+			Expect(cds[1].Name).To(Equal("example.com/b"))
+			Expect(cds[2].Name).To(Equal("example.com/c"))
+		})
+
+		It("should only resolve the root component if recursive is disabled", func() {
+			cds, err := transport.ResolveRecursive(context.TODO(), resolver, repoCtx, "example.com/a", "v0.1.0", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cds).To(HaveLen(1))
+			Expect(cds[0].Name).To(Equal("example.com/a"))
+		})
+
+		It("should return an error if a referenced component cannot be resolved", func() {
+			_, err := transport.ResolveRecursive(context.TODO(), resolver, repoCtx, "example.com/unknown", "v0.1.0", true)
+			Expect(err).To(HaveOccurred())
+		})
+
+	})
+
+	Context("CreatePipeline", func() {
+
+		var (
+			mockCtrl    *gomock.Controller
+			transporter transport.Transporter
+		)
+
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient := mock_ociclient.NewMockClient(mockCtrl)
+			ociCache := cache.NewInMemoryCache()
+			targetCtx := cdv2.NewOCIRegistryRepository("example.com/target", "")
+
+			transportCfg, err := config.ParseTransportConfig("./testdata/transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			transporter = transport.Transporter{
+				Config:            transportCfg,
+				DownloaderFactory: downloaders.NewDownloaderFactory(mockClient, ociCache),
+				ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+				UploaderFactory:   uploaders.NewUploaderFactory(mockClient, ociCache, *targetCtx),
+				OciClient:         mockClient,
+				Cache:             ociCache,
+				TargetRepoCtx:     targetCtx,
+			}
+		})
+
+		AfterEach(func() {
+			mockCtrl.Finish()
+		})
+
+		It("should create a pipeline for a resource with matching downloader and uploader", func() {
+			acc, err := cdv2.NewUnstructured(cdv2.NewOCIRegistryAccess("example.com/image:0.1.0"))
+			Expect(err).ToNot(HaveOccurred())
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "image",
+					Version: "0.1.0",
+					Type:    cdv2.OCIImageType,
+				},
+				Access: &acc,
+			}
+
+			pipeline, err := transporter.CreatePipeline(cdv2.ComponentDescriptor{}, res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pipeline).ToNot(BeNil())
+		})
+
+		It("should return an error if no downloader matches", func() {
+			acc, err := cdv2.NewUnstructured(cdv2.NewWebAccess("https://example.com/file"))
+			Expect(err).ToNot(HaveOccurred())
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "file",
+					Version: "0.1.0",
+					Type:    "plain-text",
+				},
+				Access: &acc,
+			}
+
+			_, err = transporter.CreatePipeline(cdv2.ComponentDescriptor{}, res)
+			Expect(err).To(MatchError("no matching downloader found"))
+		})
+
+	})
+
+	Context("CTF", func() {
+
+		It("should write component archives to a ctf", func() {
+			fs := memoryfs.New()
+			executor, err := process.NewExecutor(1)
+			Expect(err).ToNot(HaveOccurred())
+			targetCTF, err := processutils.NewCTFWriter(fs, "/ctf.tar")
+			Expect(err).ToNot(HaveOccurred())
+			transporter := transport.Transporter{
+				Executor:  executor,
+				TargetCTF: targetCTF,
+			}
+
+			cd := newComponentDescriptor(cdv2.NewOCIRegistryRepository("example.com/source", ""), "example.com/a", "v0.1.0")
+			cd.Metadata.Version = cdv2.SchemaVersion
+			cd.Provider = cdv2.InternalProvider
+			Expect(cdv2.DefaultComponent(&cd)).To(Succeed())
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+			Expect(targetCTF.Close()).To(Succeed())
+			_, err = fs.Stat("/ctf.tar.staging")
+			Expect(os.IsNotExist(err)).To(BeTrue(), "the staging directory should be removed")
+
+			archive, err := ctf.NewCTF(fs, "/ctf.tar")
+			Expect(err).ToNot(HaveOccurred())
+			defer archive.Close()
+			actualCDs := []*cdv2.ComponentDescriptor{}
+			Expect(archive.Walk(func(ca *ctf.ComponentArchive) error {
+				actualCDs = append(actualCDs, ca.ComponentDescriptor)
+				return nil
+			})).To(Succeed())
+			Expect(actualCDs).To(HaveLen(1))
+			Expect(actualCDs[0].Name).To(Equal("example.com/a"))
+			Expect(actualCDs[0].Version).To(Equal("v0.1.0"))
+			Expect(actualCDs[0].RepositoryContexts).To(HaveLen(1))
+		})
+
+		It("should read component descriptors and local blobs from a ctf", func() {
+			fs := memoryfs.New()
+			cd := newComponentDescriptor(cdv2.NewOCIRegistryRepository("example.com/source", ""), "example.com/a", "v0.1.0")
+			cd.Metadata.Version = cdv2.SchemaVersion
+			cd.Provider = cdv2.InternalProvider
+			Expect(cdv2.DefaultComponent(&cd)).To(Succeed())
+			ca := ctf.NewComponentArchive(&cd, memoryfs.New())
+			fileData := []byte("file content")
+			Expect(ca.AddResource(&cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "file",
+					Version: "v0.1.0",
+					Type:    "plain-text",
+				},
+				Relation: cdv2.LocalRelation,
+			}, ctf.BlobInfo{
+				MediaType: "text/plain",
+				Digest:    digest.FromBytes(fileData).String(),
+				Size:      int64(len(fileData)),
+			}, bytes.NewReader(fileData))).To(Succeed())
+
+			// the ctf is created like "component-cli ctf add" does
+			targetCTF, err := processutils.NewCTFWriter(fs, "/source.tar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targetCTF.Close()).To(Succeed())
+			archive, err := ctf.NewCTF(fs, "/source.tar")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(archive.AddComponentArchive(ca, ctf.ArchiveFormatTar)).To(Succeed())
+			Expect(archive.Write()).To(Succeed())
+			Expect(archive.Close()).To(Succeed())
+
+			sourceCTF, err := processutils.ReadCTF(fs, "/source.tar")
+			Expect(err).ToNot(HaveOccurred())
+			defer sourceCTF.Close()
+			transportCfg, err := config.ParseTransportConfig("./testdata/ctf-transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			executor, err := process.NewExecutor(1)
+			Expect(err).ToNot(HaveOccurred())
+			targetCTF, err = processutils.NewCTFWriter(fs, "/target.tar")
+			Expect(err).ToNot(HaveOccurred())
+			ociCache := cache.NewInMemoryCache()
+			transporter := transport.Transporter{
+				Config:            transportCfg,
+				Executor:          executor,
+				DownloaderFactory: downloaders.NewDownloaderFactory(nil, ociCache).WithSourceCTF(sourceCTF),
+				ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+				UploaderFactory:   uploaders.NewUploaderFactory(nil, ociCache, cdv2.OCIRegistryRepository{}),
+				Cache:             ociCache,
+				TargetCTF:         targetCTF,
+			}
+
+			sourceCA, err := sourceCTF.ComponentArchive(cd.Name, cd.Version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(transporter.Transport(context.TODO(), sourceCA.ComponentDescriptor)).To(Succeed())
+			Expect(targetCTF.Close()).To(Succeed())
+
+			actualCA, err := readCTFComponentArchive(fs, "/target.tar", cd.Name, cd.Version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualCA.ComponentDescriptor.Resources[0].Access.Type).To(Equal(cdv2.LocalFilesystemBlobType))
+			blob := bytes.NewBuffer([]byte{})
+			_, err = actualCA.BlobResolver.Resolve(context.TODO(), actualCA.ComponentDescriptor.Resources[0], blob)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blob.Bytes()).To(Equal(fileData))
+		})
+
+	})
+
+	Context("Signatures", func() {
+
+		var (
+			dir         string
+			server      *httptest.Server
+			targetCTF   *processutils.CTFWriter
+			transporter transport.Transporter
+			cd          cdv2.ComponentDescriptor
+			fileData    = []byte("file content")
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "signatures-")
+			Expect(err).ToNot(HaveOccurred())
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(fileData)
+			}))
+
+			// resources are downloaded via http and rewritten to local oci blobs in a ctf directory
+			cfgPath := filepath.Join(dir, "transport-config.yaml")
+			Expect(os.WriteFile(cfgPath, []byte(`
+meta:
+  version: v1
+downloaders:
+- name: 'web-downloader'
+  type: 'WebDownloader'
+  filters:
+  - type: 'AccessTypeFilter'
+    spec:
+      includeAccessTypes:
+      - 'web'
+uploaders:
+- name: 'ctf-uploader'
+  type: 'CtfUploader'
+  filters:
+  - type: 'AccessTypeFilter'
+    spec:
+      includeAccessTypes:
+      - 'web'
+`), 0644)).To(Succeed())
+
+			transportCfg, err := config.ParseTransportConfig(cfgPath)
+			Expect(err).ToNot(HaveOccurred())
+			executor, err := process.NewExecutor(1)
+			Expect(err).ToNot(HaveOccurred())
+			ociCache := cache.NewInMemoryCache()
+			targetCTF, err = processutils.NewCTFWriter(osfs.New(), filepath.Join(dir, "ctf.tar"))
+			Expect(err).ToNot(HaveOccurred())
+
+			transporter = transport.Transporter{
+				Config:            transportCfg,
+				Executor:          executor,
+				DownloaderFactory: downloaders.NewDownloaderFactory(nil, ociCache),
+				ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+				UploaderFactory:   uploaders.NewUploaderFactory(nil, ociCache, cdv2.OCIRegistryRepository{}),
+				Cache:             ociCache,
+				TargetCTF:         targetCTF,
+			}
+
+			acc, err := cdv2.NewUnstructured(&cdv2.Web{
+				ObjectType: cdv2.ObjectType{Type: cdv2.WebType},
+				URL:        server.URL + "/file",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			cd = newComponentDescriptor(cdv2.NewOCIRegistryRepository("example.com/source", ""), "example.com/a", "v0.1.0")
+			cd.Metadata.Version = cdv2.SchemaVersion
+			cd.Provider = cdv2.InternalProvider
+			cd.Resources = []cdv2.Resource{
+				{
+					IdentityObjectMeta: cdv2.IdentityObjectMeta{
+						Name:    "file",
+						Version: "v0.1.0",
+						Type:    "plain-text",
+					},
+					Relation: cdv2.LocalRelation,
+					Access:   &acc,
+					Digest: &cdv2.DigestSpec{
+						HashAlgorithm:          cdv2Sign.SHA256,
+						NormalisationAlgorithm: string(cdv2.GenericBlobDigestV1),
+						Value:                  digest.FromBytes(fileData).Encoded(),
+					},
+				},
+			}
+			cd.Signatures = []cdv2.Signature{
+				{
+					Name: "source",
+					Digest: cdv2.DigestSpec{
+						HashAlgorithm:          cdv2Sign.SHA256,
+						NormalisationAlgorithm: string(cdv2.JsonNormalisationV1),
+						Value:                  "abc",
+					},
+					Signature: cdv2.SignatureSpec{
+						Algorithm: "RSASSA-PKCS1-V1_5-SIGN",
+						Value:     "def",
+					},
+				},
+			}
+			Expect(cdv2.DefaultComponent(&cd)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(targetCTF.Close()).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should verify the digest of a rewritten resource and keep the signatures", func() {
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+
+			actualCA, err := readCTFComponentArchive(osfs.New(), filepath.Join(dir, "ctf.tar"), cd.Name, cd.Version)
+			Expect(err).ToNot(HaveOccurred())
+			actualCD := actualCA.ComponentDescriptor
+			Expect(actualCD.Resources[0].Access.Type).To(Equal(cdv2.LocalFilesystemBlobType))
+			Expect(actualCD.Resources[0].Digest).To(Equal(cd.Resources[0].Digest))
+			Expect(actualCD.Signatures).To(Equal(cd.Signatures))
+		})
+
+		It("should fail if the digest of a rewritten resource doesn't match", func() {
+			cd.Resources[0].Digest.Value = digest.FromString("other content").Encoded()
+
+			err := transporter.Transport(context.TODO(), &cd)
+			Expect(err).To(MatchError(ContainSubstring("does not match")))
+
+			_, err = readCTFComponentArchive(osfs.New(), filepath.Join(dir, "ctf.tar"), cd.Name, cd.Version)
+			Expect(err).To(MatchError(ctf.NotFoundError))
+		})
+
+		It("should re-sign the component descriptor with the signer", func() {
+			privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+			privateKeyPath := filepath.Join(dir, "private.key")
+			Expect(os.WriteFile(privateKeyPath, pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PRIVATE KEY",
+				Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+			}), 0600)).To(Succeed())
+			publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+			Expect(err).ToNot(HaveOccurred())
+			publicKeyPath := filepath.Join(dir, "public.key")
+			Expect(os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{
+				Type:  "PUBLIC KEY",
+				Bytes: publicKey,
+			}), 0600)).To(Succeed())
+
+			signer, err := cdv2Sign.CreateRsaSignerFromKeyFile(privateKeyPath)
+			Expect(err).ToNot(HaveOccurred())
+			transporter.Signer = signer
+			transporter.SignatureName = "source"
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+
+			actualCA, err := readCTFComponentArchive(osfs.New(), filepath.Join(dir, "ctf.tar"), cd.Name, cd.Version)
+			Expect(err).ToNot(HaveOccurred())
+			actualCD := actualCA.ComponentDescriptor
+			Expect(actualCD.Signatures).To(HaveLen(1))
+			verifier, err := cdv2Sign.CreateRsaVerifierFromKeyFile(publicKeyPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cdv2Sign.VerifySignedComponentDescriptor(actualCD, verifier, "source")).To(Succeed())
+		})
+
+		It("should trace the processors and record their metrics", func() {
+			downloaded := testutil.ToFloat64(metrics.DownloadedBytes.WithLabelValues(downloaders.WebDownloaderType))
+			uploaded := testutil.ToFloat64(metrics.UploadedBytes.WithLabelValues(uploaders.CTFUploaderType))
+			processed := testutil.ToFloat64(metrics.ResourcesProcessed.WithLabelValues(metrics.UploaderKind, uploaders.CTFUploaderType))
+
+			exporter := &recordingExporter{}
+			ctx := tracing.ContextWithExporter(context.TODO(), exporter)
+			Expect(transporter.Transport(ctx, &cd)).To(Succeed())
+
+			Expect(testutil.ToFloat64(metrics.DownloadedBytes.WithLabelValues(downloaders.WebDownloaderType))).To(BeNumerically(">", downloaded))
+			Expect(testutil.ToFloat64(metrics.UploadedBytes.WithLabelValues(uploaders.CTFUploaderType))).To(BeNumerically(">", uploaded))
+			Expect(testutil.ToFloat64(metrics.ResourcesProcessed.WithLabelValues(metrics.UploaderKind, uploaders.CTFUploaderType))).To(Equal(processed + 1))
+
+			Expect(exporter.spans).To(HaveLen(3))
+			pipelineSpan := exporter.spans[2]
+			Expect(pipelineSpan.Name).To(Equal("pipeline"))
+			Expect(exporter.spans[0].Name).To(Equal(metrics.DownloaderKind))
+			Expect(exporter.spans[1].Name).To(Equal(metrics.UploaderKind))
+			for _, span := range exporter.spans[:2] {
+				Expect(span.TraceID).To(Equal(pipelineSpan.TraceID))
+				Expect(span.ParentID).To(Equal(pipelineSpan.SpanID))
+				Expect(span.Error).ToNot(HaveOccurred())
+			}
+		})
+
+	})
+
+	Context("Changed digests", func() {
+
+		var (
+			dir         string
+			srcRegistry *faultyregistry.Registry
+			tgtRegistry *faultyregistry.Registry
+			targetCTF   *processutils.CTFWriter
+			transporter transport.Transporter
+			cd          cdv2.ComponentDescriptor
+		)
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			defer ctx.Done()
+			var err error
+			dir, err = os.MkdirTemp("", "digests-")
+			Expect(err).ToNot(HaveOccurred())
+			srcRegistry = faultyregistry.New()
+			tgtRegistry = faultyregistry.New()
+
+			ociCache := cache.NewInMemoryCache()
+			ociClient, err := ociclient.NewClient(logr.Discard(),
+				ociclient.WithKeyring(credentials.New()),
+				ociclient.WithCache(ociCache),
+				ociclient.AllowPlainHttp(true))
+			Expect(err).ToNot(HaveOccurred())
+			ref := fmt.Sprintf("%s/image:v0.1.0", srcRegistry.Addr)
+			desc, _ := testutils.UploadTestImage(ctx, ociClient, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+			// the oci artifact filter removes the layer, which changes the digest of the resource
+			cfgPath := filepath.Join(dir, "transport-config.yaml")
+			Expect(os.WriteFile(cfgPath, []byte(fmt.Sprintf(`
+meta:
+  version: v1
+downloaders:
+- name: 'oci-downloader'
+  type: 'OciArtifactDownloader'
+processors:
+- name: 'oci-filter'
+  type: 'OciArtifactFilter'
+  spec:
+    removeLayerMediaTypes:
+    - 'text/plain'
+uploaders:
+- name: 'oci-uploader'
+  type: 'OciArtifactUploader'
+  spec:
+    baseUrl: '%s/target'
+processingRules:
+- name: 'filter-images'
+  processors:
+  - name: 'oci-filter'
+    type: 'processor'
+`, tgtRegistry.Addr)), 0644)).To(Succeed())
+
+			transportCfg, err := config.ParseTransportConfig(cfgPath)
+			Expect(err).ToNot(HaveOccurred())
+			executor, err := process.NewExecutor(1)
+			Expect(err).ToNot(HaveOccurred())
+			targetCTF, err = processutils.NewCTFWriter(osfs.New(), filepath.Join(dir, "ctf.tar"))
+			Expect(err).ToNot(HaveOccurred())
+
+			transporter = transport.Transporter{
+				Config:            transportCfg,
+				Executor:          executor,
+				DownloaderFactory: downloaders.NewDownloaderFactory(ociClient, ociCache),
+				ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+				UploaderFactory:   uploaders.NewUploaderFactory(ociClient, ociCache, cdv2.OCIRegistryRepository{}),
+				OciClient:         ociClient,
+				Cache:             ociCache,
+				TargetCTF:         targetCTF,
+			}
+
+			acc, err := cdv2.NewUnstructured(cdv2.NewOCIRegistryAccess(ref))
+			Expect(err).ToNot(HaveOccurred())
+			cd = newComponentDescriptor(cdv2.NewOCIRegistryRepository("example.com/source", ""), "example.com/a", "v0.1.0")
+			cd.Metadata.Version = cdv2.SchemaVersion
+			cd.Provider = cdv2.InternalProvider
+			cd.Resources = []cdv2.Resource{
+				{
+					IdentityObjectMeta: cdv2.IdentityObjectMeta{
+						Name:    "image",
+						Version: "v0.1.0",
+						Type:    cdv2.OCIImageType,
+					},
+					Relation: cdv2.ExternalRelation,
+					Access:   &acc,
+					Digest: &cdv2.DigestSpec{
+						HashAlgorithm:          cdv2Sign.SHA256,
+						NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+						Value:                  desc.Digest.Encoded(),
+					},
+				},
+			}
+			cd.Signatures = []cdv2.Signature{
+				{
+					Name: "other",
+					Digest: cdv2.DigestSpec{
+						HashAlgorithm:          cdv2Sign.SHA256,
+						NormalisationAlgorithm: string(cdv2.JsonNormalisationV1),
+						Value:                  "abc",
+					},
+					Signature: cdv2.SignatureSpec{
+						Algorithm: "RSASSA-PKCS1-V1_5-SIGN",
+						Value:     "def",
+					},
+				},
+			}
+			Expect(cdv2.DefaultComponent(&cd)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			srcRegistry.Close()
+			tgtRegistry.Close()
+			Expect(targetCTF.Close()).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should fail if a processor changes the digest of a resource and no signer is configured", func() {
+			err := transporter.Transport(context.TODO(), &cd)
+			Expect(err).To(MatchError(ContainSubstring("has been changed by a processor")))
+
+			_, err = readCTFComponentArchive(osfs.New(), filepath.Join(dir, "ctf.tar"), cd.Name, cd.Version)
+			Expect(err).To(MatchError(ctf.NotFoundError))
+		})
+
+		It("should drop the signatures of other signers if a processor changes the digest of a resource", func() {
+			privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+			privateKeyPath := filepath.Join(dir, "private.key")
+			Expect(os.WriteFile(privateKeyPath, pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PRIVATE KEY",
+				Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+			}), 0600)).To(Succeed())
+			signer, err := cdv2Sign.CreateRsaSignerFromKeyFile(privateKeyPath)
+			Expect(err).ToNot(HaveOccurred())
+			transporter.Signer = signer
+			transporter.SignatureName = "transport"
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+
+			actualCA, err := readCTFComponentArchive(osfs.New(), filepath.Join(dir, "ctf.tar"), cd.Name, cd.Version)
+			Expect(err).ToNot(HaveOccurred())
+			actualCD := actualCA.ComponentDescriptor
+			Expect(actualCD.Resources[0].Digest).ToNot(Equal(cd.Resources[0].Digest))
+			Expect(actualCD.Signatures).To(HaveLen(1))
+			Expect(actualCD.Signatures[0].Name).To(Equal("transport"))
+		})
+
+	})
+
+	Context("Serialized oci artifacts", func() {
+
+		var (
+			dir         string
+			registry    *faultyregistry.Registry
+			targetCTF   *processutils.CTFWriter
+			transporter transport.Transporter
+			cd          cdv2.ComponentDescriptor
+		)
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			defer ctx.Done()
+			var err error
+			dir, err = os.MkdirTemp("", "serialized-")
+			Expect(err).ToNot(HaveOccurred())
+			registry = faultyregistry.New()
+
+			ociCache := cache.NewInMemoryCache()
+			ociClient, err := ociclient.NewClient(logr.Discard(),
+				ociclient.WithKeyring(credentials.New()),
+				ociclient.WithCache(ociCache),
+				ociclient.AllowPlainHttp(true))
+			Expect(err).ToNot(HaveOccurred())
+			ref := fmt.Sprintf("%s/image:v0.1.0", registry.Addr)
+			desc, _ := testutils.UploadTestImage(ctx, ociClient, ref, ocispecv1.MediaTypeImageManifest, []byte("config"), [][]byte{[]byte("layer")})
+
+			// oci artifacts are serialized into local blobs of the ctf
+			cfgPath := filepath.Join(dir, "transport-config.yaml")
+			Expect(os.WriteFile(cfgPath, []byte(`
+meta:
+  version: v1
+downloaders:
+- name: 'oci-downloader'
+  type: 'OciArtifactDownloader'
+uploaders:
+- name: 'ctf-uploader'
+  type: 'CtfUploader'
+`), 0644)).To(Succeed())
+
+			transportCfg, err := config.ParseTransportConfig(cfgPath)
+			Expect(err).ToNot(HaveOccurred())
+			executor, err := process.NewExecutor(1)
+			Expect(err).ToNot(HaveOccurred())
+			targetCTF, err = processutils.NewCTFWriter(osfs.New(), filepath.Join(dir, "ctf.tar"))
+			Expect(err).ToNot(HaveOccurred())
+
+			transporter = transport.Transporter{
+				Config:            transportCfg,
+				Executor:          executor,
+				DownloaderFactory: downloaders.NewDownloaderFactory(ociClient, ociCache),
+				ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+				UploaderFactory:   uploaders.NewUploaderFactory(ociClient, ociCache, cdv2.OCIRegistryRepository{}),
+				OciClient:         ociClient,
+				Cache:             ociCache,
+				TargetCTF:         targetCTF,
+			}
+
+			acc, err := cdv2.NewUnstructured(cdv2.NewOCIRegistryAccess(ref))
+			Expect(err).ToNot(HaveOccurred())
+			cd = newComponentDescriptor(cdv2.NewOCIRegistryRepository("example.com/source", ""), "example.com/a", "v0.1.0")
+			cd.Metadata.Version = cdv2.SchemaVersion
+			cd.Provider = cdv2.InternalProvider
+			cd.Resources = []cdv2.Resource{
+				{
+					IdentityObjectMeta: cdv2.IdentityObjectMeta{
+						Name:    "image",
+						Version: "v0.1.0",
+						Type:    cdv2.OCIImageType,
+					},
+					Relation: cdv2.ExternalRelation,
+					Access:   &acc,
+					Digest: &cdv2.DigestSpec{
+						HashAlgorithm:          cdv2Sign.SHA256,
+						NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+						Value:                  desc.Digest.Encoded(),
+					},
+				},
+			}
+			Expect(cdv2.DefaultComponent(&cd)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			registry.Close()
+			Expect(targetCTF.Close()).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should verify the manifest digest of an oci artifact which is written as local blob", func() {
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+
+			actualCA, err := readCTFComponentArchive(osfs.New(), filepath.Join(dir, "ctf.tar"), cd.Name, cd.Version)
+			Expect(err).ToNot(HaveOccurred())
+			actualCD := actualCA.ComponentDescriptor
+			Expect(actualCD.Resources[0].Access.Type).To(Equal(cdv2.LocalFilesystemBlobType))
+			Expect(actualCD.Resources[0].Digest).To(Equal(cd.Resources[0].Digest))
+		})
+
+		It("should fail if the manifest digest of an oci artifact which is written as local blob doesn't match", func() {
+			cd.Resources[0].Digest.Value = digest.FromString("other manifest").Encoded()
+
+			err := transporter.Transport(context.TODO(), &cd)
+			Expect(err).To(MatchError(ContainSubstring("does not match")))
+
+			_, err = readCTFComponentArchive(osfs.New(), filepath.Join(dir, "ctf.tar"), cd.Name, cd.Version)
+			Expect(err).To(MatchError(ctf.NotFoundError))
+		})
+
+	})
+
+	Context("Multiple targets", func() {
+
+		var (
+			mockCtrl    *gomock.Controller
+			mockClient  *mock_ociclient.MockClient
+			dir         string
+			server      *httptest.Server
+			downloads   int32
+			targetCTF   *processutils.CTFWriter
+			transporter transport.Transporter
+			cd          cdv2.ComponentDescriptor
+		)
+
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = mock_ociclient.NewMockClient(mockCtrl)
+
+			var err error
+			dir, err = os.MkdirTemp("", "targets-")
+			Expect(err).ToNot(HaveOccurred())
+
+			downloads = 0
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&downloads, 1)
+				_, _ = w.Write([]byte("file content"))
+			}))
+
+			// the resource is written to the main ctf and uploaded as local oci blob to the additional target repository
+			cfgPath := filepath.Join(dir, "transport-config.yaml")
+			Expect(os.WriteFile(cfgPath, []byte(`
+meta:
+  version: v1
+downloaders:
+- name: 'web-downloader'
+  type: 'WebDownloader'
+uploaders:
+- name: 'main-uploader'
+  type: 'CtfUploader'
+- name: 'us-uploader'
+  type: 'LocalOciBlobUploader'
+  targetRepository: 'example.com/us'
+`), 0644)).To(Succeed())
+
+			transportCfg, err := config.ParseTransportConfig(cfgPath)
+			Expect(err).ToNot(HaveOccurred())
+			executor, err := process.NewExecutor(2)
+			Expect(err).ToNot(HaveOccurred())
+			ociCache := cache.NewInMemoryCache()
+			targetCTF, err = processutils.NewCTFWriter(osfs.New(), filepath.Join(dir, "main.tar"))
+			Expect(err).ToNot(HaveOccurred())
+
+			transporter = transport.Transporter{
+				Config:            transportCfg,
+				Executor:          executor,
+				DownloaderFactory: downloaders.NewDownloaderFactory(mockClient, ociCache),
+				ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+				UploaderFactory:   uploaders.NewUploaderFactory(mockClient, ociCache, cdv2.OCIRegistryRepository{}),
+				OciClient:         mockClient,
+				Cache:             ociCache,
+				TargetCTF:         targetCTF,
+				Report:            report.New(),
+			}
+
+			acc, err := cdv2.NewUnstructured(cdv2.NewWebAccess(server.URL + "/file"))
+			Expect(err).ToNot(HaveOccurred())
+			cd = newComponentDescriptor(cdv2.NewOCIRegistryRepository("example.com/source", ""), "example.com/a", "v0.1.0")
+			cd.Metadata.Version = cdv2.SchemaVersion
+			cd.Provider = cdv2.InternalProvider
+			cd.Resources = []cdv2.Resource{
+				{
+					IdentityObjectMeta: cdv2.IdentityObjectMeta{
+						Name:    "file",
+						Version: "v0.1.0",
+						Type:    "plain-text",
+					},
+					Relation: cdv2.LocalRelation,
+					Access:   &acc,
+				},
+			}
+			Expect(cdv2.DefaultComponent(&cd)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			mockCtrl.Finish()
+			server.Close()
+			Expect(targetCTF.Close()).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should download a resource once and upload one component descriptor per target", func() {
+			usRef := "example.com/us/component-descriptors/example.com/a:v0.1.0"
+			mockClient.EXPECT().PushBlob(gomock.Any(), usRef, gomock.Any(), gomock.Any()).Return(nil)
+			mockClient.EXPECT().PushManifest(gomock.Any(), usRef, gomock.Any()).Return(nil)
+			mockClient.EXPECT().GetRawManifest(gomock.Any(), usRef).Return(ocispecv1.Descriptor{}, nil, nil)
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+			Expect(atomic.LoadInt32(&downloads)).To(Equal(int32(1)))
+
+			mainCA, err := readCTFComponentArchive(osfs.New(), filepath.Join(dir, "main.tar"), cd.Name, cd.Version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mainCA.ComponentDescriptor.Resources[0].Access.Type).To(Equal(cdv2.LocalFilesystemBlobType))
+
+			Expect(transporter.Report.Components).To(HaveLen(2))
+			Expect(transporter.Report.Components[0].Target).To(BeEmpty())
+			Expect(transporter.Report.Components[1].Target).To(Equal("example.com/us"))
+			Expect(transporter.Report.Components[1].TargetRef).To(Equal(usRef))
+			for i, uploader := range []string{"main-uploader", "us-uploader"} {
+				c := transporter.Report.Components[i]
+				Expect(c.Status).To(Equal(report.StatusSucceeded))
+				Expect(c.Resources).To(HaveLen(1))
+				Expect(c.Resources[0].Processors).To(Equal([]string{"web-downloader", uploader}))
+			}
+		})
+
+		It("should fail if a resource matches no uploader of a target", func() {
+			filter, err := filters.NewResourceTypeFilter(filters.ResourceTypeFilterSpec{
+				IncludeResourceTypes: []string{"other"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			transporter.Config.Uploaders[1].Filters = []filters.Filter{filter}
+
+			err = transporter.Transport(context.TODO(), &cd)
+			Expect(err).To(MatchError(ContainSubstring("no matching uploader found for target repository example.com/us")))
+		})
+
+	})
+
+	Context("Journal", func() {
+
+		var (
+			mockCtrl    *gomock.Controller
+			mockClient  *mock_ociclient.MockClient
+			dir         string
+			j           *journal.Journal
+			transporter transport.Transporter
+			cd          cdv2.ComponentDescriptor
+			cdRef       string
+		)
+
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = mock_ociclient.NewMockClient(mockCtrl)
+			ociCache := cache.NewInMemoryCache()
+			targetCtx := cdv2.NewOCIRegistryRepository("example.com/target", "")
+
+			transportCfg, err := config.ParseTransportConfig("./testdata/transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			executor, err := process.NewExecutor(1)
+			Expect(err).ToNot(HaveOccurred())
+
+			dir, err = os.MkdirTemp("", "journal-")
+			Expect(err).ToNot(HaveOccurred())
+			j, err = journal.Open(filepath.Join(dir, "journal.jsonl"), false)
+			Expect(err).ToNot(HaveOccurred())
+
+			transporter = transport.Transporter{
+				Config:            transportCfg,
+				Executor:          executor,
+				DownloaderFactory: downloaders.NewDownloaderFactory(mockClient, ociCache),
+				ProcessorFactory:  processors.NewProcessorFactory(ociCache),
+				UploaderFactory:   uploaders.NewUploaderFactory(mockClient, ociCache, *targetCtx),
+				OciClient:         mockClient,
+				Cache:             ociCache,
+				TargetRepoCtx:     targetCtx,
+				Journal:           j,
+			}
+
+			// the resource has no matching downloader, so the transport fails if the resource isn't skipped
+			acc, err := cdv2.NewUnstructured(cdv2.NewWebAccess("https://example.com/file"))
+			Expect(err).ToNot(HaveOccurred())
+			cd = newComponentDescriptor(cdv2.NewOCIRegistryRepository("example.com/source", ""), "example.com/a", "v0.1.0")
+			cd.Resources = []cdv2.Resource{
+				{
+					IdentityObjectMeta: cdv2.IdentityObjectMeta{
+						Name:    "file",
+						Version: "0.1.0",
+						Type:    "plain-text",
+					},
+					Access: &acc,
+				},
+			}
+			cdRef = "example.com/target/component-descriptors/example.com/a:v0.1.0"
+		})
+
+		AfterEach(func() {
+			mockCtrl.Finish()
+			Expect(j.Close()).To(Succeed())
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("should skip component descriptors which are recorded with an unchanged digest", func() {
+			sourceDigest, err := journal.ComponentDescriptorDigest(&cd)
+			Expect(err).ToNot(HaveOccurred())
+			cdDigest := digest.FromString("cd")
+			Expect(j.Record(journal.Entry{
+				Key:          journal.ComponentKey(cd.Name, cd.Version),
+				SourceDigest: sourceDigest,
+				TargetRef:    cdRef,
+				TargetDigest: cdDigest.String(),
+			})).To(Succeed())
+			mockClient.EXPECT().GetRawManifest(gomock.Any(), cdRef).Return(ocispecv1.Descriptor{Digest: cdDigest}, nil, nil)
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+		})
+
+		It("should transport component descriptors again whose source has changed", func() {
+			Expect(j.Record(journal.Entry{
+				Key:          journal.ComponentKey(cd.Name, cd.Version),
+				SourceDigest: digest.FromString("changed").String(),
+				TargetRef:    cdRef,
+				TargetDigest: digest.FromString("cd").String(),
+			})).To(Succeed())
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(MatchError(ContainSubstring("no matching downloader found")))
+		})
+
+		It("should process resources again whose source has changed", func() {
+			Expect(j.Record(journal.Entry{
+				Key:          journal.ResourceKey(cd, cd.Resources[0]),
+				SourceDigest: digest.FromString("changed").String(),
+				Resource:     &cd.Resources[0],
+			})).To(Succeed())
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(MatchError(ContainSubstring("no matching downloader found")))
+		})
+
+		It("should skip recorded resources and record the uploaded component descriptor", func() {
+			resSourceDigest, err := journal.ResourceDigest(cd.Resources[0])
+			Expect(err).ToNot(HaveOccurred())
+			processedRes := cd.Resources[0].DeepCopy()
+			processedRes.Version = "0.2.0"
+			Expect(j.Record(journal.Entry{
+				Key:          journal.ResourceKey(cd, cd.Resources[0]),
+				SourceDigest: resSourceDigest,
+				Resource:     processedRes,
+			})).To(Succeed())
+			sourceDigest, err := journal.ComponentDescriptorDigest(&cd)
+			Expect(err).ToNot(HaveOccurred())
+
+			cdDigest := digest.FromString("cd")
+			mockClient.EXPECT().PushManifest(gomock.Any(), cdRef, gomock.Any()).Return(nil)
+			mockClient.EXPECT().GetRawManifest(gomock.Any(), cdRef).Return(ocispecv1.Descriptor{Digest: cdDigest}, nil, nil)
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+
+			entry, ok := j.Get(journal.ComponentKey(cd.Name, cd.Version))
+			Expect(ok).To(BeTrue())
+			Expect(entry.SourceDigest).To(Equal(sourceDigest))
+			Expect(entry.TargetDigest).To(Equal(cdDigest.String()))
+		})
+
+		It("should report skipped resources and the uploaded component descriptor", func() {
+			transporter.Report = report.New()
+			resSourceDigest, err := journal.ResourceDigest(cd.Resources[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(j.Record(journal.Entry{
+				Key:          journal.ResourceKey(cd, cd.Resources[0]),
+				SourceDigest: resSourceDigest,
+				Resource:     &cd.Resources[0],
+			})).To(Succeed())
+
+			cdDigest := digest.FromString("cd")
+			mockClient.EXPECT().PushManifest(gomock.Any(), cdRef, gomock.Any()).Return(nil)
+			mockClient.EXPECT().GetRawManifest(gomock.Any(), cdRef).Return(ocispecv1.Descriptor{Digest: cdDigest}, nil, nil).Times(2)
+
+			Expect(transporter.Transport(context.TODO(), &cd)).To(Succeed())
+
+			Expect(transporter.Report.Components).To(HaveLen(1))
+			c := transporter.Report.Components[0]
+			Expect(c.Status).To(Equal(report.StatusSucceeded))
+			Expect(c.SourceRef).To(Equal("example.com/source/component-descriptors/example.com/a:v0.1.0"))
+			Expect(c.TargetRef).To(Equal(cdRef))
+			Expect(c.TargetDigest).To(Equal(cdDigest.String()))
+			Expect(c.Resources).To(HaveLen(1))
+			Expect(c.Resources[0].Status).To(Equal(report.StatusSkipped))
+			Expect(c.Resources[0].TargetRef).To(Equal("https://example.com/file"))
+		})
+
+	})
+
+})
+
+func newComponentDescriptor(repoCtx *cdv2.OCIRegistryRepository, name, version string, refs ...string) cdv2.ComponentDescriptor {
+	unstructuredRepoCtx, err := cdv2.NewUnstructured(repoCtx)
+	Expect(err).ToNot(HaveOccurred())
+
+	cd := cdv2.ComponentDescriptor{
+		ComponentSpec: cdv2.ComponentSpec{
+			ObjectMeta: cdv2.ObjectMeta{
+				Name:    name,
+				Version: version,
+			},
+			RepositoryContexts: []*cdv2.UnstructuredTypedObject{
+				&unstructuredRepoCtx,
+			},
+		},
+	}
+	for _, ref := range refs {
+		cd.ComponentReferences = append(cd.ComponentReferences, cdv2.ComponentReference{
+			Name:          ref,
+			ComponentName: ref,
+			Version:       version,
+		})
+	}
+	return cd
+}
+
+// recordingExporter records all exported spans in the order they are finished.
+// readCTFComponentArchive reads the component archive of a component version from a ctf.
+func readCTFComponentArchive(fs vfs.FileSystem, ctfPath, name, version string) (*ctf.ComponentArchive, error) {
+	archive, err := ctf.NewCTF(fs, ctfPath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	var found *ctf.ComponentArchive
+	err = archive.Walk(func(ca *ctf.ComponentArchive) error {
+		if ca.ComponentDescriptor.Name == name && ca.ComponentDescriptor.Version == version {
+			found = ca
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ctf.NotFoundError
+	}
+	return found, nil
+}
+
+type recordingExporter struct {
+	mux   sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(_ context.Context, span tracing.SpanData) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.spans = append(e.spans, span)
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/gardener/component-cli/pkg/logger"
+	"github.com/gardener/component-cli/pkg/transport/config"
+)
+
+// ValidateOptions contains all options to validate a transport config.
+type ValidateOptions struct {
+	// TransportCfgPath is the path to the transport config file.
+	TransportCfgPath string
+}
+
+// NewValidateCommand creates a new command to validate a transport config.
+func NewValidateCommand(ctx context.Context) *cobra.Command {
+	opts := &ValidateOptions{}
+	cmd := &cobra.Command{
+		Use:   "validate TRANSPORT_CONFIG",
+		Args:  cobra.ExactArgs(1),
+		Short: "statically validates a transport config",
+		Long: `
+validate checks a transport config without accessing any registry. It reports unknown downloader,
+processor, uploader and filter types, missing or undecodable specs, duplicate names and processing rules
+which reference undefined processors. Processors which aren't used by any processing rule are reported as warnings.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Complete(args); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if err := opts.Run(ctx, logger.Log, os.Stdout); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func (o *ValidateOptions) Complete(args []string) error {
+	o.TransportCfgPath = args[0]
+
+	if len(o.TransportCfgPath) == 0 {
+		return errors.New("a path to a transport config file has to be specified")
+	}
+	return nil
+}
+
+// Run validates the transport config and writes all found errors and warnings to w.
+func (o *ValidateOptions) Run(ctx context.Context, log logr.Logger, w io.Writer) error {
+	errs, warnings, err := config.ValidateTransportConfig(o.TransportCfgPath)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning.Error())
+	}
+
+	if len(errs) == 0 {
+		fmt.Fprintf(w, "Transport config %s is valid\n", o.TransportCfgPath)
+		return nil
+	}
+
+	for _, err := range errs {
+		fmt.Fprintln(w, err.Error())
+	}
+	return fmt.Errorf("transport config %s is invalid: %d errors found", o.TransportCfgPath, len(errs))
+}
@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package transport_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/pkg/commands/transport"
+)
+
+var _ = Describe("Validate", func() {
+
+	It("should succeed for a valid transport config", func() {
+		opts := transport.ValidateOptions{
+			TransportCfgPath: "./testdata/transport-config.yaml",
+		}
+		out := bytes.NewBuffer([]byte{})
+		Expect(opts.Run(context.TODO(), logr.Discard(), out)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("is valid"))
+	})
+
+	It("should print all errors of an invalid transport config", func() {
+		opts := transport.ValidateOptions{
+			TransportCfgPath: "../../transport/config/testdata/invalid-transport-config.yaml",
+		}
+		out := bytes.NewBuffer([]byte{})
+		err := opts.Run(context.TODO(), logr.Discard(), out)
+		Expect(err).To(MatchError(ContainSubstring("is invalid: 10 errors found")))
+		Expect(out.String()).To(ContainSubstring("processingRules[0].processors[2].name: Not found: \"missing-processor\""))
+		Expect(out.String()).To(ContainSubstring("Warning: processors[2].name: Invalid value: \"unused-processor\""))
+	})
+
+})
@@ -13,6 +13,7 @@ import (
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
 	"github.com/gardener/component-spec/bindings-go/apis/v2/signatures"
+	"github.com/gardener/component-spec/bindings-go/ctf"
 	cdoci "github.com/gardener/component-spec/bindings-go/oci"
 )
 
@@ -56,6 +57,19 @@ func (d *Digester) DigestForResource(ctx context.Context, cd cdv2.ComponentDescr
 	}
 }
 
+// DigestForResourceWithBlobResolver calculates the digest of a resource like DigestForResource, but reads local oci blobs
+// and local filesystem blobs with the given blob resolver instead of resolving them from the repository context of the
+// component descriptor.
+func (d *Digester) DigestForResourceWithBlobResolver(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource, blobResolver ctf.BlobResolver) (*cdv2.DigestSpec, error) {
+	if _, ok := d.skipAccessTypes[res.Access.Type]; ok {
+		return nil, nil
+	}
+	if (res.Access.Type == cdv2.LocalOCIBlobType || res.Access.Type == cdv2.LocalFilesystemBlobType) && blobResolver != nil {
+		return d.digestForBlob(ctx, res, blobResolver)
+	}
+	return d.DigestForResource(ctx, cd, res)
+}
+
 func (d *Digester) digestForLocalOciBlob(ctx context.Context, componentDescriptor cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.DigestSpec, error) {
 	if res.Access.GetType() != cdv2.LocalOCIBlobType {
 		return nil, fmt.Errorf("unsupported access type: %s", res.Access.Type)
@@ -66,17 +80,21 @@ func (d *Digester) digestForLocalOciBlob(ctx context.Context, componentDescripto
 		return nil, fmt.Errorf("unable to decode repository context: %w", err)
 	}
 
+	resolver := cdoci.NewResolver(d.ociClient)
+	_, blobResolver, err := resolver.ResolveWithBlobResolver(ctx, &repoctx, componentDescriptor.Name, componentDescriptor.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve component descriptor: %w", err)
+	}
+	return d.digestForBlob(ctx, res, blobResolver)
+}
+
+func (d *Digester) digestForBlob(ctx context.Context, res cdv2.Resource, blobResolver ctf.BlobResolver) (*cdv2.DigestSpec, error) {
 	tmpfile, err := ioutil.TempFile("", "")
 	if err != nil {
 		return nil, fmt.Errorf("unable to create tempfile: %w", err)
 	}
 	defer tmpfile.Close()
 
-	resolver := cdoci.NewResolver(d.ociClient)
-	_, blobResolver, err := resolver.ResolveWithBlobResolver(ctx, &repoctx, componentDescriptor.Name, componentDescriptor.Version)
-	if err != nil {
-		return nil, fmt.Errorf("unable to resolve component descriptor: %w", err)
-	}
 	if _, err := blobResolver.Resolve(ctx, res, tmpfile); err != nil {
 		return nil, fmt.Errorf("unable to resolve blob: %w", err)
 	}
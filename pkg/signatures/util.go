@@ -66,6 +66,32 @@ func RecursivelyAddDigestsToCd(cd *cdv2.ComponentDescriptor, repoContext cdv2.OC
 	return cdsWithHashes, nil
 }
 
+// VerifyResourceDigest recomputes the digest of a resource and compares it with the expected digest.
+// Local oci blobs are read with the blob resolver if it is not nil.
+// The digest is calculated with the hash algorithm of the expected digest.
+func VerifyResourceDigest(ctx context.Context, ociClient ociclient.Client, cd cdv2.ComponentDescriptor, res cdv2.Resource, expected cdv2.DigestSpec, blobResolver ctf.BlobResolver) error {
+	hasher, err := cdv2Sign.HasherForName(expected.HashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("failed creating hasher: %w", err)
+	}
+
+	// a digester is not safe for concurrent use, therefore a new one is created for every verification
+	digester := NewDigester(ociClient, *hasher, []string{})
+	actual, err := digester.DigestForResourceWithBlobResolver(ctx, cd, res, blobResolver)
+	if err != nil {
+		return fmt.Errorf("failed digesting resource %s:%s: %w", res.Name, res.Version, err)
+	}
+	if actual == nil {
+		return fmt.Errorf("unable to digest resource %s:%s with access type %s", res.Name, res.Version, res.Access.Type)
+	}
+
+	if actual.NormalisationAlgorithm != expected.NormalisationAlgorithm || actual.Value != expected.Value {
+		return fmt.Errorf("digest of resource %s:%s does not match: expected %s:%s, got %s:%s", res.Name, res.Version,
+			expected.NormalisationAlgorithm, expected.Value, actual.NormalisationAlgorithm, actual.Value)
+	}
+	return nil
+}
+
 func UploadCDPreservingLocalOciBlobs(ctx context.Context, cd v2.ComponentDescriptor, targetRepository cdv2.OCIRegistryRepository, ociClient ociclient.ExtendedClient, cache ociCache.Cache, blobResolvers map[string]ctf.BlobResolver, force bool, log logr.Logger) error {
 	// check if the component descriptor already exists and skip if not forced to overwrite
 	if !force {
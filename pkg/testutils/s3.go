@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package testutils
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/component-cli/pkg/transport/s3"
+)
+
+// S3Server is an in-memory stand-in for an S3 compatible object store which supports
+// reading and writing objects with path style and virtual host style requests.
+type S3Server struct {
+	*httptest.Server
+
+	mux         sync.Mutex
+	objects     map[string][]byte
+	region      string
+	credentials *s3.Credentials
+}
+
+// NewS3Server starts a new S3 stand-in. If credentials is not nil, all requests must be signed with them.
+func NewS3Server(region string, credentials *s3.Credentials) *S3Server {
+	s := &S3Server{
+		objects:     map[string][]byte{},
+		region:      region,
+		credentials: credentials,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Object returns the content of an object.
+func (s *S3Server) Object(bucket, key string) ([]byte, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	data, ok := s.objects[bucket+"/"+key]
+	return data, ok
+}
+
+// PutObject sets the content of an object.
+func (s *S3Server) PutObject(bucket, key string, data []byte) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.objects[bucket+"/"+key] = data
+}
+
+func (s *S3Server) handle(w http.ResponseWriter, r *http.Request) {
+	if s.credentials != nil && !s.validSignature(r) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	// virtual host style requests contain the bucket as first label of the host
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if host := strings.TrimSuffix(r.Host, "."+strings.TrimPrefix(s.URL, "http://")); host != r.Host {
+		path = host + "/" + path
+	}
+	if !strings.Contains(path, "/") {
+		http.Error(w, "InvalidURI", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.mux.Lock()
+		data, ok := s.objects[path]
+		s.mux.Unlock()
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mux.Lock()
+		s.objects[path] = data
+		s.mux.Unlock()
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validSignature recomputes the signature of a request with the known credentials.
+func (s *S3Server) validSignature(r *http.Request) bool {
+	date, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	signed := r.Clone(r.Context())
+	signed.Header = http.Header{}
+	for name, values := range r.Header {
+		if name == "Content-Type" || name == "Range" || strings.HasPrefix(name, "X-Amz-") {
+			signed.Header[name] = values
+		}
+	}
+	if err := s3.Sign(signed, *s.credentials, s.region, r.Header.Get("X-Amz-Content-Sha256"), date); err != nil {
+		return false
+	}
+	return signed.Header.Get("Authorization") == r.Header.Get("Authorization")
+}
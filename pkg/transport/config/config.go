@@ -5,6 +5,8 @@ package config
 
 import (
 	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type meta struct {
@@ -20,9 +22,17 @@ type transportConfig struct {
 }
 
 type baseProcessorDefinition struct {
-	Name string           `json:"name"`
-	Type string           `json:"type"`
-	Spec *json.RawMessage `json:"spec"`
+	Name    string           `json:"name"`
+	Type    string           `json:"type"`
+	Spec    *json.RawMessage `json:"spec"`
+	Timeout *metav1.Duration `json:"timeout"`
+	Retry   *retryDefinition `json:"retry"`
+}
+
+type retryDefinition struct {
+	MaxAttempts    int              `json:"maxAttempts"`
+	InitialBackoff *metav1.Duration `json:"initialBackoff"`
+	MaxBackoff     *metav1.Duration `json:"maxBackoff"`
 }
 
 type filterDefinition struct {
@@ -38,6 +48,9 @@ type downloaderDefinition struct {
 type uploaderDefinition struct {
 	baseProcessorDefinition
 	Filters []filterDefinition `json:"filters"`
+	// TargetRepository is the base url of the repository the uploader uploads to.
+	// Uploaders without a target repository upload to the target of the transport.
+	TargetRepository string `json:"targetRepository"`
 }
 
 type processorDefinition struct {
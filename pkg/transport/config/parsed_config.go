@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
 	"sigs.k8s.io/yaml"
@@ -22,20 +23,37 @@ type ParsedDownloaderDefinition struct {
 	Name    string
 	Type    string
 	Spec    *json.RawMessage
+	Timeout *time.Duration
+	Retry   *ParsedRetryDefinition
 	Filters []filters.Filter
 }
 
 type ParsedProcessorDefinition struct {
-	Name string
-	Type string
-	Spec *json.RawMessage
+	Name    string
+	Type    string
+	Spec    *json.RawMessage
+	Timeout *time.Duration
+	Retry   *ParsedRetryDefinition
 }
 
 type ParsedUploaderDefinition struct {
 	Name    string
 	Type    string
 	Spec    *json.RawMessage
+	Timeout *time.Duration
+	Retry   *ParsedRetryDefinition
 	Filters []filters.Filter
+	// TargetRepository is the base url of the repository the uploader uploads to.
+	// Empty for uploaders which upload to the target of the transport.
+	TargetRepository string
+}
+
+// ParsedRetryDefinition defines the retry policy of a downloader, processor, or uploader.
+// Unset backoff values are nil.
+type ParsedRetryDefinition struct {
+	MaxAttempts    int
+	InitialBackoff *time.Duration
+	MaxBackoff     *time.Duration
 }
 
 type ParsedProcessingRuleDefinition struct {
@@ -44,16 +62,15 @@ type ParsedProcessingRuleDefinition struct {
 	Filters    []filters.Filter
 }
 
-// ParseTransportConfig loads and parses a transport config file
+// ParseTransportConfig loads, validates, and parses a transport config file
 func ParseTransportConfig(configFilePath string) (*ParsedTransportConfig, error) {
-	transportCfgYaml, err := os.ReadFile(configFilePath)
+	config, err := loadTransportConfig(configFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read transport config file: %w", err)
+		return nil, err
 	}
 
-	var config transportConfig
-	if err := yaml.Unmarshal(transportCfgYaml, &config); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal transport config: %w", err)
+	if errs := validateTransportConfig(config); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid transport config: %w", errs.ToAggregate())
 	}
 
 	var parsedConfig ParsedTransportConfig
@@ -65,20 +82,26 @@ func ParseTransportConfig(configFilePath string) (*ParsedTransportConfig, error)
 		if err != nil {
 			return nil, fmt.Errorf("unable to create filters for downloader %s: %w", downloaderDefinition.Name, err)
 		}
+		timeout, retry := parseExecutionSettings(downloaderDefinition.baseProcessorDefinition)
 		parsedConfig.Downloaders = append(parsedConfig.Downloaders, ParsedDownloaderDefinition{
 			Name:    downloaderDefinition.Name,
 			Type:    downloaderDefinition.Type,
 			Spec:    downloaderDefinition.Spec,
+			Timeout: timeout,
+			Retry:   retry,
 			Filters: filters,
 		})
 	}
 
 	// processors
 	for _, processorsDefinition := range config.Processors {
+		timeout, retry := parseExecutionSettings(processorsDefinition.baseProcessorDefinition)
 		parsedConfig.Processors = append(parsedConfig.Processors, ParsedProcessorDefinition{
-			Name: processorsDefinition.Name,
-			Type: processorsDefinition.Type,
-			Spec: processorsDefinition.Spec,
+			Name:    processorsDefinition.Name,
+			Type:    processorsDefinition.Type,
+			Spec:    processorsDefinition.Spec,
+			Timeout: timeout,
+			Retry:   retry,
 		})
 	}
 
@@ -88,11 +111,16 @@ func ParseTransportConfig(configFilePath string) (*ParsedTransportConfig, error)
 		if err != nil {
 			return nil, fmt.Errorf("unable to create filters for uploader %s: %w", uploaderDefinition.Name, err)
 		}
+		timeout, retry := parseExecutionSettings(uploaderDefinition.baseProcessorDefinition)
 		parsedConfig.Uploaders = append(parsedConfig.Uploaders, ParsedUploaderDefinition{
 			Name:    uploaderDefinition.Name,
 			Type:    uploaderDefinition.Type,
 			Spec:    uploaderDefinition.Spec,
+			Timeout: timeout,
+			Retry:   retry,
 			Filters: filters,
+
+			TargetRepository: uploaderDefinition.TargetRepository,
 		})
 	}
 
@@ -146,6 +174,20 @@ func (c *ParsedTransportConfig) MatchUploaders(cd cdv2.ComponentDescriptor, res
 	return uls
 }
 
+// TargetRepositories returns the distinct target repositories which are declared by uploaders in definition order.
+func (c *ParsedTransportConfig) TargetRepositories() []string {
+	targets := []string{}
+	visited := map[string]bool{}
+	for _, uploader := range c.Uploaders {
+		if len(uploader.TargetRepository) == 0 || visited[uploader.TargetRepository] {
+			continue
+		}
+		visited[uploader.TargetRepository] = true
+		targets = append(targets, uploader.TargetRepository)
+	}
+	return targets
+}
+
 // MatchProcessingRules finds all matching processing rules
 func (c *ParsedTransportConfig) MatchProcessingRules(cd cdv2.ComponentDescriptor, res cdv2.Resource) []ParsedProcessingRuleDefinition {
 	prs := []ParsedProcessingRuleDefinition{}
@@ -166,6 +208,31 @@ func areAllFiltersMatching(filters []filters.Filter, cd cdv2.ComponentDescriptor
 	return true
 }
 
+// parseExecutionSettings converts the timeout and retry settings of a definition.
+// The settings must have been validated before.
+func parseExecutionSettings(def baseProcessorDefinition) (*time.Duration, *ParsedRetryDefinition) {
+	var timeout *time.Duration
+	if def.Timeout != nil {
+		timeout = &def.Timeout.Duration
+	}
+
+	if def.Retry == nil {
+		return timeout, nil
+	}
+
+	retry := ParsedRetryDefinition{
+		MaxAttempts: def.Retry.MaxAttempts,
+	}
+	if def.Retry.InitialBackoff != nil {
+		retry.InitialBackoff = &def.Retry.InitialBackoff.Duration
+	}
+	if def.Retry.MaxBackoff != nil {
+		retry.MaxBackoff = &def.Retry.MaxBackoff.Duration
+	}
+
+	return timeout, &retry
+}
+
 func findProcessorByName(name string, lookup *ParsedTransportConfig) (*ParsedProcessorDefinition, error) {
 	for _, processor := range lookup.Processors {
 		if processor.Name == name {
@@ -180,9 +247,23 @@ func createFilterList(filterDefinitions []filterDefinition, ff *filters.FilterFa
 	for _, f := range filterDefinitions {
 		filter, err := ff.Create(f.Type, f.Spec)
 		if err != nil {
-			return nil, fmt.Errorf("error creating filter list for type %s with args %s: %w", f.Type, string(*f.Spec), err)
+			return nil, fmt.Errorf("error creating filter list for type %s: %w", f.Type, err)
 		}
 		filters = append(filters, filter)
 	}
 	return filters, nil
 }
+
+func loadTransportConfig(configFilePath string) (*transportConfig, error) {
+	transportCfgYaml, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read transport config file: %w", err)
+	}
+
+	var config transportConfig
+	if err := yaml.Unmarshal(transportCfgYaml, &config); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal transport config: %w", err)
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package config_test
+
+import (
+	"time"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/pkg/transport/config"
+)
+
+var _ = Describe("parsed config", func() {
+
+	Context("ParseTransportConfig", func() {
+
+		It("should parse timeout and retry settings", func() {
+			cfg, err := config.ParseTransportConfig("./testdata/transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Downloaders).To(HaveLen(1))
+			Expect(*cfg.Downloaders[0].Timeout).To(Equal(2 * time.Minute))
+			Expect(cfg.Downloaders[0].Retry.MaxAttempts).To(Equal(3))
+			Expect(*cfg.Downloaders[0].Retry.InitialBackoff).To(Equal(5 * time.Second))
+			Expect(*cfg.Downloaders[0].Retry.MaxBackoff).To(Equal(30 * time.Second))
+
+			Expect(cfg.Processors).To(HaveLen(1))
+			Expect(cfg.Processors[0].Timeout).To(BeNil())
+			Expect(cfg.Processors[0].Retry).To(BeNil())
+
+			Expect(cfg.Uploaders).To(HaveLen(1))
+			Expect(*cfg.Uploaders[0].Timeout).To(Equal(time.Hour))
+			Expect(cfg.Uploaders[0].Retry.MaxAttempts).To(Equal(5))
+			Expect(cfg.Uploaders[0].Retry.InitialBackoff).To(BeNil())
+			Expect(cfg.Uploaders[0].Retry.MaxBackoff).To(BeNil())
+		})
+
+		It("should match processing rules with nested filters", func() {
+			cfg, err := config.ParseTransportConfig("./testdata/transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "my-res",
+					Version: "v0.1.0",
+					Type:    cdv2.OCIImageType,
+				},
+			}
+			cd := cdv2.ComponentDescriptor{}
+			cd.Name = "github.com/test/test-component"
+
+			rules := cfg.MatchProcessingRules(cd, res)
+			Expect(rules).To(HaveLen(1))
+			Expect(rules[0].Name).To(Equal("process-images"))
+
+			cd.Name = "github.com/test/other-component"
+			rules = cfg.MatchProcessingRules(cd, res)
+			Expect(rules).To(HaveLen(2))
+			Expect(rules[1].Name).To(Equal("process-images-except-test-component"))
+		})
+
+		It("should parse the target repositories of uploaders", func() {
+			cfg, err := config.ParseTransportConfig("./testdata/multi-target-transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cfg.Uploaders).To(HaveLen(4))
+			Expect(cfg.Uploaders[0].TargetRepository).To(BeEmpty())
+			Expect(cfg.Uploaders[1].TargetRepository).To(Equal("us.example.com/target"))
+			Expect(cfg.TargetRepositories()).To(Equal([]string{"us.example.com/target", "ap.example.com/target"}))
+		})
+
+	})
+})
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package config
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/gardener/component-cli/pkg/transport/filters"
+	"github.com/gardener/component-cli/pkg/transport/process/downloaders"
+	"github.com/gardener/component-cli/pkg/transport/process/processors"
+	"github.com/gardener/component-cli/pkg/transport/process/uploaders"
+)
+
+// ValidateTransportConfig loads a transport config file and statically validates it.
+// Additionally to the validation errors, warnings are returned for settings which don't prevent a transport,
+// e.g. processors which aren't used by any processing rule.
+// The returned error is only set if the file can't be read or unmarshaled.
+func ValidateTransportConfig(configFilePath string) (field.ErrorList, field.ErrorList, error) {
+	config, err := loadTransportConfig(configFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return validateTransportConfig(config), unusedProcessors(config), nil
+}
+
+func validateTransportConfig(config *transportConfig) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	downloaderNames := sets.NewString()
+	for i, def := range config.Downloaders {
+		fldPath := field.NewPath("downloaders").Index(i)
+		allErrs = append(allErrs, validateName(fldPath.Child("name"), def.Name, downloaderNames)...)
+		allErrs = append(allErrs, downloaders.Validate(fldPath, def.Type, def.Spec)...)
+		allErrs = append(allErrs, validateExecutionSettings(fldPath, def.baseProcessorDefinition)...)
+		allErrs = append(allErrs, validateFilters(fldPath.Child("filters"), def.Filters)...)
+	}
+
+	processorNames := sets.NewString()
+	for i, def := range config.Processors {
+		fldPath := field.NewPath("processors").Index(i)
+		allErrs = append(allErrs, validateName(fldPath.Child("name"), def.Name, processorNames)...)
+		allErrs = append(allErrs, processors.Validate(fldPath, def.Type, def.Spec)...)
+		allErrs = append(allErrs, validateExecutionSettings(fldPath, def.baseProcessorDefinition)...)
+	}
+
+	uploaderNames := sets.NewString()
+	for i, def := range config.Uploaders {
+		fldPath := field.NewPath("uploaders").Index(i)
+		allErrs = append(allErrs, validateName(fldPath.Child("name"), def.Name, uploaderNames)...)
+		allErrs = append(allErrs, uploaders.Validate(fldPath, def.Type, def.Spec)...)
+		allErrs = append(allErrs, validateExecutionSettings(fldPath, def.baseProcessorDefinition)...)
+		allErrs = append(allErrs, validateFilters(fldPath.Child("filters"), def.Filters)...)
+	}
+
+	ruleNames := sets.NewString()
+	for i, rule := range config.ProcessingRules {
+		fldPath := field.NewPath("processingRules").Index(i)
+		allErrs = append(allErrs, validateName(fldPath.Child("name"), rule.Name, ruleNames)...)
+		allErrs = append(allErrs, validateFilters(fldPath.Child("filters"), rule.Filters)...)
+
+		for j, ref := range rule.Processors {
+			refPath := fldPath.Child("processors").Index(j).Child("name")
+			if len(ref.Name) == 0 {
+				allErrs = append(allErrs, field.Required(refPath, "name of the referenced processor must not be empty"))
+				continue
+			}
+			if !processorNames.Has(ref.Name) {
+				allErrs = append(allErrs, field.NotFound(refPath, ref.Name))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// unusedProcessors reports all processors which aren't referenced by any processing rule.
+func unusedProcessors(config *transportConfig) field.ErrorList {
+	usedProcessors := sets.NewString()
+	for _, rule := range config.ProcessingRules {
+		for _, ref := range rule.Processors {
+			usedProcessors.Insert(ref.Name)
+		}
+	}
+
+	warnings := field.ErrorList{}
+	for i, def := range config.Processors {
+		if len(def.Name) != 0 && !usedProcessors.Has(def.Name) {
+			warnings = append(warnings, field.Invalid(field.NewPath("processors").Index(i).Child("name"), def.Name, "processor is not referenced by any processing rule"))
+		}
+	}
+	return warnings
+}
+
+func validateName(fldPath *field.Path, name string, names sets.String) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(name) == 0 {
+		return append(allErrs, field.Required(fldPath, "name must not be empty"))
+	}
+	if names.Has(name) {
+		allErrs = append(allErrs, field.Duplicate(fldPath, name))
+	}
+	names.Insert(name)
+	return allErrs
+}
+
+func validateFilters(fldPath *field.Path, filterDefinitions []filterDefinition) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, def := range filterDefinitions {
+		allErrs = append(allErrs, filters.Validate(fldPath.Index(i), def.Type, def.Spec)...)
+	}
+	return allErrs
+}
+
+func validateExecutionSettings(fldPath *field.Path, def baseProcessorDefinition) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if def.Timeout != nil && def.Timeout.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), def.Timeout.Duration.String(), "timeout must be greater than 0"))
+	}
+
+	if def.Retry == nil {
+		return allErrs
+	}
+
+	retryPath := fldPath.Child("retry")
+	if def.Retry.MaxAttempts < 1 {
+		allErrs = append(allErrs, field.Invalid(retryPath.Child("maxAttempts"), def.Retry.MaxAttempts, "maxAttempts must be greater than 0"))
+	}
+	if def.Retry.InitialBackoff != nil && def.Retry.InitialBackoff.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(retryPath.Child("initialBackoff"), def.Retry.InitialBackoff.Duration.String(), "initialBackoff must not be negative"))
+	}
+	if def.Retry.MaxBackoff != nil && def.Retry.MaxBackoff.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(retryPath.Child("maxBackoff"), def.Retry.MaxBackoff.Duration.String(), "maxBackoff must not be negative"))
+	}
+	return allErrs
+}
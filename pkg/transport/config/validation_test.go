@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/gardener/component-cli/pkg/transport/config"
+)
+
+var _ = Describe("validation", func() {
+
+	Context("ValidateTransportConfig", func() {
+
+		It("should not return errors for a valid config", func() {
+			errs, warnings, err := config.ValidateTransportConfig("./testdata/transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(errs).To(BeEmpty())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should return all errors of an invalid config with their field paths", func() {
+			errs, _, err := config.ValidateTransportConfig("./testdata/invalid-transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+
+			type fieldError struct {
+				Type  field.ErrorType
+				Field string
+			}
+			actual := []fieldError{}
+			for _, e := range errs {
+				actual = append(actual, fieldError{Type: e.Type, Field: e.Field})
+			}
+
+			Expect(actual).To(ConsistOf(
+				fieldError{Type: field.ErrorTypeRequired, Field: "downloaders[0].filters[0].spec"},
+				fieldError{Type: field.ErrorTypeDuplicate, Field: "downloaders[1].name"},
+				fieldError{Type: field.ErrorTypeNotSupported, Field: "downloaders[1].type"},
+				fieldError{Type: field.ErrorTypeRequired, Field: "processors[0].spec"},
+				fieldError{Type: field.ErrorTypeInvalid, Field: "processors[1].spec"},
+				fieldError{Type: field.ErrorTypeInvalid, Field: "processors[2].timeout"},
+				fieldError{Type: field.ErrorTypeRequired, Field: "uploaders[0].spec"},
+				fieldError{Type: field.ErrorTypeInvalid, Field: "uploaders[0].retry.maxAttempts"},
+				fieldError{Type: field.ErrorTypeNotSupported, Field: "uploaders[0].filters[0].type"},
+				fieldError{Type: field.ErrorTypeNotFound, Field: "processingRules[0].processors[2].name"},
+			))
+		})
+
+		It("should return warnings for processors which aren't used by any processing rule", func() {
+			_, warnings, err := config.ValidateTransportConfig("./testdata/invalid-transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0].Field).To(Equal("processors[2].name"))
+			Expect(warnings[0].BadValue).To(Equal("unused-processor"))
+		})
+
+		It("should parse a config with processors which aren't used by any processing rule", func() {
+			_, err := config.ParseTransportConfig("./testdata/unused-processor-transport-config.yaml")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should fail to parse an invalid config instead of panicking", func() {
+			_, err := config.ParseTransportConfig("./testdata/invalid-transport-config.yaml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("invalid transport config: "))
+			Expect(err.Error()).To(ContainSubstring("downloaders[0].filters[0].spec: Required value"))
+		})
+
+	})
+})
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package filters
+
+import (
+	"fmt"
+	"regexp"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+)
+
+type ExtraIdentityFilterSpec struct {
+	// ExtraIdentity maps extra identity keys to regexps which must match the respective values.
+	ExtraIdentity map[string]string `json:"extraIdentity"`
+}
+
+type extraIdentityFilter struct {
+	extraIdentity map[string]*regexp.Regexp
+}
+
+func (f extraIdentityFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	for key, valueRegexp := range f.extraIdentity {
+		value, ok := r.ExtraIdentity[key]
+		if !ok || !valueRegexp.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewExtraIdentityFilter creates a new extraIdentityFilter which matches if all configured
+// extra identity keys exist and their values match the respective regexps
+func NewExtraIdentityFilter(spec ExtraIdentityFilterSpec) (Filter, error) {
+	if len(spec.ExtraIdentity) == 0 {
+		return nil, fmt.Errorf("extraIdentity must not be empty")
+	}
+
+	filter := extraIdentityFilter{
+		extraIdentity: map[string]*regexp.Regexp{},
+	}
+
+	for key, value := range spec.ExtraIdentity {
+		valueRegexp, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse regexp %s for key %s: %w", value, key, err)
+		}
+		filter.extraIdentity[key] = valueRegexp
+	}
+
+	return &filter, nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/yaml"
 )
 
@@ -19,8 +20,47 @@ const (
 
 	// AccessTypeFilterType defines the type of a access type filter
 	AccessTypeFilterType = "AccessTypeFilter"
+
+	// LabelFilterType defines the type of a label filter
+	LabelFilterType = "LabelFilter"
+
+	// ResourceNameFilterType defines the type of a resource name filter
+	ResourceNameFilterType = "ResourceNameFilter"
+
+	// ResourceRelationFilterType defines the type of a resource relation filter
+	ResourceRelationFilterType = "ResourceRelationFilter"
+
+	// ExtraIdentityFilterType defines the type of a extra identity filter
+	ExtraIdentityFilterType = "ExtraIdentityFilter"
+
+	// ResourceVersionFilterType defines the type of a resource version filter
+	ResourceVersionFilterType = "ResourceVersionFilter"
+
+	// AndFilterType defines the type of a filter which matches if all nested filters match
+	AndFilterType = "AndFilter"
+
+	// OrFilterType defines the type of a filter which matches if at least one nested filter matches
+	OrFilterType = "OrFilter"
+
+	// NotFilterType defines the type of a filter which negates a nested filter
+	NotFilterType = "NotFilter"
 )
 
+// supportedFilterTypes contains all filter types which can be created by the filter factory
+var supportedFilterTypes = []string{
+	ComponentNameFilterType,
+	ResourceTypeFilterType,
+	AccessTypeFilterType,
+	LabelFilterType,
+	ResourceNameFilterType,
+	ResourceRelationFilterType,
+	ExtraIdentityFilterType,
+	ResourceVersionFilterType,
+	AndFilterType,
+	OrFilterType,
+	NotFilterType,
+}
+
 // NewFilterFactory creates a new filter factory
 // How to add a new filter:
 // - Add Go file to filters package which contains the source code of the new filter
@@ -35,6 +75,9 @@ type FilterFactory struct{}
 
 // Create creates a new filter defined by a type and a spec
 func (f *FilterFactory) Create(filterType string, spec *json.RawMessage) (Filter, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("spec of filter type %s must not be empty", filterType)
+	}
 	switch filterType {
 	case ComponentNameFilterType:
 		return f.createComponentNameFilter(spec)
@@ -42,6 +85,22 @@ func (f *FilterFactory) Create(filterType string, spec *json.RawMessage) (Filter
 		return f.createResourceTypeFilter(spec)
 	case AccessTypeFilterType:
 		return f.createAccessTypeFilter(spec)
+	case LabelFilterType:
+		return f.createLabelFilter(spec)
+	case ResourceNameFilterType:
+		return f.createResourceNameFilter(spec)
+	case ResourceRelationFilterType:
+		return f.createResourceRelationFilter(spec)
+	case ExtraIdentityFilterType:
+		return f.createExtraIdentityFilter(spec)
+	case ResourceVersionFilterType:
+		return f.createResourceVersionFilter(spec)
+	case AndFilterType:
+		return f.createAndFilter(spec)
+	case OrFilterType:
+		return f.createOrFilter(spec)
+	case NotFilterType:
+		return f.createNotFilter(spec)
 	default:
 		return nil, fmt.Errorf("unknown filter type %s", filterType)
 	}
@@ -73,3 +132,132 @@ func (f *FilterFactory) createAccessTypeFilter(rawSpec *json.RawMessage) (Filter
 
 	return NewAccessTypeFilter(spec)
 }
+
+func (f *FilterFactory) createLabelFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec LabelFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	return NewLabelFilter(spec)
+}
+
+func (f *FilterFactory) createResourceNameFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec ResourceNameFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	return NewResourceNameFilter(spec)
+}
+
+func (f *FilterFactory) createResourceRelationFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec ResourceRelationFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	return NewResourceRelationFilter(spec)
+}
+
+func (f *FilterFactory) createExtraIdentityFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec ExtraIdentityFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	return NewExtraIdentityFilter(spec)
+}
+
+func (f *FilterFactory) createResourceVersionFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec ResourceVersionFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	return NewResourceVersionFilter(spec)
+}
+
+func (f *FilterFactory) createAndFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec AndFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	filters, err := f.createNestedFilters(spec.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAndFilter(filters...)
+}
+
+func (f *FilterFactory) createOrFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec OrFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	filters, err := f.createNestedFilters(spec.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOrFilter(filters...)
+}
+
+func (f *FilterFactory) createNotFilter(rawSpec *json.RawMessage) (Filter, error) {
+	var spec NotFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if spec.Filter == nil {
+		return nil, fmt.Errorf("filter must not be empty")
+	}
+
+	filter, err := f.Create(spec.Filter.Type, spec.Filter.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create nested filter of type %s: %w", spec.Filter.Type, err)
+	}
+
+	return NewNotFilter(filter)
+}
+
+// createNestedFilters recursively creates the nested filters of a logical filter
+func (f *FilterFactory) createNestedFilters(filterDefinitions []FilterDefinition) ([]Filter, error) {
+	filters := []Filter{}
+	for i, def := range filterDefinitions {
+		filter, err := f.Create(def.Type, def.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create nested filter %d of type %s: %w", i, def.Type, err)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// Validate validates the type and spec of a filter definition, including all nested filters
+func Validate(fldPath *field.Path, filterType string, spec *json.RawMessage) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	supported := false
+	for _, t := range supportedFilterTypes {
+		if t == filterType {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return append(allErrs, field.NotSupported(fldPath.Child("type"), filterType, supportedFilterTypes))
+	}
+
+	if spec == nil {
+		return append(allErrs, field.Required(fldPath.Child("spec"), fmt.Sprintf("spec of filter type %s must not be empty", filterType)))
+	}
+
+	if _, err := NewFilterFactory().Create(filterType, spec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(*spec), err.Error()))
+	}
+	return allErrs
+}
@@ -4,6 +4,7 @@
 package filters_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
@@ -216,4 +217,305 @@ var _ = Describe("filters", func() {
 
 	})
 
+	Context("resourceNameFilter", func() {
+
+		It("should match if resource name is in include list", func() {
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name: "my-res",
+				},
+			}
+			f, err := filter.NewResourceNameFilter(filter.ResourceNameFilterSpec{
+				IncludeResourceNames: []string{"other-res", "my-.*"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(true))
+		})
+
+		It("should not match if resource name is not in include list", func() {
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name: "my-res",
+				},
+			}
+			f, err := filter.NewResourceNameFilter(filter.ResourceNameFilterSpec{
+				IncludeResourceNames: []string{"other-res"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+		})
+
+		It("should return error upon creation if include list is empty", func() {
+			_, err := filter.NewResourceNameFilter(filter.ResourceNameFilterSpec{})
+			Expect(err).To(MatchError("includeResourceNames must not be empty"))
+		})
+
+	})
+
+	Context("resourceRelationFilter", func() {
+
+		It("should match if resource relation is in include list", func() {
+			res := cdv2.Resource{
+				Relation: cdv2.LocalRelation,
+			}
+			f, err := filter.NewResourceRelationFilter(filter.ResourceRelationFilterSpec{
+				IncludeRelations: []cdv2.ResourceRelation{cdv2.LocalRelation},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(true))
+
+			res.Relation = cdv2.ExternalRelation
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+		})
+
+		It("should return error upon creation if a relation is invalid", func() {
+			_, err := filter.NewResourceRelationFilter(filter.ResourceRelationFilterSpec{
+				IncludeRelations: []cdv2.ResourceRelation{"internal"},
+			})
+			Expect(err).To(MatchError("invalid relation internal, must be one of local, external"))
+		})
+
+	})
+
+	Context("extraIdentityFilter", func() {
+
+		It("should match if all extra identity values match", func() {
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					ExtraIdentity: cdv2.Identity{
+						"platform": "linux-amd64",
+						"variant":  "debug",
+					},
+				},
+			}
+			f, err := filter.NewExtraIdentityFilter(filter.ExtraIdentityFilterSpec{
+				ExtraIdentity: map[string]string{
+					"platform": "linux-.*",
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(true))
+
+			f, err = filter.NewExtraIdentityFilter(filter.ExtraIdentityFilterSpec{
+				ExtraIdentity: map[string]string{
+					"platform": "linux-.*",
+					"os":       "ubuntu",
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+		})
+
+		It("should return error upon creation if extra identity is empty", func() {
+			_, err := filter.NewExtraIdentityFilter(filter.ExtraIdentityFilterSpec{})
+			Expect(err).To(MatchError("extraIdentity must not be empty"))
+		})
+
+	})
+
+	Context("resourceVersionFilter", func() {
+
+		It("should match if resource version satisfies the constraint", func() {
+			f, err := filter.NewResourceVersionFilter(filter.ResourceVersionFilterSpec{
+				Constraint: ">= 1.2.0, < 2.0.0",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			res := cdv2.Resource{}
+			res.Version = "v1.5.3"
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(true))
+			res.Version = "2.0.0"
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+			res.Version = "latest"
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+		})
+
+		It("should return error upon creation if constraint is invalid", func() {
+			_, err := filter.NewResourceVersionFilter(filter.ResourceVersionFilterSpec{
+				Constraint: "~> abc",
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to parse constraint"))
+		})
+
+	})
+
+	Context("labelFilter", func() {
+
+		res := cdv2.Resource{
+			IdentityObjectMeta: cdv2.IdentityObjectMeta{
+				Labels: cdv2.Labels{
+					{
+						Name:  "cloud.gardener.cnudie/responsibles",
+						Value: json.RawMessage(`[{"type": "githubTeam", "teamname": "gardener/component-cli"}]`),
+					},
+				},
+			},
+		}
+
+		It("should match if the label exists", func() {
+			f, err := filter.NewLabelFilter(filter.LabelFilterSpec{
+				Name: "cloud.gardener.cnudie/responsibles",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(true))
+
+			f, err = filter.NewLabelFilter(filter.LabelFilterSpec{
+				Name: "other-label",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+		})
+
+		It("should match if the label value is equal", func() {
+			value := json.RawMessage(`[ { "teamname": "gardener/component-cli", "type": "githubTeam" } ]`)
+			f, err := filter.NewLabelFilter(filter.LabelFilterSpec{
+				Name:  "cloud.gardener.cnudie/responsibles",
+				Value: &value,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(true))
+		})
+
+		It("should match if the value selected by the path is equal", func() {
+			value := json.RawMessage(`"gardener/component-cli"`)
+			f, err := filter.NewLabelFilter(filter.LabelFilterSpec{
+				Name:  "cloud.gardener.cnudie/responsibles",
+				Path:  "$[0].teamname",
+				Value: &value,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(true))
+
+			value = json.RawMessage(`"gardener/other-team"`)
+			f, err = filter.NewLabelFilter(filter.LabelFilterSpec{
+				Name:  "cloud.gardener.cnudie/responsibles",
+				Path:  "$[0]['teamname']",
+				Value: &value,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+		})
+
+		It("should not match if the path doesn't exist", func() {
+			f, err := filter.NewLabelFilter(filter.LabelFilterSpec{
+				Name: "cloud.gardener.cnudie/responsibles",
+				Path: "$[1].teamname",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cdv2.ComponentDescriptor{}, res)).To(Equal(false))
+		})
+
+		It("should return error upon creation if name is empty or path is invalid", func() {
+			_, err := filter.NewLabelFilter(filter.LabelFilterSpec{})
+			Expect(err).To(MatchError("name must not be empty"))
+
+			_, err = filter.NewLabelFilter(filter.LabelFilterSpec{
+				Name: "my-label",
+				Path: "$[abc]",
+			})
+			Expect(err).To(MatchError("unable to parse path $[abc]: invalid array index abc"))
+		})
+
+	})
+
+	Context("logical filters", func() {
+
+		cd := cdv2.ComponentDescriptor{
+			ComponentSpec: cdv2.ComponentSpec{
+				ObjectMeta: cdv2.ObjectMeta{
+					Name: "github.com/test/my-component",
+				},
+			},
+		}
+		res := cdv2.Resource{
+			IdentityObjectMeta: cdv2.IdentityObjectMeta{
+				Name:    "my-res",
+				Version: "v0.1.0",
+				Type:    cdv2.OCIImageType,
+			},
+			Access: cdv2.NewEmptyUnstructured(cdv2.OCIRegistryType),
+		}
+
+		matching, _ := filter.NewResourceTypeFilter(filter.ResourceTypeFilterSpec{
+			IncludeResourceTypes: []string{cdv2.OCIImageType},
+		})
+		notMatching, _ := filter.NewResourceTypeFilter(filter.ResourceTypeFilterSpec{
+			IncludeResourceTypes: []string{"helm"},
+		})
+
+		It("should match if all filters of an and filter match", func() {
+			f, err := filter.NewAndFilter(matching, matching)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cd, res)).To(Equal(true))
+
+			f, err = filter.NewAndFilter(matching, notMatching)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cd, res)).To(Equal(false))
+		})
+
+		It("should match if at least one filter of an or filter matches", func() {
+			f, err := filter.NewOrFilter(notMatching, matching)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cd, res)).To(Equal(true))
+
+			f, err = filter.NewOrFilter(notMatching, notMatching)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cd, res)).To(Equal(false))
+		})
+
+		It("should negate the nested filter of a not filter", func() {
+			f, err := filter.NewNotFilter(matching)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cd, res)).To(Equal(false))
+
+			f, err = filter.NewNotFilter(notMatching)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cd, res)).To(Equal(true))
+		})
+
+		It("should return error upon creation if nested filters are empty", func() {
+			_, err := filter.NewAndFilter()
+			Expect(err).To(MatchError("filters must not be empty"))
+			_, err = filter.NewOrFilter()
+			Expect(err).To(MatchError("filters must not be empty"))
+			_, err = filter.NewNotFilter(nil)
+			Expect(err).To(MatchError("filter must not be nil"))
+		})
+
+		It("should create recursively nested filters from a spec", func() {
+			// all ociImage resources except those from component github.com/test/my-component
+			spec := json.RawMessage(`{
+				"filters": [
+					{ "type": "ResourceTypeFilter", "spec": { "includeResourceTypes": [ "ociImage" ] } },
+					{ "type": "NotFilter", "spec": { "filter": {
+						"type": "OrFilter",
+						"spec": { "filters": [
+							{ "type": "ComponentNameFilter", "spec": { "includeComponentNames": [ "github.com/test/my-component" ] } }
+						] }
+					} } }
+				]
+			}`)
+
+			f, err := filter.NewFilterFactory().Create(filter.AndFilterType, &spec)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.Matches(cd, res)).To(Equal(false))
+
+			otherCd := cd
+			otherCd.Name = "github.com/test/my-other-component"
+			Expect(f.Matches(otherCd, res)).To(Equal(true))
+		})
+
+		It("should return error upon creation if a nested filter is invalid", func() {
+			spec := json.RawMessage(`{ "filters": [ { "type": "UnknownFilter", "spec": {} } ] }`)
+			_, err := filter.NewFilterFactory().Create(filter.OrFilterType, &spec)
+			Expect(err).To(MatchError("unable to create nested filter 0 of type UnknownFilter: unknown filter type UnknownFilter"))
+
+			spec = json.RawMessage(`{ "filter": { "type": "ResourceTypeFilter" } }`)
+			_, err = filter.NewFilterFactory().Create(filter.NotFilterType, &spec)
+			Expect(err).To(MatchError("unable to create nested filter of type ResourceTypeFilter: spec of filter type ResourceTypeFilter must not be empty"))
+		})
+
+	})
+
 })
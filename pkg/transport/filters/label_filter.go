@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+)
+
+type LabelFilterSpec struct {
+	// Name is the name of the label.
+	Name string `json:"name"`
+	// Value is the JSON value which the label value must be equal to.
+	// If a path is defined, the value selected by the path is compared.
+	// If empty, the filter matches every value.
+	Value *json.RawMessage `json:"value,omitempty"`
+	// Path is a JSONPath expression which selects a value from the label value, e.g. "$.teams[0].name".
+	// Only child (".key", "['key']") and array index ("[0]") operators are supported.
+	// If no value is defined, the filter matches if the path exists.
+	Path string `json:"path,omitempty"`
+}
+
+type labelFilter struct {
+	name  string
+	value interface{}
+	path  []pathSegment
+}
+
+func (f labelFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	for _, label := range r.Labels {
+		if label.Name != f.name {
+			continue
+		}
+
+		var labelValue interface{}
+		if err := json.Unmarshal(label.Value, &labelValue); err != nil {
+			continue
+		}
+
+		selected, ok := selectPath(labelValue, f.path)
+		if !ok {
+			continue
+		}
+
+		if f.value == nil || reflect.DeepEqual(selected, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewLabelFilter creates a new labelFilter
+func NewLabelFilter(spec LabelFilterSpec) (Filter, error) {
+	if len(spec.Name) == 0 {
+		return nil, fmt.Errorf("name must not be empty")
+	}
+
+	filter := labelFilter{
+		name: spec.Name,
+	}
+
+	if spec.Value != nil {
+		if err := json.Unmarshal(*spec.Value, &filter.value); err != nil {
+			return nil, fmt.Errorf("unable to parse value: %w", err)
+		}
+	}
+
+	if len(spec.Path) != 0 {
+		path, err := parsePath(spec.Path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse path %s: %w", spec.Path, err)
+		}
+		filter.path = path
+	}
+
+	return &filter, nil
+}
+
+// pathSegment is either a key of an object or an index of an array
+type pathSegment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// parsePath parses a JSONPath expression which consists of child and array index operators
+func parsePath(path string) ([]pathSegment, error) {
+	rest := strings.TrimPrefix(path, "$")
+	segments := []pathSegment{}
+
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key")
+			}
+			segments = append(segments, pathSegment{key: rest[:end], isKey: true})
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("missing closing bracket")
+			}
+			content := rest[1:end]
+			rest = rest[end+1:]
+
+			if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+				segments = append(segments, pathSegment{key: content[1 : len(content)-1], isKey: true})
+				continue
+			}
+
+			index, err := strconv.Atoi(content)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid array index %s", content)
+			}
+			segments = append(segments, pathSegment{index: index})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", rest[0])
+		}
+	}
+
+	return segments, nil
+}
+
+// selectPath returns the value which is selected by the path. The second return value is false
+// if the path doesn't exist in the value.
+func selectPath(value interface{}, path []pathSegment) (interface{}, bool) {
+	for _, segment := range path {
+		if segment.isKey {
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if value, ok = obj[segment.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+
+		arr, ok := value.([]interface{})
+		if !ok || segment.index >= len(arr) {
+			return nil, false
+		}
+		value = arr[segment.index]
+	}
+	return value, true
+}
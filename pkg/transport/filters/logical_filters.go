@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+)
+
+// FilterDefinition defines a filter which is nested in the spec of a logical filter
+type FilterDefinition struct {
+	Type string           `json:"type"`
+	Spec *json.RawMessage `json:"spec"`
+}
+
+type AndFilterSpec struct {
+	Filters []FilterDefinition `json:"filters"`
+}
+
+type OrFilterSpec struct {
+	Filters []FilterDefinition `json:"filters"`
+}
+
+type NotFilterSpec struct {
+	Filter *FilterDefinition `json:"filter"`
+}
+
+type andFilter struct {
+	filters []Filter
+}
+
+func (f andFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	for _, filter := range f.filters {
+		if !filter.Matches(cd, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewAndFilter creates a new andFilter which matches if all of the given filters match
+func NewAndFilter(filters ...Filter) (Filter, error) {
+	if len(filters) == 0 {
+		return nil, errors.New("filters must not be empty")
+	}
+
+	filter := andFilter{
+		filters: filters,
+	}
+	return &filter, nil
+}
+
+type orFilter struct {
+	filters []Filter
+}
+
+func (f orFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	for _, filter := range f.filters {
+		if filter.Matches(cd, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewOrFilter creates a new orFilter which matches if at least one of the given filters matches
+func NewOrFilter(filters ...Filter) (Filter, error) {
+	if len(filters) == 0 {
+		return nil, errors.New("filters must not be empty")
+	}
+
+	filter := orFilter{
+		filters: filters,
+	}
+	return &filter, nil
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func (f notFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	return !f.filter.Matches(cd, r)
+}
+
+// NewNotFilter creates a new notFilter which matches if the given filter doesn't match
+func NewNotFilter(filter Filter) (Filter, error) {
+	if filter == nil {
+		return nil, errors.New("filter must not be nil")
+	}
+
+	obj := notFilter{
+		filter: filter,
+	}
+	return &obj, nil
+}
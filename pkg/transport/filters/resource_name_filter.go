@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package filters
+
+import (
+	"fmt"
+	"regexp"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+)
+
+type ResourceNameFilterSpec struct {
+	IncludeResourceNames []string `json:"includeResourceNames"`
+}
+
+type resourceNameFilter struct {
+	includeResourceNames []*regexp.Regexp
+}
+
+func (f resourceNameFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	for _, irn := range f.includeResourceNames {
+		if irn.MatchString(r.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewResourceNameFilter creates a new resourceNameFilter
+func NewResourceNameFilter(spec ResourceNameFilterSpec) (Filter, error) {
+	if len(spec.IncludeResourceNames) == 0 {
+		return nil, fmt.Errorf("includeResourceNames must not be empty")
+	}
+
+	irnRegexps := []*regexp.Regexp{}
+	for _, irn := range spec.IncludeResourceNames {
+		irnRegexp, err := regexp.Compile(irn)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse regexp %s: %w", irn, err)
+		}
+		irnRegexps = append(irnRegexps, irnRegexp)
+	}
+
+	filter := resourceNameFilter{
+		includeResourceNames: irnRegexps,
+	}
+
+	return &filter, nil
+}
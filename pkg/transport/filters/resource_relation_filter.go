@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package filters
+
+import (
+	"fmt"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+)
+
+type ResourceRelationFilterSpec struct {
+	IncludeRelations []cdv2.ResourceRelation `json:"includeRelations"`
+}
+
+type resourceRelationFilter struct {
+	includeRelations map[cdv2.ResourceRelation]bool
+}
+
+func (f resourceRelationFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	if _, ok := f.includeRelations[r.Relation]; ok {
+		return true
+	}
+	return false
+}
+
+// NewResourceRelationFilter creates a new resourceRelationFilter
+func NewResourceRelationFilter(spec ResourceRelationFilterSpec) (Filter, error) {
+	if len(spec.IncludeRelations) == 0 {
+		return nil, fmt.Errorf("includeRelations must not be empty")
+	}
+
+	filter := resourceRelationFilter{
+		includeRelations: map[cdv2.ResourceRelation]bool{},
+	}
+
+	for _, relation := range spec.IncludeRelations {
+		if relation != cdv2.LocalRelation && relation != cdv2.ExternalRelation {
+			return nil, fmt.Errorf("invalid relation %s, must be one of %s, %s", relation, cdv2.LocalRelation, cdv2.ExternalRelation)
+		}
+		filter.includeRelations[relation] = true
+	}
+
+	return &filter, nil
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package filters
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+)
+
+type ResourceVersionFilterSpec struct {
+	// Constraint is a semver constraint, e.g. ">= 1.2.0, < 2.0.0"
+	Constraint string `json:"constraint"`
+}
+
+type resourceVersionFilter struct {
+	constraint *semver.Constraints
+}
+
+func (f resourceVersionFilter) Matches(cd cdv2.ComponentDescriptor, r cdv2.Resource) bool {
+	version, err := semver.NewVersion(r.Version)
+	if err != nil {
+		// resources without a valid semver version never match
+		return false
+	}
+	return f.constraint.Check(version)
+}
+
+// NewResourceVersionFilter creates a new resourceVersionFilter
+func NewResourceVersionFilter(spec ResourceVersionFilterSpec) (Filter, error) {
+	if len(spec.Constraint) == 0 {
+		return nil, fmt.Errorf("constraint must not be empty")
+	}
+
+	constraint, err := semver.NewConstraint(spec.Constraint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse constraint %s: %w", spec.Constraint, err)
+	}
+
+	filter := resourceVersionFilter{
+		constraint: constraint,
+	}
+
+	return &filter, nil
+}
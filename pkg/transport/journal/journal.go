@@ -0,0 +1,249 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package journal
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/go-logr/logr"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/gardener/component-cli/ociclient"
+)
+
+// Entry records a unit of work which has been completed successfully.
+type Entry struct {
+	// Key uniquely identifies the unit of work, see ComponentKey, ResourceKey, and ArtifactKey.
+	Key string `json:"key"`
+	// SourceDigest is the digest of the source artifact. The entry is only considered valid on resume
+	// if the source still has this digest.
+	// +optional
+	SourceDigest string `json:"sourceDigest,omitempty"`
+	// TargetRef is the reference of the uploaded artifact.
+	// +optional
+	TargetRef string `json:"targetRef,omitempty"`
+	// TargetDigest is the manifest digest of the uploaded artifact. If set, the entry is only
+	// considered valid on resume if the manifest of the target ref still has this digest.
+	// +optional
+	TargetDigest string `json:"targetDigest,omitempty"`
+	// Resource is the resource which has been returned by a processing pipeline.
+	// +optional
+	Resource *cdv2.Resource `json:"resource,omitempty"`
+}
+
+// Journal is a persistent, append-only record of completed work which allows to resume
+// interrupted transports and copies. Every entry is written as single json line and synced
+// to disk immediately, so that a journal stays readable even if the process is killed.
+type Journal struct {
+	mux     sync.Mutex
+	file    *os.File
+	entries map[string]Entry
+}
+
+// Open opens the journal file at the given path. If resume is true, the entries of an existing
+// journal are loaded. Otherwise an existing journal is truncated.
+func Open(path string, resume bool) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create journal directory: %w", err)
+	}
+
+	flags := os.O_CREATE | os.O_RDWR | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open journal file: %w", err)
+	}
+
+	j := Journal{
+		file:    file,
+		entries: map[string]Entry{},
+	}
+
+	if resume {
+		if err := j.load(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return &j, nil
+}
+
+func (j *Journal) load() error {
+	scanner := bufio.NewScanner(j.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// the last line might be incomplete if the process has been killed while writing it
+			continue
+		}
+		j.entries[entry.Key] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read journal file: %w", err)
+	}
+	return nil
+}
+
+// Record persists a completed unit of work.
+func (j *Journal) Record(entry Entry) error {
+	if len(entry.Key) == 0 {
+		return errors.New("key must not be empty")
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to marshal journal entry: %w", err)
+	}
+
+	j.mux.Lock()
+	defer j.mux.Unlock()
+
+	// start a new line in case the last line has been written only partially
+	if _, err := j.file.Write(append(append([]byte("\n"), data...), '\n')); err != nil {
+		return fmt.Errorf("unable to write journal entry: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("unable to sync journal file: %w", err)
+	}
+	j.entries[entry.Key] = entry
+	return nil
+}
+
+// Get returns the recorded entry for a key without verifying it.
+func (j *Journal) Get(key string) (Entry, bool) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	entry, ok := j.entries[key]
+	return entry, ok
+}
+
+// Lookup returns the recorded entry for a key if the work has been completed, the source is unchanged, and the
+// result is still present in the target. The recorded source digest is compared with the given current digest
+// of the source. If the entry contains a target digest, the current manifest digest of the target ref is fetched
+// and compared. Stale entries are removed from the journal.
+func (j *Journal) Lookup(ctx context.Context, client ociclient.Client, key, sourceDigest string) (*Entry, bool) {
+	entry, ok := j.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	log := logr.FromContextOrDiscard(ctx).WithValues("key", key, "targetRef", entry.TargetRef)
+	if entry.SourceDigest != sourceDigest {
+		log.V(3).Info("discard stale journal entry, source digest has changed", "expected", entry.SourceDigest, "actual", sourceDigest)
+		j.forget(key)
+		return nil, false
+	}
+
+	if len(entry.TargetDigest) != 0 {
+		desc, _, err := client.GetRawManifest(ctx, entry.TargetRef)
+		if err != nil {
+			log.V(3).Info("discard stale journal entry, unable to get target manifest", "error", err.Error())
+			j.forget(key)
+			return nil, false
+		}
+		if desc.Digest.String() != entry.TargetDigest {
+			log.V(3).Info("discard stale journal entry, target digest has changed", "expected", entry.TargetDigest, "actual", desc.Digest.String())
+			j.forget(key)
+			return nil, false
+		}
+	}
+
+	return &entry, true
+}
+
+// RecordManifest resolves the current manifest digest of the entry's target ref and persists the entry.
+func (j *Journal) RecordManifest(ctx context.Context, client ociclient.Client, entry Entry) error {
+	desc, _, err := client.GetRawManifest(ctx, entry.TargetRef)
+	if err != nil {
+		return fmt.Errorf("unable to get manifest of %s: %w", entry.TargetRef, err)
+	}
+	entry.TargetDigest = desc.Digest.String()
+	return j.Record(entry)
+}
+
+func (j *Journal) forget(key string) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	delete(j.entries, key)
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// ComponentKey returns the journal key of a component descriptor upload.
+func ComponentKey(name, version string) string {
+	return fmt.Sprintf("component/%s:%s", name, version)
+}
+
+// ResourceKey returns the journal key of a processed resource of a component descriptor.
+func ResourceKey(cd cdv2.ComponentDescriptor, res cdv2.Resource) string {
+	// the identity is marshaled as json to get a stable order of the identity attributes
+	id, err := json.Marshal(res.GetIdentity())
+	if err != nil {
+		id = []byte(res.Name)
+	}
+	return fmt.Sprintf("resource/%s:%s/%s", cd.Name, cd.Version, string(id))
+}
+
+// TargetKey returns the journal key of a component descriptor upload or processed resource for an additional target.
+// The key is returned unchanged for the main target, which is identified by an empty target.
+func TargetKey(key, target string) string {
+	if len(target) == 0 {
+		return key
+	}
+	return fmt.Sprintf("%s@%s", key, target)
+}
+
+// ArtifactKey returns the journal key of an oci artifact copy.
+func ArtifactKey(srcRef, targetRef string) string {
+	return fmt.Sprintf("artifact/%s->%s", srcRef, targetRef)
+}
+
+// ComponentDescriptorDigest returns the digest of the json representation of a component descriptor,
+// which is recorded as source digest if the source of a component descriptor has no manifest digest.
+func ComponentDescriptorDigest(cd *cdv2.ComponentDescriptor) (string, error) {
+	data, err := json.Marshal(cd)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal component descriptor: %w", err)
+	}
+	return digest.FromBytes(data).String(), nil
+}
+
+// ResourceDigest returns the digest of the json representation of a resource,
+// which is recorded as source digest if the source of a resource has no digest, e.g. a file on a web server.
+func ResourceDigest(res cdv2.Resource) (string, error) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal resource: %w", err)
+	}
+	return digest.FromBytes(data).String(), nil
+}
+
+// DefaultPath returns the default location of a journal in the given directory.
+// The filename is derived from the given parts, e.g. the arguments of a command.
+func DefaultPath(dir string, parts ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return filepath.Join(dir, "journals", hex.EncodeToString(h[:8])+".jsonl")
+}
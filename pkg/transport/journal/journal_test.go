@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package journal_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	mock_ociclient "github.com/gardener/component-cli/ociclient/mock"
+	"github.com/gardener/component-cli/pkg/transport/journal"
+)
+
+var _ = Describe("Journal", func() {
+
+	var (
+		dir        string
+		path       string
+		mockCtrl   *gomock.Controller
+		mockClient *mock_ociclient.MockClient
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "journal-")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(dir, "journal.jsonl")
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_ociclient.NewMockClient(mockCtrl)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should load recorded entries on resume", func() {
+		res := cdv2.Resource{
+			IdentityObjectMeta: cdv2.IdentityObjectMeta{
+				Name:    "res",
+				Version: "0.1.0",
+			},
+		}
+		j, err := journal.Open(path, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(j.Record(journal.Entry{Key: "a", Resource: &res})).To(Succeed())
+		Expect(j.Record(journal.Entry{Key: "b"})).To(Succeed())
+		Expect(j.Close()).To(Succeed())
+
+		j, err = journal.Open(path, true)
+		Expect(err).ToNot(HaveOccurred())
+		defer j.Close()
+
+		entry, ok := j.Lookup(context.TODO(), mockClient, "a", "")
+		Expect(ok).To(BeTrue())
+		Expect(entry.Resource).To(Equal(&res))
+		_, ok = j.Lookup(context.TODO(), mockClient, "b", "")
+		Expect(ok).To(BeTrue())
+		_, ok = j.Lookup(context.TODO(), mockClient, "c", "")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should discard recorded entries if resume is disabled", func() {
+		j, err := journal.Open(path, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(j.Record(journal.Entry{Key: "a"})).To(Succeed())
+		Expect(j.Close()).To(Succeed())
+
+		j, err = journal.Open(path, false)
+		Expect(err).ToNot(HaveOccurred())
+		defer j.Close()
+		_, ok := j.Get("a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should ignore a partially written entry", func() {
+		j, err := journal.Open(path, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(j.Record(journal.Entry{Key: "a"})).To(Succeed())
+		Expect(j.Close()).To(Succeed())
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.WriteString(`{"key":"b","targetR`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		j, err = journal.Open(path, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(j.Record(journal.Entry{Key: "c"})).To(Succeed())
+		Expect(j.Close()).To(Succeed())
+
+		j, err = journal.Open(path, true)
+		Expect(err).ToNot(HaveOccurred())
+		defer j.Close()
+		_, ok := j.Get("a")
+		Expect(ok).To(BeTrue())
+		_, ok = j.Get("b")
+		Expect(ok).To(BeFalse())
+		_, ok = j.Get("c")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should detect stale entries by rechecking the target digest", func() {
+		ref := "example.com/target/image:0.1.0"
+		recorded := digest.FromString("recorded")
+		changed := digest.FromString("changed")
+
+		j, err := journal.Open(path, false)
+		Expect(err).ToNot(HaveOccurred())
+		defer j.Close()
+
+		mockClient.EXPECT().GetRawManifest(gomock.Any(), ref).Return(ocispecv1.Descriptor{Digest: recorded}, nil, nil)
+		Expect(j.RecordManifest(context.TODO(), mockClient, journal.Entry{Key: "unchanged", TargetRef: ref})).To(Succeed())
+		Expect(j.Record(journal.Entry{Key: "changed", TargetRef: ref, TargetDigest: recorded.String()})).To(Succeed())
+		Expect(j.Record(journal.Entry{Key: "deleted", TargetRef: ref, TargetDigest: recorded.String()})).To(Succeed())
+
+		mockClient.EXPECT().GetRawManifest(gomock.Any(), ref).Return(ocispecv1.Descriptor{Digest: recorded}, nil, nil)
+		entry, ok := j.Lookup(context.TODO(), mockClient, "unchanged", "")
+		Expect(ok).To(BeTrue())
+		Expect(entry.TargetDigest).To(Equal(recorded.String()))
+
+		mockClient.EXPECT().GetRawManifest(gomock.Any(), ref).Return(ocispecv1.Descriptor{Digest: changed}, nil, nil)
+		_, ok = j.Lookup(context.TODO(), mockClient, "changed", "")
+		Expect(ok).To(BeFalse())
+
+		mockClient.EXPECT().GetRawManifest(gomock.Any(), ref).Return(ocispecv1.Descriptor{}, nil, errors.New("not found"))
+		_, ok = j.Lookup(context.TODO(), mockClient, "deleted", "")
+		Expect(ok).To(BeFalse())
+
+		// stale entries are removed and not checked again
+		_, ok = j.Get("changed")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should detect stale entries by comparing the source digest", func() {
+		ref := "example.com/target/image:0.1.0"
+		source := digest.FromString("source")
+		target := digest.FromString("target")
+
+		j, err := journal.Open(path, false)
+		Expect(err).ToNot(HaveOccurred())
+		defer j.Close()
+		Expect(j.Record(journal.Entry{Key: "unchanged", SourceDigest: source.String(), TargetRef: ref, TargetDigest: target.String()})).To(Succeed())
+		Expect(j.Record(journal.Entry{Key: "changed", SourceDigest: source.String(), TargetRef: ref, TargetDigest: target.String()})).To(Succeed())
+
+		mockClient.EXPECT().GetRawManifest(gomock.Any(), ref).Return(ocispecv1.Descriptor{Digest: target}, nil, nil)
+		entry, ok := j.Lookup(context.TODO(), mockClient, "unchanged", source.String())
+		Expect(ok).To(BeTrue())
+		Expect(entry.SourceDigest).To(Equal(source.String()))
+
+		// the target is not checked if the source has changed
+		_, ok = j.Lookup(context.TODO(), mockClient, "changed", digest.FromString("changed").String())
+		Expect(ok).To(BeFalse())
+		_, ok = j.Get("changed")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should derive a stable default path from the arguments", func() {
+		Expect(journal.DefaultPath("/cache", "a", "b")).To(Equal(journal.DefaultPath("/cache", "a", "b")))
+		Expect(journal.DefaultPath("/cache", "a", "b")).ToNot(Equal(journal.DefaultPath("/cache", "ab")))
+		Expect(filepath.Dir(journal.DefaultPath("/cache", "a"))).To(Equal("/cache/journals"))
+	})
+
+	It("should derive distinct keys for additional targets", func() {
+		key := journal.ComponentKey("example.com/a", "v0.1.0")
+		Expect(journal.TargetKey(key, "")).To(Equal(key))
+		Expect(journal.TargetKey(key, "example.com/us")).To(Equal(key + "@example.com/us"))
+	})
+
+})
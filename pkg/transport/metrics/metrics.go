@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	transportNamespaceName = "transport"
+	processorSubsystemName = "processor"
+)
+
+const (
+	// DownloaderKind is the kind label value of downloaders.
+	DownloaderKind = "downloader"
+	// ProcessorKind is the kind label value of processors.
+	ProcessorKind = "processor"
+	// UploaderKind is the kind label value of uploaders.
+	UploaderKind = "uploader"
+)
+
+var (
+	// ResourcesProcessed discloses the number of resources which have been processed successfully by a processor type
+	ResourcesProcessed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: transportNamespaceName,
+			Subsystem: processorSubsystemName,
+			Name:      "resources_processed_total",
+			Help:      "Total number of resources which have been processed successfully by a processor type.",
+		},
+		[]string{"kind", "type"},
+	)
+
+	// ProcessorDuration discloses the duration of processor runs
+	ProcessorDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: transportNamespaceName,
+			Subsystem: processorSubsystemName,
+			Name:      "duration_seconds",
+			Help:      "Duration of single processor runs, including failed runs.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+		},
+		[]string{"kind", "type"},
+	)
+
+	// ProcessorRetries discloses the number of retried processor runs
+	ProcessorRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: transportNamespaceName,
+			Subsystem: processorSubsystemName,
+			Name:      "retries_total",
+			Help:      "Total number of processor runs which are retries of a failed run.",
+		},
+		[]string{"kind", "type"},
+	)
+
+	// ProcessorFailures discloses the number of failed processor runs
+	ProcessorFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: transportNamespaceName,
+			Subsystem: processorSubsystemName,
+			Name:      "failures_total",
+			Help:      "Total number of failed processor runs.",
+		},
+		[]string{"kind", "type"},
+	)
+
+	// DownloadedBytes discloses the number of bytes written by downloaders
+	DownloadedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: transportNamespaceName,
+			Name:      "downloaded_bytes_total",
+			Help:      "Total number of bytes of the processor messages written by downloaders.",
+		},
+		[]string{"type"},
+	)
+
+	// UploadedBytes discloses the number of bytes read by uploaders
+	UploadedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: transportNamespaceName,
+			Name:      "uploaded_bytes_total",
+			Help:      "Total number of bytes of the processor messages read by uploaders.",
+		},
+		[]string{"type"},
+	)
+)
+
+// RegisterTransportMetrics allows to register transport metrics with a given prometheus registerer
+func RegisterTransportMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(ResourcesProcessed)
+	reg.MustRegister(ProcessorDuration)
+	reg.MustRegister(ProcessorRetries)
+	reg.MustRegister(ProcessorFailures)
+	reg.MustRegister(DownloadedBytes)
+	reg.MustRegister(UploadedBytes)
+}
+
+// WriteTextFile writes the metrics of a gatherer in the prometheus text exposition format to a file.
+func WriteTextFile(fs vfs.FileSystem, path string, g prometheus.Gatherer) error {
+	families, err := g.Gather()
+	if err != nil {
+		return fmt.Errorf("unable to gather metrics: %w", err)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(buf, family); err != nil {
+			return fmt.Errorf("unable to encode metrics: %w", err)
+		}
+	}
+
+	if err := vfs.WriteFile(fs, path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("unable to write metrics file %s: %w", path, err)
+	}
+	return nil
+}
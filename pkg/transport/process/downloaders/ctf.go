@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package downloaders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+type ctfDownloader struct {
+	ctf *utils.CTFReader
+}
+
+// NewCTFDownloader creates a new downloader which reads local blobs from the component archives of a source CTF.
+// If a resource has been converted by the CTF uploader, the original access is restored from the label
+// "transport.gardener.cloud/original-access", so that e.g. oci artifacts can be uploaded with an oci
+// artifact uploader.
+func NewCTFDownloader(sourceCTF *utils.CTFReader) (process.ResourceStreamProcessor, error) {
+	if sourceCTF == nil {
+		return nil, errors.New("sourceCTF must not be nil")
+	}
+
+	obj := ctfDownloader{
+		ctf: sourceCTF,
+	}
+	return &obj, nil
+}
+
+func (d *ctfDownloader) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, _, err := utils.ReadProcessorMessage(r)
+	if err != nil {
+		return fmt.Errorf("unable to read processor message: %w", err)
+	}
+
+	if res.Access.GetType() != cdv2.LocalFilesystemBlobType {
+		return fmt.Errorf("unsupported access type: %s", res.Access.Type)
+	}
+
+	ca, err := d.ctf.ComponentArchive(cd.Name, cd.Version)
+	if err != nil {
+		return fmt.Errorf("unable to get component archive %s:%s: %w", cd.Name, cd.Version, err)
+	}
+
+	blob, err := utils.NewTempFile()
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	if _, err := ca.BlobResolver.Resolve(ctx, res, blob); err != nil {
+		return fmt.Errorf("unable to resolve blob: %w", err)
+	}
+	if _, err := blob.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to seek to beginning of tempfile: %w", err)
+	}
+
+	for i, label := range res.Labels {
+		if label.Name != utils.OriginalAccessLabel {
+			continue
+		}
+		originalAccess := &cdv2.UnstructuredTypedObject{}
+		if err := json.Unmarshal(label.Value, originalAccess); err != nil {
+			return fmt.Errorf("unable to decode original access: %w", err)
+		}
+		res.Access = originalAccess
+		res.Labels = append(res.Labels[:i], res.Labels[i+1:]...)
+		break
+	}
+
+	if err := utils.WriteProcessorMessage(*cd, res, blob, w); err != nil {
+		return fmt.Errorf("unable to write processor message: %w", err)
+	}
+
+	return nil
+}
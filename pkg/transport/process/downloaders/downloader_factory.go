@@ -5,12 +5,19 @@ package downloaders
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
 
 	"github.com/gardener/component-cli/ociclient"
 	"github.com/gardener/component-cli/ociclient/cache"
 	"github.com/gardener/component-cli/pkg/transport/process"
 	"github.com/gardener/component-cli/pkg/transport/process/extensions"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/transport/s3"
 )
 
 const (
@@ -19,6 +26,15 @@ const (
 
 	// OCIArtifactDownloaderType defines the type of an oci artifact downloader
 	OCIArtifactDownloaderType = "OciArtifactDownloader"
+
+	// CTFDownloaderType defines the type of a CTF downloader
+	CTFDownloaderType = "CtfDownloader"
+
+	// S3DownloaderType defines the type of an S3 downloader
+	S3DownloaderType = "S3Downloader"
+
+	// WebDownloaderType defines the type of a web downloader
+	WebDownloaderType = "WebDownloader"
 )
 
 // NewDownloaderFactory creates a new downloader factory
@@ -28,15 +44,25 @@ const (
 // - Add source code for creating new downloader to DownloaderFactory.Create() method
 func NewDownloaderFactory(client ociclient.Client, ocicache cache.Cache) *DownloaderFactory {
 	return &DownloaderFactory{
-		client: client,
-		cache:  ocicache,
+		client:     client,
+		cache:      ocicache,
+		httpClient: http.DefaultClient,
 	}
 }
 
 // DownloaderFactory defines a helper struct for creating downloaders
 type DownloaderFactory struct {
-	client ociclient.Client
-	cache  cache.Cache
+	client     ociclient.Client
+	cache      cache.Cache
+	sourceCTF  *processutils.CTFReader
+	httpClient *http.Client
+}
+
+// WithSourceCTF returns a copy of the factory whose CTF downloaders read from a source CTF.
+func (f *DownloaderFactory) WithSourceCTF(sourceCTF *processutils.CTFReader) *DownloaderFactory {
+	factory := *f
+	factory.sourceCTF = sourceCTF
+	return &factory
 }
 
 // Create creates a new downloader defined by a type and a spec
@@ -46,9 +72,75 @@ func (f *DownloaderFactory) Create(downloaderType string, spec *json.RawMessage)
 		return NewLocalOCIBlobDownloader(f.client)
 	case OCIArtifactDownloaderType:
 		return NewOCIArtifactDownloader(f.client, f.cache)
+	case CTFDownloaderType:
+		if f.sourceCTF == nil {
+			return nil, errors.New("a ctf downloader requires a source ctf")
+		}
+		return NewCTFDownloader(f.sourceCTF)
+	case S3DownloaderType:
+		return f.createS3Downloader(spec)
+	case WebDownloaderType:
+		return NewWebDownloader(f.httpClient)
 	case extensions.ExecutableType:
 		return extensions.CreateExecutable(spec)
 	default:
 		return nil, fmt.Errorf("unknown downloader type %s", downloaderType)
 	}
 }
+
+func (f *DownloaderFactory) createS3Downloader(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
+	spec, err := parseS3DownloaderSpec(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s3.NewClient(f.httpClient, *spec, s3.CredentialsFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create s3 client: %w", err)
+	}
+
+	return NewS3Downloader(client)
+}
+
+// parseS3DownloaderSpec parses the optional spec of an S3 downloader.
+// Credentials are read from the environment variables AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN.
+func parseS3DownloaderSpec(rawSpec *json.RawMessage) (*s3.Config, error) {
+	var spec s3.Config
+	if rawSpec == nil {
+		return &spec, nil
+	}
+
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if spec.Endpoint != "" {
+		if _, err := s3.ParseEndpoint(spec.Endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	return &spec, nil
+}
+
+// Validate validates the type and spec of a downloader definition without creating the downloader
+func Validate(fldPath *field.Path, downloaderType string, spec *json.RawMessage) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch downloaderType {
+	case LocalOCIBlobDownloaderType, OCIArtifactDownloaderType, WebDownloaderType:
+	case CTFDownloaderType:
+		if spec != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(*spec), "a ctf downloader has no spec, it reads from the source ctf of the transport"))
+		}
+	case S3DownloaderType:
+		if _, err := parseS3DownloaderSpec(spec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(*spec), err.Error()))
+		}
+	case extensions.ExecutableType:
+		allErrs = append(allErrs, extensions.ValidateExecutable(fldPath.Child("spec"), spec)...)
+	default:
+		supportedTypes := []string{LocalOCIBlobDownloaderType, OCIArtifactDownloaderType, CTFDownloaderType, S3DownloaderType, WebDownloaderType, extensions.ExecutableType}
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), downloaderType, supportedTypes))
+	}
+	return allErrs
+}
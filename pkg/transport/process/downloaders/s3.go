@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package downloaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/transport/s3"
+)
+
+type s3Downloader struct {
+	client *s3.Client
+}
+
+// NewS3Downloader creates a new downloader which reads resources with access type s3 from an S3 compatible object store.
+func NewS3Downloader(client *s3.Client) (process.ResourceStreamProcessor, error) {
+	if client == nil {
+		return nil, errors.New("client must not be nil")
+	}
+
+	obj := s3Downloader{
+		client: client,
+	}
+	return &obj, nil
+}
+
+func (d *s3Downloader) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, _, err := utils.ReadProcessorMessage(r)
+	if err != nil {
+		return fmt.Errorf("unable to read processor message: %w", err)
+	}
+
+	if res.Access.GetType() != cdv2.S3AccessType {
+		return fmt.Errorf("unsupported access type: %s", res.Access.Type)
+	}
+
+	s3Access := &cdv2.S3Access{}
+	if err := res.Access.DecodeInto(s3Access); err != nil {
+		return fmt.Errorf("unable to decode resource access: %w", err)
+	}
+
+	blob, err := d.client.GetObject(ctx, s3Access.BucketName, s3Access.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("unable to download object: %w", err)
+	}
+	defer blob.Close()
+
+	if err := utils.WriteProcessorMessage(*cd, res, blob, w); err != nil {
+		return fmt.Errorf("unable to write processor message: %w", err)
+	}
+
+	return nil
+}
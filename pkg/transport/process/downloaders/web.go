@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package downloaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+type webDownloader struct {
+	client *http.Client
+}
+
+// NewWebDownloader creates a new downloader which fetches resources with access type web via http(s) GET requests.
+func NewWebDownloader(client *http.Client) (process.ResourceStreamProcessor, error) {
+	if client == nil {
+		return nil, errors.New("client must not be nil")
+	}
+
+	obj := webDownloader{
+		client: client,
+	}
+	return &obj, nil
+}
+
+func (d *webDownloader) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, _, err := utils.ReadProcessorMessage(r)
+	if err != nil {
+		return fmt.Errorf("unable to read processor message: %w", err)
+	}
+
+	if res.Access.GetType() != cdv2.WebType {
+		return fmt.Errorf("unsupported access type: %s", res.Access.Type)
+	}
+
+	webAccess := &cdv2.Web{}
+	if err := res.Access.DecodeInto(webAccess); err != nil {
+		return fmt.Errorf("unable to decode resource access: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webAccess.URL, nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request for %s: %w", webAccess.URL, err)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to download %s: %w", webAccess.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download %s, response code %d", webAccess.URL, resp.StatusCode)
+	}
+
+	if err := utils.WriteProcessorMessage(*cd, res, resp.Body, w); err != nil {
+		return fmt.Errorf("unable to write processor message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DefaultConcurrency is the default number of resources which are processed in parallel.
+const DefaultConcurrency = 10
+
+// ProcessingJob defines a resource of a component descriptor and the pipeline which should process it.
+type ProcessingJob struct {
+	ComponentDescriptor *cdv2.ComponentDescriptor
+	Resource            cdv2.Resource
+	Pipeline            ResourceProcessingPipeline
+}
+
+// ProcessingResult contains the outcome of a processing job.
+type ProcessingResult struct {
+	Job *ProcessingJob
+	// ProcessedComponentDescriptor is the component descriptor returned by the last processor.
+	ProcessedComponentDescriptor *cdv2.ComponentDescriptor
+	// ProcessedResource is the resource returned by the last processor.
+	ProcessedResource cdv2.Resource
+	// Error is the error that occurred during processing, nil on success.
+	Error error
+}
+
+// Executor executes processing jobs on a bounded number of concurrent workers.
+type Executor struct {
+	concurrency int
+}
+
+// NewExecutor creates a new Executor which processes at most concurrency jobs in parallel.
+func NewExecutor(concurrency int) (*Executor, error) {
+	if concurrency < 1 {
+		return nil, errors.New("concurrency must be greater than 0")
+	}
+
+	obj := Executor{
+		concurrency: concurrency,
+	}
+	return &obj, nil
+}
+
+// Execute runs all jobs and returns one result per job in the order of the jobs.
+// A failing job does not stop the processing of other jobs. If the context is cancelled,
+// all in-flight processors are cancelled and jobs which have not been started yet fail with
+// the context error. The returned error aggregates the errors of all failed jobs.
+func (e *Executor) Execute(ctx context.Context, jobs []ProcessingJob) ([]ProcessingResult, error) {
+	results := make([]ProcessingResult, len(jobs))
+	jobIndices := make(chan int)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < e.concurrency && i < len(jobs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobIndices {
+				results[idx] = runJob(ctx, &jobs[idx])
+			}
+		}()
+	}
+
+	for i := range jobs {
+		jobIndices <- i
+	}
+	close(jobIndices)
+	wg.Wait()
+
+	errs := []error{}
+	for _, result := range results {
+		if result.Error != nil {
+			errs = append(errs, result.Error)
+		}
+	}
+
+	return results, utilerrors.NewAggregate(errs)
+}
+
+func runJob(ctx context.Context, job *ProcessingJob) ProcessingResult {
+	result := ProcessingResult{
+		Job: job,
+	}
+
+	if err := ctx.Err(); err != nil {
+		result.Error = fmt.Errorf("unable to process resource %s of component %s:%s: %w", job.Resource.Name, job.ComponentDescriptor.Name, job.ComponentDescriptor.Version, err)
+		return result
+	}
+
+	cd, res, err := job.Pipeline.Process(ctx, *job.ComponentDescriptor, job.Resource)
+	if err != nil {
+		result.Error = fmt.Errorf("unable to process resource %s of component %s:%s: %w", job.Resource.Name, job.ComponentDescriptor.Name, job.ComponentDescriptor.Version, err)
+		return result
+	}
+
+	result.ProcessedComponentDescriptor = cd
+	result.ProcessedResource = res
+	return result
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package process_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+)
+
+var _ = Describe("executor", func() {
+
+	Context("Execute", func() {
+
+		It("should not process more jobs in parallel than the configured concurrency", func() {
+			const concurrency = 3
+			pipeline := &countingPipeline{}
+			jobs := createJobs(20, pipeline)
+
+			executor, err := process.NewExecutor(concurrency)
+			Expect(err).ToNot(HaveOccurred())
+
+			results, err := executor.Execute(context.TODO(), jobs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(len(jobs)))
+			for i, result := range results {
+				Expect(result.Error).ToNot(HaveOccurred())
+				Expect(result.ProcessedResource.Name).To(Equal(jobs[i].Resource.Name))
+			}
+			Expect(pipeline.maxActive).To(Equal(concurrency))
+		})
+
+		It("should collect the errors of all failed jobs", func() {
+			jobs := createJobs(5, &countingPipeline{})
+			jobs[1].Pipeline = failingPipeline{}
+			jobs[3].Pipeline = failingPipeline{}
+
+			executor, err := process.NewExecutor(2)
+			Expect(err).ToNot(HaveOccurred())
+
+			results, err := executor.Execute(context.TODO(), jobs)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("res-1"))
+			Expect(err.Error()).To(ContainSubstring("res-3"))
+
+			for i, result := range results {
+				if i == 1 || i == 3 {
+					Expect(result.Error).To(HaveOccurred())
+				} else {
+					Expect(result.Error).ToNot(HaveOccurred())
+				}
+			}
+		})
+
+		It("should cancel in-flight jobs if the context is cancelled", func() {
+			jobs := createJobs(10, blockingPipeline{})
+
+			executor, err := process.NewExecutor(2)
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx, cancelfunc := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+			defer cancelfunc()
+
+			results, err := executor.Execute(ctx, jobs)
+			Expect(err).To(HaveOccurred())
+			for _, result := range results {
+				Expect(errors.Is(result.Error, context.DeadlineExceeded)).To(BeTrue())
+			}
+		})
+
+		It("should return an error upon creation if concurrency is less than 1", func() {
+			_, err := process.NewExecutor(0)
+			Expect(err).To(MatchError("concurrency must be greater than 0"))
+		})
+
+	})
+})
+
+func createJobs(n int, pipeline process.ResourceProcessingPipeline) []process.ProcessingJob {
+	cd := cdv2.ComponentDescriptor{
+		ComponentSpec: cdv2.ComponentSpec{
+			ObjectMeta: cdv2.ObjectMeta{
+				Name:    "github.com/component-cli/test-component",
+				Version: "0.1.0",
+			},
+		},
+	}
+
+	jobs := []process.ProcessingJob{}
+	for i := 0; i < n; i++ {
+		jobs = append(jobs, process.ProcessingJob{
+			ComponentDescriptor: &cd,
+			Resource: cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    fmt.Sprintf("res-%d", i),
+					Version: "0.1.0",
+				},
+			},
+			Pipeline: pipeline,
+		})
+	}
+	return jobs
+}
+
+type countingPipeline struct {
+	mux       sync.Mutex
+	active    int
+	maxActive int
+}
+
+func (p *countingPipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	p.mux.Lock()
+	p.active++
+	if p.active > p.maxActive {
+		p.maxActive = p.active
+	}
+	p.mux.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	p.mux.Lock()
+	p.active--
+	p.mux.Unlock()
+
+	return &cd, res, nil
+}
+
+type failingPipeline struct{}
+
+func (p failingPipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	return nil, cdv2.Resource{}, errors.New("processing failed")
+}
+
+type blockingPipeline struct{}
+
+func (p blockingPipeline) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	<-ctx.Done()
+	return nil, cdv2.Resource{}, ctx.Err()
+}
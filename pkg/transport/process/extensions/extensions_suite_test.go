@@ -7,16 +7,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/gardener/component-cli/pkg/transport/process"
 	"github.com/gardener/component-cli/pkg/transport/process/extensions"
@@ -43,6 +48,10 @@ var _ = BeforeSuite(func() {
 	Expect(err).ToNot(HaveOccurred(), sleepProcessorBinaryPath+" doesn't exists. pls run make install-requirements.")
 }, 5)
 
+var _ = AfterSuite(func() {
+	Expect(extensions.StopWorkers()).To(Succeed())
+}, 15)
+
 var _ = Describe("transport extensions", func() {
 
 	Context("stdio executable", func() {
@@ -51,83 +60,179 @@ var _ = Describe("transport extensions", func() {
 			_, err := extensions.NewStdIOExecutable(exampleProcessorBinaryPath, args, nil)
 			Expect(err).ToNot(HaveOccurred())
 		})
+	})
 
-		It("should modify the processed resource correctly", func() {
+	Context("unix domain socket executable", func() {
+		It("should create processor successfully if env is nil", func() {
 			args := []string{}
-			env := map[string]string{}
-			processor, err := extensions.NewStdIOExecutable(exampleProcessorBinaryPath, args, env)
+			_, err := extensions.NewUnixDomainSocketExecutable(exampleProcessorBinaryPath, args, nil)
 			Expect(err).ToNot(HaveOccurred())
-
-			runExampleResourceTest(processor)
 		})
 
-		It("should exit with error when timeout is reached", func() {
+		It("should raise an error when trying to set the server address env variable manually", func() {
 			args := []string{}
 			env := map[string]string{
-				sleepTimeEnv: sleepTime.String(),
+				extensions.ProcessorServerAddressEnv: "/tmp/my-processor.sock",
 			}
-			processor, err := extensions.NewStdIOExecutable(sleepProcessorBinaryPath, args, env)
-			Expect(err).ToNot(HaveOccurred())
-
-			runTimeoutTest(processor)
+			_, err := extensions.NewUnixDomainSocketExecutable(exampleProcessorBinaryPath, args, env)
+			Expect(err).To(MatchError(fmt.Sprintf("the env variable %s is not allowed to be set manually", extensions.ProcessorServerAddressEnv)))
 		})
 	})
 
-	Context("unix domain socket executable", func() {
-		It("should create processor successfully if env is nil", func() {
+	Context("grpc executable", func() {
+		It("should raise an error when trying to set the server env variables manually", func() {
 			args := []string{}
-			_, err := extensions.NewUnixDomainSocketExecutable(exampleProcessorBinaryPath, args, nil)
+			for _, key := range []string{extensions.ProcessorServerAddressEnv, extensions.ProcessorServerProtocolEnv} {
+				env := map[string]string{
+					key: "my-value",
+				}
+				_, err := extensions.NewGRPCExecutable(exampleProcessorBinaryPath, args, env)
+				Expect(err).To(MatchError(fmt.Sprintf("the env variable %s is not allowed to be set manually", key)))
+			}
+		})
+
+		It("should process resources concurrently with a shared worker", func() {
+			const resources = 10
+
+			wg := sync.WaitGroup{}
+			for i := 0; i < resources; i++ {
+				processor, err := extensions.NewGRPCExecutable(exampleProcessorBinaryPath, []string{}, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					runExampleResourceTest(processor, fmt.Sprintf("resource-%d", i))
+				}(i)
+			}
+			wg.Wait()
+		})
+
+		It("should release the stream if the output cannot be written", func() {
+			processor, err := extensions.NewGRPCExecutable(exampleProcessorBinaryPath, []string{}, nil)
 			Expect(err).ToNot(HaveOccurred())
+			runExampleResourceTest(processor, "12345")
+			goroutines := runtime.NumGoroutine()
+
+			for i := 0; i < 5; i++ {
+				inputBuf := bytes.NewBuffer([]byte{})
+				Expect(utils.WriteProcessorMessage(cdv2.ComponentDescriptor{}, cdv2.Resource{}, strings.NewReader("12345"), inputBuf)).To(Succeed())
+				err = processor.Process(context.TODO(), inputBuf, &failingWriter{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("unable to read output"))
+			}
+
+			// the goroutines of the streams are stopped as soon as the streams are cancelled
+			Eventually(runtime.NumGoroutine, 5*time.Second).Should(BeNumerically("<=", goroutines))
 		})
 
-		It("should modify the processed resource correctly", func() {
-			args := []string{}
-			env := map[string]string{}
-			processor, err := extensions.NewUnixDomainSocketExecutable(exampleProcessorBinaryPath, args, env)
+		It("should restart the worker after it has been stopped", func() {
+			processor, err := extensions.NewGRPCExecutable(exampleProcessorBinaryPath, []string{}, nil)
 			Expect(err).ToNot(HaveOccurred())
+			runExampleResourceTest(processor, "12345")
 
-			runExampleResourceTest(processor)
+			Expect(extensions.StopWorkers()).To(Succeed())
+			runExampleResourceTest(processor, "12345")
 		})
+	})
 
-		It("should raise an error when trying to set the server address env variable manually", func() {
-			args := []string{}
-			env := map[string]string{
-				extensions.ProcessorServerAddressEnv: "/tmp/my-processor.sock",
-			}
-			_, err := extensions.NewUnixDomainSocketExecutable(exampleProcessorBinaryPath, args, env)
-			Expect(err).To(MatchError(fmt.Sprintf("the env variable %s is not allowed to be set manually", extensions.ProcessorServerAddressEnv)))
+	Context("CreateExecutable", func() {
+		It("should raise an error if the protocol is unknown", func() {
+			spec := json.RawMessage(`{"bin": "` + exampleProcessorBinaryPath + `", "protocol": "http"}`)
+			_, err := extensions.CreateExecutable(&spec)
+			Expect(err).To(MatchError("unknown protocol http, must be one of stdio, uds, grpc"))
 		})
 
-		It("should exit with error when timeout is reached", func() {
-			args := []string{}
-			env := map[string]string{
-				sleepTimeEnv: sleepTime.String(),
-			}
-			processor, err := extensions.NewUnixDomainSocketExecutable(sleepProcessorBinaryPath, args, env)
+		It("should raise an error if bin is empty", func() {
+			spec := json.RawMessage(`{"protocol": "stdio"}`)
+			_, err := extensions.CreateExecutable(&spec)
+			Expect(err).To(MatchError("bin must not be empty"))
+		})
+
+		It("should use unix domain sockets if no protocol is defined", func() {
+			spec := json.RawMessage(`{"bin": "` + exampleProcessorBinaryPath + `"}`)
+			processor, err := extensions.CreateExecutable(&spec)
 			Expect(err).ToNot(HaveOccurred())
 
-			runTimeoutTest(processor)
+			runExampleResourceTest(processor, "12345")
 		})
 	})
 
+	for _, protocol := range extensions.Protocols {
+		describeConformance(protocol)
+	}
+
 })
 
-func runTimeoutTest(processor process.ResourceStreamProcessor) {
+// describeConformance defines the tests which the reference extension must pass with every protocol.
+func describeConformance(protocol string) {
+	Context("conformance of protocol "+protocol, func() {
+
+		createExecutable := func(bin string, env map[string]string) process.ResourceStreamProcessor {
+			spec := struct {
+				Bin      string            `json:"bin"`
+				Env      map[string]string `json:"env"`
+				Protocol string            `json:"protocol"`
+			}{
+				Bin:      bin,
+				Env:      env,
+				Protocol: protocol,
+			}
+			rawSpec, err := json.Marshal(spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			processor, err := extensions.CreateExecutable((*json.RawMessage)(&rawSpec))
+			Expect(err).ToNot(HaveOccurred())
+			return processor
+		}
+
+		It("should modify the processed resource correctly", func() {
+			processor := createExecutable(exampleProcessorBinaryPath, map[string]string{})
+			runExampleResourceTest(processor, "12345")
+		})
+
+		It("should process a resource blob which is larger than the pipe buffers", func() {
+			processor := createExecutable(exampleProcessorBinaryPath, map[string]string{})
+			runExampleResourceTest(processor, strings.Repeat("0123456789", 512*1024))
+		})
+
+		It("should return an error if the processor fails", func() {
+			processor := createExecutable(sleepProcessorBinaryPath, map[string]string{
+				sleepTimeEnv: "0s",
+			})
+			err := processor.Process(context.TODO(), bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{}))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should exit with error when timeout is reached", func() {
+			processor := createExecutable(sleepProcessorBinaryPath, map[string]string{
+				sleepTimeEnv: sleepTime.String(),
+			})
+			err := runTimeoutTest(processor)
+			if protocol == extensions.GRPCProtocol {
+				// the long-lived worker is not killed, only the processing of the resource is cancelled
+				Expect(status.Code(errors.Unwrap(err))).To(Equal(codes.DeadlineExceeded))
+			} else {
+				Expect(err).To(MatchError("unable to wait for processor: signal: killed"))
+			}
+		})
+
+	})
+}
+
+func runTimeoutTest(processor process.ResourceStreamProcessor) error {
 	const timeout = 2 * time.Second
 
 	ctx, cancelfunc := context.WithTimeout(context.TODO(), timeout)
 	defer cancelfunc()
 
-	err := processor.Process(ctx, bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{}))
-	Expect(err).To(MatchError("unable to wait for processor: signal: killed"))
+	return processor.Process(ctx, bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{}))
 }
 
-func runExampleResourceTest(processor process.ResourceStreamProcessor) {
-	const (
-		processorName        = "example-processor"
-		resourceData         = "12345"
-		expectedResourceData = resourceData + "\n" + processorName
-	)
+func runExampleResourceTest(processor process.ResourceStreamProcessor, resourceData string) {
+	const processorName = "example-processor"
+	expectedResourceData := resourceData + "\n" + processorName
 
 	res := cdv2.Resource{
 		IdentityObjectMeta: cdv2.IdentityObjectMeta{
@@ -172,3 +277,10 @@ func runExampleResourceTest(processor process.ResourceStreamProcessor) {
 
 	Expect(processedResourceDataBuf.String()).To(Equal(expectedResourceData))
 }
+
+// failingWriter fails on every write.
+type failingWriter struct{}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("unable to write")
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package extensions
+
+import (
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ResourceProcessorServiceName is the name of the grpc service which is implemented by extensions
+// that communicate via the grpc protocol. The service is defined in processor.proto.
+const ResourceProcessorServiceName = "componentcli.transport.extensions.v1.ResourceProcessor"
+
+const (
+	processMethod = "/" + ResourceProcessorServiceName + "/Process"
+
+	// chunkSize is the maximum size of a chunk of a streamed processor message.
+	chunkSize = 64 * 1024
+)
+
+// resourceProcessorServer is the server api of the resource processor service.
+type resourceProcessorServer interface {
+	Process(stream grpc.ServerStream) error
+}
+
+var resourceProcessorServiceDesc = grpc.ServiceDesc{
+	ServiceName: ResourceProcessorServiceName,
+	HandlerType: (*resourceProcessorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Process",
+			Handler:       processHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "processor.proto",
+}
+
+func processHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(resourceProcessorServer).Process(stream)
+}
+
+// messageStream is the part of the api which is shared by client and server streams.
+type messageStream interface {
+	SendMsg(m interface{}) error
+	RecvMsg(m interface{}) error
+}
+
+// streamReader reads the chunks of a processor message from a stream.
+type streamReader struct {
+	stream messageStream
+	buf    []byte
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk := wrapperspb.BytesValue{}
+		if err := r.stream.RecvMsg(&chunk); err != nil {
+			return 0, err
+		}
+		r.buf = chunk.Value
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// streamWriter writes a processor message as chunks to a stream.
+// It should be wrapped in a buffered writer, as every write results in at least one message.
+type streamWriter struct {
+	stream messageStream
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + chunkSize
+		if end > len(p) {
+			end = len(p)
+		}
+		if err := w.stream.SendMsg(&wrapperspb.BytesValue{Value: p[written:end]}); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+var _ io.Reader = &streamReader{}
+var _ io.Writer = &streamWriter{}
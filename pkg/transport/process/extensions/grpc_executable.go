@@ -0,0 +1,334 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package extensions
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/utils"
+)
+
+// ProcessorServerProtocolEnv is the environment variable key which is used to store the
+// protocol which should be served by a resource processor server.
+const ProcessorServerProtocolEnv = "PROCESSOR_SERVER_PROTOCOL"
+
+const (
+	// workerStartupTimeout is the maximum duration until a started worker must report to be healthy.
+	workerStartupTimeout = 30 * time.Second
+	// workerShutdownTimeout is the maximum duration a worker gets for finishing after SIGTERM before it is killed.
+	workerShutdownTimeout = 10 * time.Second
+)
+
+// workers contains the long-lived workers of all grpc executables by their spec,
+// so that all executables with the same spec share a single worker process.
+var workers = struct {
+	sync.Mutex
+	m map[string]*grpcWorker
+}{
+	m: map[string]*grpcWorker{},
+}
+
+type grpcExecutable struct {
+	worker *grpcWorker
+}
+
+// NewGRPCExecutable returns a resource processor extension which is served by a long-lived worker process.
+// The worker is started on the first call of Process() and communicates via grpc over unix domain sockets.
+// All executables with the same bin, args, and env share one worker, which processes resources concurrently.
+// The workers must be stopped via StopWorkers().
+func NewGRPCExecutable(bin string, args []string, env map[string]string) (process.ResourceStreamProcessor, error) {
+	for _, key := range []string{ProcessorServerAddressEnv, ProcessorServerProtocolEnv} {
+		if _, ok := env[key]; ok {
+			return nil, fmt.Errorf("the env variable %s is not allowed to be set manually", key)
+		}
+	}
+
+	rawKey, err := json.Marshal(executableSpec{
+		Bin:  bin,
+		Args: args,
+		Env:  env,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal worker key: %w", err)
+	}
+	key := string(rawKey)
+
+	workers.Lock()
+	defer workers.Unlock()
+	worker, ok := workers.m[key]
+	if !ok {
+		worker = newGRPCWorker(bin, args, env)
+		workers.m[key] = worker
+	}
+
+	e := grpcExecutable{
+		worker: worker,
+	}
+	return &e, nil
+}
+
+// StopWorkers stops the worker processes of all grpc executables.
+// Running workers get the chance to finish their work after receiving SIGTERM, before they are killed.
+// A stopped worker is restarted if one of its executables is used again.
+func StopWorkers() error {
+	workers.Lock()
+	defer workers.Unlock()
+
+	errs := []error{}
+	for _, worker := range workers.m {
+		if err := worker.stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (e *grpcExecutable) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	conn, err := e.worker.connection(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the stream is cancelled if the output cannot be read completely, so that the input writer returns.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &resourceProcessorServiceDesc.Streams[0], processMethod)
+	if err != nil {
+		return fmt.Errorf("unable to open stream: %w", err)
+	}
+
+	// the input is written concurrently, as processors may already write output before they consumed their whole input
+	inputErr := make(chan error, 1)
+	go func() {
+		bw := bufio.NewWriterSize(&streamWriter{stream: stream}, chunkSize)
+		if _, err := io.Copy(bw, r); err != nil {
+			inputErr <- fmt.Errorf("unable to write input: %w", err)
+			return
+		}
+		if err := bw.Flush(); err != nil {
+			inputErr <- fmt.Errorf("unable to write input: %w", err)
+			return
+		}
+		if err := stream.CloseSend(); err != nil {
+			inputErr <- fmt.Errorf("unable to close input writer: %w", err)
+			return
+		}
+		inputErr <- nil
+	}()
+
+	// errors of the processor are returned when reading the output
+	if _, err := io.Copy(w, &streamReader{stream: stream}); err != nil {
+		return fmt.Errorf("unable to read output: %w", err)
+	}
+
+	if err := <-inputErr; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// grpcWorker is a long-lived extension process which serves the resource processor service.
+type grpcWorker struct {
+	bin  string
+	args []string
+	env  []string
+	addr string
+
+	mux  sync.Mutex
+	cmd  *exec.Cmd
+	conn *grpc.ClientConn
+	// exited is closed as soon as the worker process has exited
+	exited  chan struct{}
+	waitErr error
+}
+
+func newGRPCWorker(bin string, args []string, env map[string]string) *grpcWorker {
+	addr := filepath.Join(os.TempDir(), fmt.Sprintf("component-cli-%s.sock", utils.RandomString(8)))
+
+	parsedEnv := []string{}
+	for k, v := range env {
+		parsedEnv = append(parsedEnv, fmt.Sprintf("%s=%s", k, v))
+	}
+	parsedEnv = append(parsedEnv,
+		fmt.Sprintf("%s=%s", ProcessorServerAddressEnv, addr),
+		fmt.Sprintf("%s=%s", ProcessorServerProtocolEnv, GRPCProtocol),
+	)
+
+	return &grpcWorker{
+		bin:  bin,
+		args: args,
+		env:  parsedEnv,
+		addr: addr,
+	}
+}
+
+// connection returns the connection to the worker. The worker is (re)started if it is not running.
+func (w *grpcWorker) connection(ctx context.Context) (*grpc.ClientConn, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.cmd != nil {
+		select {
+		case <-w.exited:
+			// the worker crashed, so the next resource is processed by a new worker
+			_ = w.conn.Close()
+			w.cmd = nil
+			w.conn = nil
+		default:
+			return w.conn, nil
+		}
+	}
+
+	if err := w.start(ctx); err != nil {
+		return nil, err
+	}
+	return w.conn, nil
+}
+
+func (w *grpcWorker) start(ctx context.Context) error {
+	if err := removeSocket(w.addr); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(w.bin, w.args...)
+	cmd.Env = w.env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("unable to start processor: %w", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		w.waitErr = err
+		close(exited)
+	}()
+
+	conn, err := grpc.DialContext(ctx, "unix://"+w.addr,
+		grpc.WithInsecure(),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  10 * time.Millisecond,
+				Multiplier: 1.6,
+				Jitter:     0.2,
+				MaxDelay:   time.Second,
+			},
+		}),
+	)
+	if err == nil {
+		err = waitUntilServing(ctx, conn, exited)
+	}
+	if err != nil {
+		if conn != nil {
+			_ = conn.Close()
+		}
+		_ = cmd.Process.Kill()
+		<-exited
+		return fmt.Errorf("unable to connect to processor: %w", err)
+	}
+
+	w.cmd = cmd
+	w.conn = conn
+	w.exited = exited
+	return nil
+}
+
+// waitUntilServing waits until the health service of the worker reports that the resource processor service is serving.
+// The connection attempts are retried with the backoff of the connection.
+func waitUntilServing(ctx context.Context, conn *grpc.ClientConn, exited <-chan struct{}) error {
+	ctx, cancel := context.WithTimeout(ctx, workerStartupTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-exited:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: ResourceProcessorServiceName,
+	}, grpc.WaitForReady(true))
+	if err != nil {
+		select {
+		case <-exited:
+			return errors.New("processor exited during startup")
+		default:
+			return fmt.Errorf("health check failed: %w", err)
+		}
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("processor is not serving: status %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *grpcWorker) stop() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.cmd == nil {
+		return nil
+	}
+	defer func() {
+		w.cmd = nil
+		w.conn = nil
+	}()
+
+	if err := w.conn.Close(); err != nil {
+		return fmt.Errorf("unable to close connection to processor: %w", err)
+	}
+
+	var exitErr error
+	select {
+	case <-w.exited:
+	default:
+		if err := w.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			return fmt.Errorf("unable to send SIGTERM to processor: %w", err)
+		}
+		select {
+		case <-w.exited:
+			if w.waitErr != nil {
+				exitErr = fmt.Errorf("unable to wait for processor: %w", w.waitErr)
+			}
+		case <-time.After(workerShutdownTimeout):
+			_ = w.cmd.Process.Kill()
+			<-w.exited
+			exitErr = errors.New("processor didn't shut down in time and has been killed")
+		}
+	}
+
+	if err := removeSocket(w.addr); err != nil {
+		return err
+	}
+	return exitErr
+}
+
+// removeSocket removes a socket file if the server hasn't already cleaned up.
+func removeSocket(addr string) error {
+	if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to remove %s: %w", addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package extensions
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+// Serve serves a resource processor as extension until ctx is cancelled, which should happen on SIGTERM.
+// The protocol is chosen via the env variables which are set by the executable that started the extension:
+// If no server address is set, a single resource is processed via stdin/stdout.
+// Otherwise the processor is served via unix domain sockets or, if the grpc protocol is set, via grpc.
+func Serve(ctx context.Context, processor process.ResourceStreamProcessor) error {
+	addr := os.Getenv(ProcessorServerAddressEnv)
+	if addr == "" {
+		return processor.Process(ctx, os.Stdin, os.Stdout)
+	}
+
+	if os.Getenv(ProcessorServerProtocolEnv) == GRPCProtocol {
+		return serveGRPC(ctx, addr, processor)
+	}
+	return serveUnixDomainSocket(ctx, addr, processor)
+}
+
+// serveUnixDomainSocket serves a processor via unix domain sockets.
+// The server is stopped on the first error of the processor and the error is returned,
+// so that the extension exits with an error instead of handing out a truncated stream.
+func serveUnixDomainSocket(ctx context.Context, addr string, processor process.ResourceStreamProcessor) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	processErr := make(chan error, 1)
+	handlerFunc := func(r io.Reader, w io.WriteCloser) {
+		defer w.Close()
+		if err := processor.Process(ctx, r, w); err != nil {
+			select {
+			case processErr <- err:
+			default:
+			}
+		}
+	}
+
+	srv, err := utils.NewUnixDomainSocketServer(addr, handlerFunc)
+	if err != nil {
+		return fmt.Errorf("unable to create unix domain socket server: %w", err)
+	}
+	srv.Start()
+
+	var firstErr error
+	select {
+	case <-ctx.Done():
+	case firstErr = <-processErr:
+	}
+	cancel()
+	srv.Stop()
+
+	if firstErr == nil {
+		// a processor may have failed while the server was stopped
+		select {
+		case firstErr = <-processErr:
+		default:
+		}
+	}
+	return firstErr
+}
+
+// serveGRPC serves a processor via grpc. Resources are processed concurrently.
+func serveGRPC(ctx context.Context, addr string, processor process.ResourceStreamProcessor) error {
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", addr, err)
+	}
+
+	srv := grpc.NewServer()
+	healthSrv := health.NewServer()
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	srv.RegisterService(&resourceProcessorServiceDesc, &grpcProcessorServer{processor: processor})
+	healthSrv.SetServingStatus(ResourceProcessorServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		// let clients know that no new resources are accepted before the running ones are finished
+		healthSrv.Shutdown()
+		srv.GracefulStop()
+	}()
+
+	if err := srv.Serve(l); err != nil {
+		return fmt.Errorf("unable to serve: %w", err)
+	}
+	<-stopped
+	return nil
+}
+
+type grpcProcessorServer struct {
+	processor process.ResourceStreamProcessor
+}
+
+func (s *grpcProcessorServer) Process(stream grpc.ServerStream) error {
+	w := bufio.NewWriterSize(&streamWriter{stream: stream}, chunkSize)
+	if err := s.processor.Process(stream.Context(), &streamReader{stream: stream}, w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+	return nil
+}
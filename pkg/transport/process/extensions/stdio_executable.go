@@ -53,15 +53,28 @@ func (e *stdIOExecutable) Process(ctx context.Context, r io.Reader, w io.Writer)
 		return fmt.Errorf("unable to start processor: %w", err)
 	}
 
-	if _, err := io.Copy(stdin, r); err != nil {
-		return fmt.Errorf("unable to write input: %w", err)
-	}
-
-	if err := stdin.Close(); err != nil {
-		return fmt.Errorf("unable to close input writer: %w", err)
-	}
+	// the input is written concurrently, as processors may already write output before they
+	// consumed their whole input. otherwise the processor would block on a full stdout pipe.
+	inputErr := make(chan error, 1)
+	go func() {
+		if _, err := io.Copy(stdin, r); err != nil {
+			stdin.Close()
+			inputErr <- fmt.Errorf("unable to write input: %w", err)
+			return
+		}
+		if err := stdin.Close(); err != nil {
+			inputErr <- fmt.Errorf("unable to close input writer: %w", err)
+			return
+		}
+		inputErr <- nil
+	}()
 
 	if _, err := io.Copy(w, stdout); err != nil {
+		// stop the processor, so that the input writer fails instead of blocking on a processor
+		// which no longer reads its input.
+		stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
 		return fmt.Errorf("unable to read output: %w", err)
 	}
 
@@ -69,5 +82,9 @@ func (e *stdIOExecutable) Process(ctx context.Context, r io.Reader, w io.Writer)
 		return fmt.Errorf("unable to wait for processor: %w", err)
 	}
 
+	if err := <-inputErr; err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -6,7 +6,9 @@ package extensions
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/yaml"
 
 	"github.com/gardener/component-cli/pkg/transport/process"
@@ -15,14 +17,60 @@ import (
 const (
 	// ExecutableType defines the type of an executable
 	ExecutableType = "Executable"
+
+	// StdIOProtocol defines that an executable communicates via stdin/stdout
+	StdIOProtocol = "stdio"
+
+	// UnixDomainSocketProtocol defines that an executable communicates via unix domain sockets
+	UnixDomainSocketProtocol = "uds"
+
+	// GRPCProtocol defines that an executable is a long-lived worker which communicates via grpc
+	GRPCProtocol = "grpc"
 )
 
-// CreateExecutable creates a new executable defined by a spec
+// Protocols contains all supported protocols of executables
+var Protocols = []string{StdIOProtocol, UnixDomainSocketProtocol, GRPCProtocol}
+
+type executableSpec struct {
+	Bin      string
+	Args     []string
+	Env      map[string]string
+	Protocol string
+}
+
+// CreateExecutable creates a new executable defined by a spec.
+// If no protocol is defined, the executable communicates via unix domain sockets.
 func CreateExecutable(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
-	type executableSpec struct {
-		Bin  string
-		Args []string
-		Env  map[string]string
+	spec, err := parseExecutableSpec(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	switch spec.Protocol {
+	case StdIOProtocol:
+		return NewStdIOExecutable(spec.Bin, spec.Args, spec.Env)
+	case GRPCProtocol:
+		return NewGRPCExecutable(spec.Bin, spec.Args, spec.Env)
+	default:
+		return NewUnixDomainSocketExecutable(spec.Bin, spec.Args, spec.Env)
+	}
+}
+
+// ValidateExecutable validates the spec of an executable without creating the executable
+func ValidateExecutable(fldPath *field.Path, rawSpec *json.RawMessage) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if rawSpec == nil {
+		return append(allErrs, field.Required(fldPath, "spec of an executable must not be empty"))
+	}
+	if _, err := parseExecutableSpec(rawSpec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, string(*rawSpec), err.Error()))
+	}
+	return allErrs
+}
+
+func parseExecutableSpec(rawSpec *json.RawMessage) (*executableSpec, error) {
+	if rawSpec == nil {
+		return nil, fmt.Errorf("spec must not be empty")
 	}
 
 	var spec executableSpec
@@ -30,5 +78,15 @@ func CreateExecutable(rawSpec *json.RawMessage) (process.ResourceStreamProcessor
 		return nil, fmt.Errorf("unable to parse spec: %w", err)
 	}
 
-	return NewUnixDomainSocketExecutable(spec.Bin, spec.Args, spec.Env)
+	if len(spec.Bin) == 0 {
+		return nil, fmt.Errorf("bin must not be empty")
+	}
+
+	switch spec.Protocol {
+	case StdIOProtocol, UnixDomainSocketProtocol, GRPCProtocol, "":
+	default:
+		return nil, fmt.Errorf("unknown protocol %s, must be one of %s", spec.Protocol, strings.Join(Protocols, ", "))
+	}
+
+	return &spec, nil
 }
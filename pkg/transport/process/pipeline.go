@@ -13,11 +13,55 @@ import (
 	"io/ioutil"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/go-logr/logr"
 
 	"github.com/gardener/component-cli/pkg/transport/process/utils"
 )
 
-const processorTimeout = 30 * time.Second
+const (
+	// DefaultProcessorTimeout is the timeout of a single processor run if no timeout is configured
+	DefaultProcessorTimeout = 30 * time.Second
+
+	// DefaultInitialBackoff is the backoff before the first retry if no initial backoff is configured
+	DefaultInitialBackoff = 1 * time.Second
+
+	// DefaultMaxBackoff is the upper limit for the backoff between retries if no max backoff is configured
+	DefaultMaxBackoff = 1 * time.Minute
+)
+
+// ProcessorOptions defines how a processor is executed by a pipeline.
+type ProcessorOptions struct {
+	// Timeout is the timeout of a single processor run. Defaults to DefaultProcessorTimeout.
+	Timeout time.Duration
+	// Retry defines the retry policy for failed processor runs.
+	// If nil, a processor is only executed once.
+	Retry *RetryOptions
+}
+
+// RetryOptions defines how often and when a failed processor run is retried.
+// The backoff between two attempts is doubled after every attempt, starting with
+// InitialBackoff until MaxBackoff is reached.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of processor runs, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the backoff before the first retry. Defaults to DefaultInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper limit for the backoff between retries. Defaults to DefaultMaxBackoff.
+	MaxBackoff time.Duration
+}
+
+type configuredProcessor struct {
+	ResourceStreamProcessor
+	opts ProcessorOptions
+}
+
+// WithProcessorOptions returns a processor which is executed by a pipeline with the given options.
+func WithProcessorOptions(proc ResourceStreamProcessor, opts ProcessorOptions) ResourceStreamProcessor {
+	return &configuredProcessor{
+		ResourceStreamProcessor: proc,
+		opts:                    opts,
+	}
+}
 
 type resourceProcessingPipelineImpl struct {
 	processors []ResourceStreamProcessor
@@ -30,6 +74,7 @@ func (p *resourceProcessingPipelineImpl) Process(ctx context.Context, cd cdv2.Co
 	}
 
 	if err := utils.WriteProcessorMessage(cd, res, nil, infile); err != nil {
+		removeTempFile(infile)
 		return nil, cdv2.Resource{}, fmt.Errorf("unable to write: %w", err)
 	}
 
@@ -41,7 +86,7 @@ func (p *resourceProcessingPipelineImpl) Process(ctx context.Context, cd cdv2.Co
 
 		infile = outfile
 	}
-	defer infile.Close()
+	defer removeTempFile(infile)
 
 	if _, err := infile.Seek(0, io.SeekStart); err != nil {
 		return nil, cdv2.Resource{}, fmt.Errorf("unable to seek to beginning of input file: %w", err)
@@ -59,10 +104,32 @@ func (p *resourceProcessingPipelineImpl) Process(ctx context.Context, cd cdv2.Co
 }
 
 func (p *resourceProcessingPipelineImpl) runProcessor(ctx context.Context, infile *os.File, proc ResourceStreamProcessor) (*os.File, error) {
-	defer infile.Close()
+	defer removeTempFile(infile)
 
-	if _, err := infile.Seek(0, io.SeekStart); err != nil {
-		return nil, fmt.Errorf("unable to seek to beginning of input file: %w", err)
+	opts := ProcessorOptions{}
+	if cp, ok := proc.(*configuredProcessor); ok {
+		opts = cp.opts
+		proc = cp.ResourceStreamProcessor
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultProcessorTimeout
+	}
+
+	maxAttempts := 1
+	backoff := DefaultInitialBackoff
+	maxBackoff := DefaultMaxBackoff
+	if opts.Retry != nil {
+		if opts.Retry.MaxAttempts > 1 {
+			maxAttempts = opts.Retry.MaxAttempts
+		}
+		if opts.Retry.InitialBackoff > 0 {
+			backoff = opts.Retry.InitialBackoff
+		}
+		if opts.Retry.MaxBackoff > 0 {
+			maxBackoff = opts.Retry.MaxBackoff
+		}
 	}
 
 	outfile, err := ioutil.TempFile("", "")
@@ -70,17 +137,60 @@ func (p *resourceProcessingPipelineImpl) runProcessor(ctx context.Context, infil
 		return nil, fmt.Errorf("unable to create temporary outfile: %w", err)
 	}
 
-	inreader := infile
-	outwriter := outfile
+	for attempt := 1; ; attempt++ {
+		err := runProcessorAttempt(ctx, infile, outfile, proc, timeout)
+		if err == nil {
+			return outfile, nil
+		}
 
-	ctx, cancelfunc := context.WithTimeout(ctx, processorTimeout)
-	defer cancelfunc()
+		if attempt >= maxAttempts || ctx.Err() != nil {
+			removeTempFile(outfile)
+			if maxAttempts > 1 {
+				return nil, fmt.Errorf("unable to process resource after %d attempts: %w", attempt, err)
+			}
+			return nil, fmt.Errorf("unable to process resource: %w", err)
+		}
+
+		logr.FromContextOrDiscard(ctx).V(3).Info("processor failed, retrying", "attempt", attempt, "backoff", backoff.String(), "error", err.Error())
 
-	if err := proc.Process(ctx, inreader, outwriter); err != nil {
-		return nil, fmt.Errorf("unable to process resource: %w", err)
+		select {
+		case <-ctx.Done():
+			removeTempFile(outfile)
+			return nil, fmt.Errorf("unable to process resource: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
 	}
+}
+
+// runProcessorAttempt executes a processor once. The processor message is replayed from the
+// beginning of the input file and the output file is truncated before every attempt.
+func runProcessorAttempt(ctx context.Context, infile *os.File, outfile *os.File, proc ResourceStreamProcessor, timeout time.Duration) error {
+	if _, err := infile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to seek to beginning of input file: %w", err)
+	}
+
+	if err := outfile.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate output file: %w", err)
+	}
+	if _, err := outfile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to seek to beginning of output file: %w", err)
+	}
+
+	ctx, cancelfunc := context.WithTimeout(ctx, timeout)
+	defer cancelfunc()
+
+	return proc.Process(ctx, infile, outfile)
+}
 
-	return outfile, nil
+// removeTempFile closes and deletes a temporary file which holds a processor message.
+func removeTempFile(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
 }
 
 // NewResourceProcessingPipeline returns a new ResourceProcessingPipeline
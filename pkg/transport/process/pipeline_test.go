@@ -6,6 +6,9 @@ package process_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"time"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
 	. "github.com/onsi/ginkgo"
@@ -59,5 +62,104 @@ var _ = Describe("pipeline", func() {
 			Expect(actualRes).To(Equal(expectedRes))
 		})
 
+		It("should retry a failed processor with the replayed processor message", func() {
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "my-res",
+					Version: "v0.1.0",
+					Type:    "ociImage",
+				},
+			}
+			l := cdv2.Label{
+				Name:  "processor-0",
+				Value: json.RawMessage(`"true"`),
+			}
+			expectedRes := res
+			expectedRes.Labels = append(expectedRes.Labels, l)
+
+			cd := cdv2.ComponentDescriptor{
+				ComponentSpec: cdv2.ComponentSpec{
+					Resources: []cdv2.Resource{
+						res,
+					},
+				},
+			}
+
+			flaky := &flakyProcessor{
+				failures:  2,
+				processor: processors.NewResourceLabeler(l),
+			}
+			opts := process.ProcessorOptions{
+				Retry: &process.RetryOptions{
+					MaxAttempts:    3,
+					InitialBackoff: 10 * time.Millisecond,
+				},
+			}
+			pipeline := process.NewResourceProcessingPipeline(process.WithProcessorOptions(flaky, opts))
+
+			_, actualRes, err := pipeline.Process(context.TODO(), cd, res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualRes).To(Equal(expectedRes))
+			Expect(flaky.attempts).To(Equal(3))
+		})
+
+		It("should fail if the max attempts are exceeded", func() {
+			flaky := &flakyProcessor{
+				failures:  3,
+				processor: processors.NewResourceLabeler(),
+			}
+			opts := process.ProcessorOptions{
+				Retry: &process.RetryOptions{
+					MaxAttempts:    2,
+					InitialBackoff: 10 * time.Millisecond,
+				},
+			}
+			pipeline := process.NewResourceProcessingPipeline(process.WithProcessorOptions(flaky, opts))
+
+			_, _, err := pipeline.Process(context.TODO(), cdv2.ComponentDescriptor{}, cdv2.Resource{})
+			Expect(err).To(MatchError("unable to process resource after 2 attempts: processor failed"))
+			Expect(flaky.attempts).To(Equal(2))
+		})
+
+		It("should cancel a processor when the configured timeout is reached", func() {
+			opts := process.ProcessorOptions{
+				Timeout: 100 * time.Millisecond,
+			}
+			pipeline := process.NewResourceProcessingPipeline(process.WithProcessorOptions(blockingProcessor{}, opts))
+
+			_, _, err := pipeline.Process(context.TODO(), cdv2.ComponentDescriptor{}, cdv2.Resource{})
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+
 	})
 })
+
+// flakyProcessor fails for a defined number of attempts before it delegates to the wrapped processor.
+// It consumes the whole input on every attempt to ensure that the input is replayed.
+type flakyProcessor struct {
+	failures  int
+	attempts  int
+	processor process.ResourceStreamProcessor
+}
+
+func (p *flakyProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	p.attempts++
+	if p.attempts <= p.failures {
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte("garbage")); err != nil {
+			return err
+		}
+		return errors.New("processor failed")
+	}
+	return p.processor.Process(ctx, r, w)
+}
+
+type blockingProcessor struct{}
+
+func (p blockingProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,41 +26,18 @@ const processorName = "example-processor"
 // a test processor which adds its name to the resource labels and the resource blob.
 // the resource blob is expected to be plain text data.
 func main() {
-	// read the address under which the unix domain socket server should start
-	addr := os.Getenv(extensions.ProcessorServerAddressEnv)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if addr == "" {
-		// if addr is not set, use stdin/stdout for communication
-		if err := processorRoutine(os.Stdin, os.Stdout); err != nil {
-			log.Fatal(err)
-		}
-		return
-	}
-	// if addr is set, use unix domain sockets for communication
-
-	h := func(r io.Reader, w io.WriteCloser) {
-		if err := processorRoutine(r, w); err != nil {
-			log.Fatal(err)
-		}
-	}
-
-	srv, err := utils.NewUnixDomainSocketServer(addr, h)
-	if err != nil {
+	// the protocol is chosen by the executable which started the processor
+	if err := extensions.Serve(ctx, &exampleProcessor{}); err != nil {
 		log.Fatal(err)
 	}
-
-	srv.Start()
-
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
-
-	srv.Stop()
 }
 
-func processorRoutine(inputStream io.Reader, outputStream io.WriteCloser) error {
-	defer outputStream.Close()
+type exampleProcessor struct{}
 
+func (p *exampleProcessor) Process(ctx context.Context, inputStream io.Reader, outputStream io.Writer) error {
 	// split up the input stream into component descriptor, resource, and resource blob
 	cd, res, resourceBlobReader, err := utils.ReadProcessorMessage(inputStream)
 	if err != nil {
@@ -0,0 +1,455 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package processors
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/apis/v2/signatures"
+	ivpkg "github.com/gardener/image-vector/pkg"
+	"github.com/opencontainers/go-digest"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/oci"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/utils"
+)
+
+const (
+	// HelmChartContentLayerMediaType is the media type of the layer which contains the chart archive of a helm chart oci artifact
+	HelmChartContentLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+	// legacyHelmChartContentLayerMediaType is the media type of chart layers which have been pushed by helm < 3.7
+	legacyHelmChartContentLayerMediaType = "application/tar+gzip"
+
+	valuesFile = "values.yaml"
+)
+
+type helmChartRelocator struct {
+	cache                cache.Cache
+	baseUrl              string
+	keepSourceRepo       bool
+	useImageVectorLabels bool
+}
+
+// NewHelmChartRelocator returns a processor that rewrites the image references in the values.yaml files of a helm chart,
+// so that they point to the images which have been transported to baseUrl. The target references are calculated like
+// the references of the oci artifact uploader. Only images which are resources of the component are rewritten.
+// If useImageVectorLabels is set, the images of the imagevector labels of the component are rewritten, too.
+// The resource blob is either a serialized helm chart oci artifact or a chart archive.
+// The values.yaml files are re-marshaled if they contain image references, which drops all comments and sorts the keys
+// alphabetically. Values files without image references of the component are kept unmodified.
+// Values files of subcharts which are packaged as archives inside the chart are not modified.
+func NewHelmChartRelocator(cache cache.Cache, baseUrl string, keepSourceRepo, useImageVectorLabels bool) (process.ResourceStreamProcessor, error) {
+	if cache == nil {
+		return nil, errors.New("cache must not be nil")
+	}
+
+	if baseUrl == "" {
+		return nil, errors.New("baseUrl must not be empty")
+	}
+
+	obj := helmChartRelocator{
+		cache:                cache,
+		baseUrl:              baseUrl,
+		keepSourceRepo:       keepSourceRepo,
+		useImageVectorLabels: useImageVectorLabels,
+	}
+	return &obj, nil
+}
+
+func (p *helmChartRelocator) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, resBlobReader, err := processutils.ReadProcessorMessageStream(r)
+	if err != nil {
+		return fmt.Errorf("unable to read processor message: %w", err)
+	}
+	if resBlobReader == nil {
+		return errors.New("resource blob must not be nil")
+	}
+	defer resBlobReader.Close()
+
+	images, err := p.sourceImages(*cd)
+	if err != nil {
+		return err
+	}
+
+	var (
+		blobReader io.ReadCloser
+		dgst       *cdv2.DigestSpec
+	)
+	if res.Access != nil && res.Access.Type == cdv2.OCIRegistryType {
+		blobReader, dgst, err = p.relocateOCIArtifact(resBlobReader, images)
+	} else {
+		blobReader, dgst, err = p.relocateChartBlob(resBlobReader, images)
+	}
+	if err != nil {
+		return err
+	}
+	defer blobReader.Close()
+
+	if res.Digest != nil {
+		res.Digest = dgst
+	}
+
+	if err := processutils.WriteProcessorMessage(*cd, res, blobReader, w); err != nil {
+		return fmt.Errorf("unable to write processor message: %w", err)
+	}
+
+	return nil
+}
+
+// relocateOCIArtifact relocates the chart layer of a serialized helm chart oci artifact.
+func (p *helmChartRelocator) relocateOCIArtifact(r io.Reader, images map[string]bool) (io.ReadCloser, *cdv2.DigestSpec, error) {
+	ociArtifact, err := processutils.DeserializeOCIArtifact(r, p.cache)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to deserialize oci artifact: %w", err)
+	}
+	if !ociArtifact.IsManifest() {
+		return nil, nil, errors.New("helm chart oci artifact must be a manifest")
+	}
+
+	manifest := ociArtifact.GetManifest().Data
+	layerIndex := -1
+	for i, layer := range manifest.Layers {
+		if layer.MediaType == HelmChartContentLayerMediaType || layer.MediaType == legacyHelmChartContentLayerMediaType {
+			layerIndex = i
+			break
+		}
+	}
+	if layerIndex < 0 {
+		return nil, nil, fmt.Errorf("oci artifact contains no layer of media type %s", HelmChartContentLayerMediaType)
+	}
+
+	layer := manifest.Layers[layerIndex]
+	chartReader, err := p.cache.Get(layer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get chart layer from cache: %w", err)
+	}
+	defer chartReader.Close()
+
+	relocatedChart, err := p.relocateChart(chartReader, images)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer relocatedChart.Close()
+
+	layer.Digest, layer.Size, err = digestFile(relocatedChart.File)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.cache.Add(layer, relocatedChart); err != nil {
+		return nil, nil, fmt.Errorf("unable to add relocated chart layer to cache: %w", err)
+	}
+	manifest.Layers[layerIndex] = layer
+
+	manifestDigest, err := artifactDigest(ociArtifact)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to calculate digest of oci artifact: %w", err)
+	}
+
+	blobReader, err := processutils.SerializeOCIArtifact(*ociArtifact, p.cache)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to serialize oci artifact: %w", err)
+	}
+
+	return blobReader, &cdv2.DigestSpec{
+		HashAlgorithm:          signatures.SHA256,
+		NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+		Value:                  manifestDigest.Encoded(),
+	}, nil
+}
+
+// relocateChartBlob relocates a resource blob which is a chart archive.
+func (p *helmChartRelocator) relocateChartBlob(r io.Reader, images map[string]bool) (io.ReadCloser, *cdv2.DigestSpec, error) {
+	relocatedChart, err := p.relocateChart(r, images)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dgst, _, err := digestFile(relocatedChart.File)
+	if err != nil {
+		relocatedChart.Close()
+		return nil, nil, err
+	}
+
+	return relocatedChart, &cdv2.DigestSpec{
+		HashAlgorithm:          signatures.SHA256,
+		NormalisationAlgorithm: string(cdv2.GenericBlobDigestV1),
+		Value:                  dgst.Encoded(),
+	}, nil
+}
+
+// relocateChart rewrites the values files of a chart archive. The relocated chart archive is returned as tempfile,
+// which is deleted when it is closed.
+func (p *helmChartRelocator) relocateChart(r io.Reader, images map[string]bool) (*processutils.TempFile, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open gzip reader: %w", err)
+	}
+	defer gzr.Close()
+
+	tmpfile, err := processutils.NewTempFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.relocateChartFiles(tar.NewReader(gzr), tmpfile, images); err != nil {
+		tmpfile.Close()
+		return nil, err
+	}
+
+	if _, err := tmpfile.Seek(0, io.SeekStart); err != nil {
+		tmpfile.Close()
+		return nil, fmt.Errorf("unable to seek to beginning of tempfile: %w", err)
+	}
+	return tmpfile, nil
+}
+
+func (p *helmChartRelocator) relocateChartFiles(tr *tar.Reader, w io.Writer, images map[string]bool) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("unable to read tar header: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || path.Base(header.Name) != valuesFile {
+			if err := tw.WriteHeader(header); err != nil {
+				return fmt.Errorf("unable to write tar header: %w", err)
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return fmt.Errorf("unable to copy %s: %w", header.Name, err)
+			}
+			continue
+		}
+
+		values, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", header.Name, err)
+		}
+		relocatedValues, err := p.relocateValues(values, images)
+		if err != nil {
+			return fmt.Errorf("unable to relocate %s: %w", header.Name, err)
+		}
+
+		header.Size = int64(len(relocatedValues))
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("unable to write tar header: %w", err)
+		}
+		if _, err := tw.Write(relocatedValues); err != nil {
+			return fmt.Errorf("unable to write %s: %w", header.Name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("unable to close tar writer: %w", err)
+	}
+	if err := gzw.Close(); err != nil {
+		return fmt.Errorf("unable to close gzip writer: %w", err)
+	}
+	return nil
+}
+
+// relocateValues rewrites the image references of a values file. The file is returned unmodified if it contains no image references.
+// Otherwise the values are marshaled again, which drops comments and sorts the keys.
+func (p *helmChartRelocator) relocateValues(data []byte, images map[string]bool) ([]byte, error) {
+	var values interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("unable to parse values: %w", err)
+	}
+
+	relocated, changed, err := p.relocateValue(values, images)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return data, nil
+	}
+
+	return yaml.Marshal(relocated)
+}
+
+// relocateValue rewrites image references in a value. Images are either defined as full reference
+// ("image: eu.gcr.io/my-project/my-image:1.0.0") or as map with a repository and an optional registry
+// ("image: {registry: eu.gcr.io, repository: my-project/my-image, tag: 1.0.0}").
+func (p *helmChartRelocator) relocateValue(value interface{}, images map[string]bool) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case string:
+		name, ok := imageName(v)
+		if !ok || !images[name] {
+			return v, false, nil
+		}
+		target, err := utils.TargetOCIArtifactRef(p.baseUrl, v, p.keepSourceRepo)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to create target oci artifact reference for %s: %w", v, err)
+		}
+		return target, true, nil
+	case map[string]interface{}:
+		if changed, err := p.relocateImageMap(v, images); changed || err != nil {
+			return v, changed, err
+		}
+		changed := false
+		for key, item := range v {
+			relocated, itemChanged, err := p.relocateValue(item, images)
+			if err != nil {
+				return nil, false, err
+			}
+			v[key] = relocated
+			changed = changed || itemChanged
+		}
+		return v, changed, nil
+	case []interface{}:
+		changed := false
+		for i, item := range v {
+			relocated, itemChanged, err := p.relocateValue(item, images)
+			if err != nil {
+				return nil, false, err
+			}
+			v[i] = relocated
+			changed = changed || itemChanged
+		}
+		return v, changed, nil
+	default:
+		return v, false, nil
+	}
+}
+
+// relocateImageMap rewrites the registry and repository of an image which is defined as map.
+func (p *helmChartRelocator) relocateImageMap(values map[string]interface{}, images map[string]bool) (bool, error) {
+	repository, ok := values["repository"].(string)
+	if !ok {
+		return false, nil
+	}
+	rawRegistry, hasRegistry := values["registry"]
+	if hasRegistry {
+		registry, ok := rawRegistry.(string)
+		if !ok {
+			return false, nil
+		}
+		repository = registry + "/" + repository
+	}
+
+	name, ok := imageName(repository)
+	if !ok || !images[name] {
+		return false, nil
+	}
+
+	target, err := utils.TargetOCIArtifactRef(p.baseUrl, name, p.keepSourceRepo)
+	if err != nil {
+		return false, fmt.Errorf("unable to create target oci artifact reference for %s: %w", name, err)
+	}
+	targetName, _ := imageName(target)
+
+	if !hasRegistry {
+		values["repository"] = targetName
+		return true, nil
+	}
+	split := strings.SplitN(targetName, "/", 2)
+	values["registry"] = split[0]
+	values["repository"] = split[1]
+	return true, nil
+}
+
+// sourceImages returns the normalized names of the images of a component which are relocated.
+func (p *helmChartRelocator) sourceImages(cd cdv2.ComponentDescriptor) (map[string]bool, error) {
+	images := map[string]bool{}
+	addImage := func(ref string) {
+		if name, ok := imageName(ref); ok {
+			images[name] = true
+		}
+	}
+
+	for _, res := range cd.Resources {
+		if res.Access != nil && res.Access.Type == cdv2.OCIRegistryType {
+			acc := cdv2.OCIRegistryAccess{}
+			if err := res.Access.DecodeInto(&acc); err != nil {
+				return nil, fmt.Errorf("unable to decode access of resource %s: %w", res.Name, err)
+			}
+			addImage(acc.ImageReference)
+		}
+		if !p.useImageVectorLabels {
+			continue
+		}
+		if label, ok := findLabel(res.Labels, ivpkg.RepositoryLabel); ok {
+			var repository string
+			if err := json.Unmarshal(label.Value, &repository); err != nil {
+				return nil, fmt.Errorf("unable to parse label %s of resource %s: %w", ivpkg.RepositoryLabel, res.Name, err)
+			}
+			addImage(repository)
+		}
+	}
+
+	if !p.useImageVectorLabels {
+		return images, nil
+	}
+
+	imageLabels := []cdv2.Labels{cd.Labels}
+	for _, ref := range cd.ComponentReferences {
+		imageLabels = append(imageLabels, ref.Labels)
+	}
+	for _, labels := range imageLabels {
+		label, ok := findLabel(labels, ivpkg.ImagesLabel)
+		if !ok {
+			continue
+		}
+		iv := ivpkg.ImageVector{}
+		if err := json.Unmarshal(label.Value, &iv); err != nil {
+			return nil, fmt.Errorf("unable to parse label %s: %w", ivpkg.ImagesLabel, err)
+		}
+		for _, image := range iv.Images {
+			addImage(image.Repository)
+		}
+	}
+
+	return images, nil
+}
+
+func findLabel(labels cdv2.Labels, name string) (cdv2.Label, bool) {
+	for _, label := range labels {
+		if label.Name == name {
+			return label, true
+		}
+	}
+	return cdv2.Label{}, false
+}
+
+// imageName returns the normalized name of an image reference without tag and digest.
+func imageName(ref string) (string, bool) {
+	parsedRef, err := oci.ParseRef(ref)
+	if err != nil {
+		return "", false
+	}
+	return parsedRef.Name(), true
+}
+
+// digestFile calculates the digest and size of a file and seeks back to its beginning.
+func digestFile(f *os.File) (digest.Digest, int64, error) {
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(digester.Hash(), f)
+	if err != nil {
+		return "", 0, fmt.Errorf("unable to calculate digest: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("unable to seek to beginning of tempfile: %w", err)
+	}
+	return digester.Digest(), size, nil
+}
@@ -0,0 +1,329 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package processors_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/oci"
+	"github.com/gardener/component-cli/pkg/testutils"
+	"github.com/gardener/component-cli/pkg/transport/process/processors"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+var _ = Describe("helmChartRelocator", func() {
+
+	const (
+		baseUrl = "target.registry.io/base"
+		values  = `image: eu.gcr.io/my-project/my-image:1.0.0
+sidecar:
+  registry: eu.gcr.io
+  repository: my-project/sidecar
+  tag: 2.0.0
+other:
+  repository: eu.gcr.io/other/image
+pullPolicy: IfNotPresent
+`
+		chartFile = "apiVersion: v2\nname: mychart\nversion: 0.1.0\n"
+	)
+
+	var (
+		ociCache cache.Cache
+		cd       cdv2.ComponentDescriptor
+		res      cdv2.Resource
+	)
+
+	newOCIRegistryAccess := func(ref string) *cdv2.UnstructuredTypedObject {
+		acc, err := cdv2.NewUnstructured(cdv2.NewOCIRegistryAccess(ref))
+		Expect(err).ToNot(HaveOccurred())
+		return &acc
+	}
+
+	createChart := func(files map[string]string) []byte {
+		buf := bytes.NewBuffer([]byte{})
+		gzw := gzip.NewWriter(buf)
+		tw := tar.NewWriter(gzw)
+		for _, name := range []string{"mychart/Chart.yaml", "mychart/values.yaml"} {
+			Expect(tw.WriteHeader(&tar.Header{
+				Name:     name,
+				Mode:     0644,
+				Size:     int64(len(files[name])),
+				Typeflag: tar.TypeReg,
+			})).To(Succeed())
+			_, err := tw.Write([]byte(files[name]))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(tw.Close()).To(Succeed())
+		Expect(gzw.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	readChart := func(r io.Reader) map[string]string {
+		gzr, err := gzip.NewReader(r)
+		Expect(err).ToNot(HaveOccurred())
+		tr := tar.NewReader(gzr)
+		files := map[string]string{}
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+			data, err := ioutil.ReadAll(tr)
+			Expect(err).ToNot(HaveOccurred())
+			files[header.Name] = string(data)
+		}
+		return files
+	}
+
+	readValues := func(data string) map[string]interface{} {
+		values := map[string]interface{}{}
+		Expect(yaml.Unmarshal([]byte(data), &values)).To(Succeed())
+		return values
+	}
+
+	process := func(blob []byte, useImageVectorLabels bool) (cdv2.Resource, []byte) {
+		inBuf := bytes.NewBuffer([]byte{})
+		Expect(utils.WriteProcessorMessage(cd, res, bytes.NewReader(blob), inBuf)).To(Succeed())
+
+		p, err := processors.NewHelmChartRelocator(ociCache, baseUrl, false, useImageVectorLabels)
+		Expect(err).ToNot(HaveOccurred())
+
+		outBuf := bytes.NewBuffer([]byte{})
+		Expect(p.Process(context.TODO(), inBuf, outBuf)).To(Succeed())
+
+		actualCd, actualRes, resBlobReader, err := utils.ReadProcessorMessage(outBuf)
+		Expect(err).ToNot(HaveOccurred())
+		defer resBlobReader.Close()
+		Expect(actualCd.Name).To(Equal(cd.Name))
+
+		actualBlob, err := ioutil.ReadAll(resBlobReader)
+		Expect(err).ToNot(HaveOccurred())
+		return actualRes, actualBlob
+	}
+
+	BeforeEach(func() {
+		ociCache = cache.NewInMemoryCache()
+		res = cdv2.Resource{
+			IdentityObjectMeta: cdv2.IdentityObjectMeta{
+				Name:    "my-chart",
+				Version: "0.1.0",
+				Type:    "helm",
+			},
+			Relation: cdv2.LocalRelation,
+			Access:   nil,
+			Digest: &cdv2.DigestSpec{
+				HashAlgorithm:          "sha256",
+				NormalisationAlgorithm: string(cdv2.GenericBlobDigestV1),
+				Value:                  "00000000",
+			},
+		}
+		cd = cdv2.ComponentDescriptor{
+			ComponentSpec: cdv2.ComponentSpec{
+				ObjectMeta: cdv2.ObjectMeta{
+					Name:    "github.com/component-cli/test-component",
+					Version: "0.1.0",
+				},
+				Resources: []cdv2.Resource{
+					res,
+					{
+						IdentityObjectMeta: cdv2.IdentityObjectMeta{
+							Name:    "my-image",
+							Version: "1.0.0",
+							Type:    cdv2.OCIImageType,
+						},
+						Access: newOCIRegistryAccess("eu.gcr.io/my-project/my-image:1.0.0"),
+					},
+					{
+						IdentityObjectMeta: cdv2.IdentityObjectMeta{
+							Name:    "sidecar",
+							Version: "2.0.0",
+							Type:    cdv2.OCIImageType,
+						},
+						Access: newOCIRegistryAccess("eu.gcr.io/my-project/sidecar:2.0.0"),
+					},
+				},
+				ComponentReferences: []cdv2.ComponentReference{
+					{
+						Name:          "other",
+						ComponentName: "github.com/component-cli/other-component",
+						Version:       "0.1.0",
+						Labels: cdv2.Labels{
+							{
+								Name:  "imagevector.gardener.cloud/images",
+								Value: json.RawMessage(`{"images": [{"name": "other", "repository": "eu.gcr.io/other/image", "tag": "3.0.0"}]}`),
+							},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("should relocate the images of the component in a chart archive", func() {
+		chart := createChart(map[string]string{
+			"mychart/Chart.yaml":  chartFile,
+			"mychart/values.yaml": values,
+		})
+
+		actualRes, actualBlob := process(chart, false)
+
+		files := readChart(bytes.NewReader(actualBlob))
+		Expect(files).To(HaveKeyWithValue("mychart/Chart.yaml", chartFile))
+		Expect(readValues(files["mychart/values.yaml"])).To(Equal(map[string]interface{}{
+			"image": baseUrl + "/my-project/my-image:1.0.0",
+			"sidecar": map[string]interface{}{
+				"registry":   "target.registry.io",
+				"repository": "base/my-project/sidecar",
+				"tag":        "2.0.0",
+			},
+			"other": map[string]interface{}{
+				"repository": "eu.gcr.io/other/image",
+			},
+			"pullPolicy": "IfNotPresent",
+		}))
+
+		Expect(actualRes.Digest).To(Equal(&cdv2.DigestSpec{
+			HashAlgorithm:          "sha256",
+			NormalisationAlgorithm: string(cdv2.GenericBlobDigestV1),
+			Value:                  digest.FromBytes(actualBlob).Encoded(),
+		}))
+	})
+
+	It("should relocate the images of the imagevector labels", func() {
+		chart := createChart(map[string]string{
+			"mychart/Chart.yaml":  chartFile,
+			"mychart/values.yaml": values,
+		})
+
+		_, actualBlob := process(chart, true)
+
+		files := readChart(bytes.NewReader(actualBlob))
+		Expect(readValues(files["mychart/values.yaml"])).To(HaveKeyWithValue("other", map[string]interface{}{
+			"repository": baseUrl + "/other/image",
+		}))
+	})
+
+	It("should not modify values files without images of the component", func() {
+		unrelatedValues := "# the image of the chart\nimage: eu.gcr.io/unrelated/image:1.0.0\n"
+		chart := createChart(map[string]string{
+			"mychart/Chart.yaml":  chartFile,
+			"mychart/values.yaml": unrelatedValues,
+		})
+
+		_, actualBlob := process(chart, false)
+
+		files := readChart(bytes.NewReader(actualBlob))
+		Expect(files).To(HaveKeyWithValue("mychart/values.yaml", unrelatedValues))
+	})
+
+	It("should relocate the chart layer of a helm chart oci artifact", func() {
+		res.Type = "helmChart"
+		res.Access = newOCIRegistryAccess("eu.gcr.io/my-project/charts/mychart:0.1.0")
+		cd.Resources[0] = res
+
+		chart := createChart(map[string]string{
+			"mychart/Chart.yaml":  chartFile,
+			"mychart/values.yaml": values,
+		})
+		m, mdesc, blobs := testutils.CreateImage(ocispecv1.MediaTypeImageManifest, []byte("{}"), [][]byte{chart})
+		m.Layers[0].MediaType = processors.HelmChartContentLayerMediaType
+		for dgst, data := range blobs {
+			Expect(ociCache.Add(ocispecv1.Descriptor{Digest: dgst}, io.NopCloser(bytes.NewReader(data)))).To(Succeed())
+		}
+		ociArtifact, err := oci.NewManifestArtifact(&oci.Manifest{
+			Descriptor: mdesc,
+			Data:       m,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		serializedReader, err := utils.SerializeOCIArtifact(*ociArtifact, ociCache)
+		Expect(err).ToNot(HaveOccurred())
+		defer serializedReader.Close()
+		serialized, err := ioutil.ReadAll(serializedReader)
+		Expect(err).ToNot(HaveOccurred())
+
+		actualRes, actualBlob := process(serialized, false)
+
+		actualCache := cache.NewInMemoryCache()
+		actualOciArtifact, err := utils.DeserializeOCIArtifact(bytes.NewReader(actualBlob), actualCache)
+		Expect(err).ToNot(HaveOccurred())
+		actualManifest := actualOciArtifact.GetManifest().Data
+		Expect(actualManifest.Layers).To(HaveLen(1))
+		Expect(actualManifest.Layers[0].MediaType).To(Equal(processors.HelmChartContentLayerMediaType))
+
+		chartReader, err := actualCache.Get(actualManifest.Layers[0])
+		Expect(err).ToNot(HaveOccurred())
+		defer chartReader.Close()
+		files := readChart(chartReader)
+		Expect(readValues(files["mychart/values.yaml"])).To(HaveKeyWithValue("image", baseUrl+"/my-project/my-image:1.0.0"))
+
+		expectedDesc, err := ociclient.CreateDescriptorFromManifest(actualManifest)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actualRes.Digest).To(Equal(&cdv2.DigestSpec{
+			HashAlgorithm:          "sha256",
+			NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+			Value:                  expectedDesc.Digest.Encoded(),
+		}))
+	})
+
+	It("should remove the tempfiles of the relocated charts", func() {
+		tmpDir, err := ioutil.TempDir("", "helm-chart-relocator-")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmpDir)
+		oldTmpDir, hasTmpDir := os.LookupEnv("TMPDIR")
+		Expect(os.Setenv("TMPDIR", tmpDir)).To(Succeed())
+		defer func() {
+			if hasTmpDir {
+				os.Setenv("TMPDIR", oldTmpDir)
+			} else {
+				os.Unsetenv("TMPDIR")
+			}
+		}()
+
+		p, err := processors.NewHelmChartRelocator(ociCache, baseUrl, false, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		inBuf := bytes.NewBuffer([]byte{})
+		chart := createChart(map[string]string{
+			"mychart/Chart.yaml":  chartFile,
+			"mychart/values.yaml": values,
+		})
+		Expect(utils.WriteProcessorMessage(cd, res, bytes.NewReader(chart), inBuf)).To(Succeed())
+		Expect(p.Process(context.TODO(), inBuf, ioutil.Discard)).To(Succeed())
+
+		inBuf = bytes.NewBuffer([]byte{})
+		invalidChart := createChart(map[string]string{
+			"mychart/values.yaml": "image: [",
+		})
+		Expect(utils.WriteProcessorMessage(cd, res, bytes.NewReader(invalidChart), inBuf)).To(Succeed())
+		Expect(p.Process(context.TODO(), inBuf, ioutil.Discard)).To(MatchError(ContainSubstring("unable to relocate mychart/values.yaml")))
+
+		entries, err := ioutil.ReadDir(tmpDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("should return an error if the base url is empty", func() {
+		spec := json.RawMessage(`{"keepSourceRepo": true}`)
+		_, err := processors.NewProcessorFactory(ociCache).Create(processors.HelmChartRelocatorProcessorType, &spec)
+		Expect(err).To(MatchError("baseUrl must not be empty"))
+	})
+
+})
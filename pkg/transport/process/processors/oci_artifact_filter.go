@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/apis/v2/signatures"
+	"github.com/opencontainers/go-digest"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/oci"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+// PlatformSelector selects the manifests of an image index by their platform.
+// Empty fields match every value.
+type PlatformSelector struct {
+	OS           string `json:"os,omitempty"`
+	Architecture string `json:"architecture,omitempty"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Matches checks whether a platform is selected.
+func (s PlatformSelector) Matches(platform *ocispecv1.Platform) bool {
+	if platform == nil {
+		return false
+	}
+	return (s.OS == "" || s.OS == platform.OS) &&
+		(s.Architecture == "" || s.Architecture == platform.Architecture) &&
+		(s.Variant == "" || s.Variant == platform.Variant)
+}
+
+type ociArtifactFilter struct {
+	cache                 cache.Cache
+	platforms             []PlatformSelector
+	removeLayerMediaTypes map[string]bool
+}
+
+// NewOCIArtifactFilter returns a processor that filters a serialized oci artifact.
+// If platforms is not empty, only the manifests of an image index which match one of the platforms are kept.
+// Layers with one of the media types of removeLayerMediaTypes are removed from all manifests, the configs
+// of the manifests are not modified.
+// The digest of the resource is updated to the digest of the filtered oci artifact.
+func NewOCIArtifactFilter(cache cache.Cache, platforms []PlatformSelector, removeLayerMediaTypes []string) (process.ResourceStreamProcessor, error) {
+	if cache == nil {
+		return nil, errors.New("cache must not be nil")
+	}
+
+	mediaTypes := map[string]bool{}
+	for _, mediaType := range removeLayerMediaTypes {
+		mediaTypes[mediaType] = true
+	}
+
+	obj := ociArtifactFilter{
+		cache:                 cache,
+		platforms:             platforms,
+		removeLayerMediaTypes: mediaTypes,
+	}
+	return &obj, nil
+}
+
+func (f *ociArtifactFilter) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, resBlobReader, err := utils.ReadProcessorMessageStream(r)
+	if err != nil {
+		return fmt.Errorf("unable to read processor message: %w", err)
+	}
+	if resBlobReader == nil {
+		return errors.New("resource blob must not be nil")
+	}
+	defer resBlobReader.Close()
+
+	if res.Type != cdv2.OCIImageType {
+		return fmt.Errorf("unsupported resource type: %s", res.Type)
+	}
+
+	ociArtifact, err := utils.DeserializeOCIArtifact(resBlobReader, f.cache)
+	if err != nil {
+		return fmt.Errorf("unable to deserialize oci artifact: %w", err)
+	}
+
+	if err := f.filter(ociArtifact); err != nil {
+		return err
+	}
+
+	if res.Digest != nil {
+		dgst, err := artifactDigest(ociArtifact)
+		if err != nil {
+			return fmt.Errorf("unable to calculate digest of oci artifact: %w", err)
+		}
+		res.Digest = &cdv2.DigestSpec{
+			HashAlgorithm:          signatures.SHA256,
+			NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+			Value:                  dgst.Encoded(),
+		}
+	}
+
+	blobReader, err := utils.SerializeOCIArtifact(*ociArtifact, f.cache)
+	if err != nil {
+		return fmt.Errorf("unable to serialize oci artifact: %w", err)
+	}
+	defer blobReader.Close()
+
+	if err := utils.WriteProcessorMessage(*cd, res, blobReader, w); err != nil {
+		return fmt.Errorf("unable to write processor message: %w", err)
+	}
+
+	return nil
+}
+
+func (f *ociArtifactFilter) filter(ociArtifact *oci.Artifact) error {
+	if ociArtifact.IsManifest() {
+		f.filterLayers(ociArtifact.GetManifest())
+		return nil
+	}
+
+	index := ociArtifact.GetIndex()
+	manifests := []*oci.Manifest{}
+	for _, m := range index.Manifests {
+		if !f.keepManifest(m) {
+			continue
+		}
+		f.filterLayers(m)
+		manifests = append(manifests, m)
+	}
+	if len(manifests) == 0 {
+		return errors.New("no manifest of the image index matches the platforms")
+	}
+	index.Manifests = manifests
+	return nil
+}
+
+func (f *ociArtifactFilter) keepManifest(m *oci.Manifest) bool {
+	if len(f.platforms) == 0 {
+		return true
+	}
+	for _, platform := range f.platforms {
+		if platform.Matches(m.Descriptor.Platform) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ociArtifactFilter) filterLayers(m *oci.Manifest) {
+	if len(f.removeLayerMediaTypes) == 0 {
+		return
+	}
+	layers := []ocispecv1.Descriptor{}
+	for _, layer := range m.Data.Layers {
+		if !f.removeLayerMediaTypes[layer.MediaType] {
+			layers = append(layers, layer)
+		}
+	}
+	m.Data.Layers = layers
+}
+
+// artifactDigest calculates the digest of an oci artifact as it is pushed to a registry.
+func artifactDigest(ociArtifact *oci.Artifact) (digest.Digest, error) {
+	if ociArtifact.IsManifest() {
+		desc, err := ociclient.CreateDescriptorFromManifest(ociArtifact.GetManifest().Data)
+		if err != nil {
+			return "", err
+		}
+		return desc.Digest, nil
+	}
+	desc, _, err := ociclient.CreateDescriptorFromIndex(ociArtifact.GetIndex())
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest, nil
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package processors_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	ocispecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/gardener/component-cli/ociclient"
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/ociclient/oci"
+	"github.com/gardener/component-cli/pkg/testutils"
+	"github.com/gardener/component-cli/pkg/transport/process/processors"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+var _ = Describe("ociArtifactFilter", func() {
+
+	const sbomMediaType = "application/vnd.test.sbom"
+
+	var (
+		ociCache cache.Cache
+		cd       cdv2.ComponentDescriptor
+		res      cdv2.Resource
+	)
+
+	BeforeEach(func() {
+		ociCache = cache.NewInMemoryCache()
+		res = cdv2.Resource{
+			IdentityObjectMeta: cdv2.IdentityObjectMeta{
+				Name:    "my-res",
+				Version: "v0.1.0",
+				Type:    cdv2.OCIImageType,
+			},
+			Digest: &cdv2.DigestSpec{
+				HashAlgorithm:          "sha256",
+				NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+				Value:                  "00000000",
+			},
+		}
+		cd = cdv2.ComponentDescriptor{
+			ComponentSpec: cdv2.ComponentSpec{
+				Resources: []cdv2.Resource{
+					res,
+				},
+			},
+		}
+	})
+
+	// createManifest creates a manifest with an image layer and a sbom layer and adds its blobs to the cache.
+	createManifest := func(name string, platform *ocispecv1.Platform) *oci.Manifest {
+		m, mdesc, blobs := testutils.CreateImage(ocispecv1.MediaTypeImageManifest, []byte(name+"-config"), [][]byte{
+			[]byte(name + "-layer"),
+			[]byte(name + "-sbom"),
+		})
+		m.Layers[1].MediaType = sbomMediaType
+		for dgst, data := range blobs {
+			Expect(ociCache.Add(ocispecv1.Descriptor{Digest: dgst}, io.NopCloser(bytes.NewReader(data)))).To(Succeed())
+		}
+		mdesc.Platform = platform
+		return &oci.Manifest{
+			Descriptor: mdesc,
+			Data:       m,
+		}
+	}
+
+	process := func(ociArtifact *oci.Artifact, platforms []processors.PlatformSelector, removeLayerMediaTypes []string) (cdv2.Resource, *oci.Artifact, error) {
+		serializedReader, err := utils.SerializeOCIArtifact(*ociArtifact, ociCache)
+		Expect(err).ToNot(HaveOccurred())
+		defer serializedReader.Close()
+
+		inBuf := bytes.NewBuffer([]byte{})
+		Expect(utils.WriteProcessorMessage(cd, res, serializedReader, inBuf)).To(Succeed())
+
+		p, err := processors.NewOCIArtifactFilter(ociCache, platforms, removeLayerMediaTypes)
+		Expect(err).ToNot(HaveOccurred())
+
+		outBuf := bytes.NewBuffer([]byte{})
+		if err := p.Process(context.TODO(), inBuf, outBuf); err != nil {
+			return cdv2.Resource{}, nil, err
+		}
+
+		actualCd, actualRes, resBlobReader, err := utils.ReadProcessorMessage(outBuf)
+		Expect(err).ToNot(HaveOccurred())
+		defer resBlobReader.Close()
+		Expect(*actualCd).To(Equal(cd))
+
+		actualOciArtifact, err := utils.DeserializeOCIArtifact(resBlobReader, cache.NewInMemoryCache())
+		Expect(err).ToNot(HaveOccurred())
+		return actualRes, actualOciArtifact, nil
+	}
+
+	It("should remove the manifests of unwanted platforms from an image index", func() {
+		index, err := oci.NewIndexArtifact(&oci.Index{
+			Manifests: []*oci.Manifest{
+				createManifest("linux-amd64", &ocispecv1.Platform{OS: "linux", Architecture: "amd64"}),
+				createManifest("linux-arm64", &ocispecv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}),
+				createManifest("linux-s390x", &ocispecv1.Platform{OS: "linux", Architecture: "s390x"}),
+				createManifest("windows-amd64", &ocispecv1.Platform{OS: "windows", Architecture: "amd64"}),
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		platforms := []processors.PlatformSelector{
+			{OS: "linux", Architecture: "amd64"},
+			{OS: "linux", Architecture: "arm64"},
+		}
+		actualRes, actualOciArtifact, err := process(index, platforms, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(actualOciArtifact.IsIndex()).To(BeTrue())
+		actualPlatforms := []ocispecv1.Platform{}
+		for _, m := range actualOciArtifact.GetIndex().Manifests {
+			actualPlatforms = append(actualPlatforms, *m.Descriptor.Platform)
+			Expect(m.Data.Layers).To(HaveLen(2))
+		}
+		Expect(actualPlatforms).To(ConsistOf(
+			ocispecv1.Platform{OS: "linux", Architecture: "amd64"},
+			ocispecv1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		))
+
+		expectedDesc, _, err := ociclient.CreateDescriptorFromIndex(actualOciArtifact.GetIndex())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actualRes.Digest).To(Equal(&cdv2.DigestSpec{
+			HashAlgorithm:          "sha256",
+			NormalisationAlgorithm: string(cdv2.ManifestDigestV1),
+			Value:                  expectedDesc.Digest.Encoded(),
+		}))
+	})
+
+	It("should remove layers by media type from an image manifest", func() {
+		m := createManifest("linux-amd64", nil)
+		manifest, err := oci.NewManifestArtifact(m)
+		Expect(err).ToNot(HaveOccurred())
+		imageLayer := m.Data.Layers[0]
+
+		actualRes, actualOciArtifact, err := process(manifest, nil, []string{sbomMediaType})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(actualOciArtifact.IsManifest()).To(BeTrue())
+		Expect(actualOciArtifact.GetManifest().Data.Layers).To(Equal([]ocispecv1.Descriptor{imageLayer}))
+
+		expectedDesc, err := ociclient.CreateDescriptorFromManifest(actualOciArtifact.GetManifest().Data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actualRes.Digest.Value).To(Equal(expectedDesc.Digest.Encoded()))
+	})
+
+	It("should not set a digest if the resource has no digest", func() {
+		res.Digest = nil
+		manifest, err := oci.NewManifestArtifact(createManifest("linux-amd64", nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		actualRes, _, err := process(manifest, nil, []string{sbomMediaType})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actualRes.Digest).To(BeNil())
+	})
+
+	It("should return an error if no manifest matches the platforms", func() {
+		index, err := oci.NewIndexArtifact(&oci.Index{
+			Manifests: []*oci.Manifest{
+				createManifest("windows-amd64", &ocispecv1.Platform{OS: "windows", Architecture: "amd64"}),
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, err = process(index, []processors.PlatformSelector{{OS: "linux"}}, nil)
+		Expect(err).To(MatchError("no manifest of the image index matches the platforms"))
+	})
+
+	It("should return an error for an invalid spec", func() {
+		_, err := processors.NewProcessorFactory(ociCache).Create(processors.OCIArtifactFilterProcessorType, nil)
+		Expect(err).To(MatchError("spec must not be empty"))
+
+		spec := []byte(`{"platforms": []}`)
+		_, err = processors.NewProcessorFactory(ociCache).Create(processors.OCIArtifactFilterProcessorType, (*json.RawMessage)(&spec))
+		Expect(err).To(MatchError("platforms and removeLayerMediaTypes must not both be empty"))
+	})
+
+})
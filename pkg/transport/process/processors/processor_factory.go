@@ -0,0 +1,240 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package processors
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/extensions"
+)
+
+const (
+	// ResourceLabelerProcessorType defines the type of a resource labeler
+	ResourceLabelerProcessorType = "ResourceLabeler"
+
+	// SleepProcessorType defines the type of a sleep processor
+	SleepProcessorType = "Sleep"
+
+	// OCIArtifactFilterProcessorType defines the type of an oci artifact filter
+	OCIArtifactFilterProcessorType = "OciArtifactFilter"
+
+	// HelmChartRelocatorProcessorType defines the type of a helm chart relocator
+	HelmChartRelocatorProcessorType = "HelmChartRelocator"
+)
+
+// NewProcessorFactory creates a new processor factory
+// How to add a new processor (without using extension mechanism):
+// - Add Go file to processors package which contains the source code of the new processor
+// - Add string constant for new processor type -> will be used in ProcessorFactory.Create()
+// - Add source code for creating new processor to ProcessorFactory.Create() method
+func NewProcessorFactory(ocicache cache.Cache) *ProcessorFactory {
+	return &ProcessorFactory{
+		cache: ocicache,
+	}
+}
+
+// ProcessorFactory defines a helper struct for creating processors
+type ProcessorFactory struct {
+	cache cache.Cache
+}
+
+// Create creates a new processor defined by a type and a spec
+func (f *ProcessorFactory) Create(processorType string, spec *json.RawMessage) (process.ResourceStreamProcessor, error) {
+	switch processorType {
+	case ResourceLabelerProcessorType:
+		return f.createResourceLabeler(spec)
+	case SleepProcessorType:
+		return f.createSleep(spec)
+	case OCIArtifactFilterProcessorType:
+		return f.createOCIArtifactFilter(spec)
+	case HelmChartRelocatorProcessorType:
+		return f.createHelmChartRelocator(spec)
+	case extensions.ExecutableType:
+		return extensions.CreateExecutable(spec)
+	default:
+		return nil, fmt.Errorf("unknown processor type %s", processorType)
+	}
+}
+
+func (f *ProcessorFactory) createResourceLabeler(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
+	spec, err := parseResourceLabelerSpec(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResourceLabeler(spec.Labels...), nil
+}
+
+func (f *ProcessorFactory) createSleep(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
+	spec, err := parseSleepSpec(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSleep(spec.Duration.Duration)
+}
+
+func (f *ProcessorFactory) createOCIArtifactFilter(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
+	spec, err := parseOCIArtifactFilterSpec(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOCIArtifactFilter(f.cache, spec.Platforms, spec.RemoveLayerMediaTypes)
+}
+
+func (f *ProcessorFactory) createHelmChartRelocator(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
+	spec, err := parseHelmChartRelocatorSpec(rawSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHelmChartRelocator(f.cache, spec.BaseUrl, spec.KeepSourceRepo, spec.UseImageVectorLabels)
+}
+
+type resourceLabelerSpec struct {
+	Labels cdv2.Labels `json:"labels"`
+}
+
+func parseResourceLabelerSpec(rawSpec *json.RawMessage) (*resourceLabelerSpec, error) {
+	if rawSpec == nil {
+		return nil, fmt.Errorf("spec must not be empty")
+	}
+
+	var spec resourceLabelerSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if len(spec.Labels) == 0 {
+		return nil, fmt.Errorf("labels must not be empty")
+	}
+
+	return &spec, nil
+}
+
+type sleepSpec struct {
+	Duration metav1.Duration `json:"duration"`
+}
+
+func parseSleepSpec(rawSpec *json.RawMessage) (*sleepSpec, error) {
+	if rawSpec == nil {
+		return nil, fmt.Errorf("spec must not be empty")
+	}
+
+	var spec sleepSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if spec.Duration.Duration < 0 {
+		return nil, fmt.Errorf("duration must not be negative")
+	}
+
+	return &spec, nil
+}
+
+type ociArtifactFilterSpec struct {
+	Platforms             []PlatformSelector `json:"platforms"`
+	RemoveLayerMediaTypes []string           `json:"removeLayerMediaTypes"`
+}
+
+func parseOCIArtifactFilterSpec(rawSpec *json.RawMessage) (*ociArtifactFilterSpec, error) {
+	if rawSpec == nil {
+		return nil, fmt.Errorf("spec must not be empty")
+	}
+
+	var spec ociArtifactFilterSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if len(spec.Platforms) == 0 && len(spec.RemoveLayerMediaTypes) == 0 {
+		return nil, fmt.Errorf("platforms and removeLayerMediaTypes must not both be empty")
+	}
+
+	for i, platform := range spec.Platforms {
+		if platform == (PlatformSelector{}) {
+			return nil, fmt.Errorf("platform %d must not be empty", i)
+		}
+	}
+
+	return &spec, nil
+}
+
+type helmChartRelocatorSpec struct {
+	// BaseUrl is the base url the images have been transported to.
+	BaseUrl string `json:"baseUrl"`
+	// KeepSourceRepo must match the setting of the uploader which transports the images.
+	KeepSourceRepo bool `json:"keepSourceRepo"`
+	// UseImageVectorLabels additionally relocates the images of the imagevector labels of the component.
+	UseImageVectorLabels bool `json:"useImageVectorLabels"`
+}
+
+func parseHelmChartRelocatorSpec(rawSpec *json.RawMessage) (*helmChartRelocatorSpec, error) {
+	if rawSpec == nil {
+		return nil, fmt.Errorf("spec must not be empty")
+	}
+
+	var spec helmChartRelocatorSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if spec.BaseUrl == "" {
+		return nil, fmt.Errorf("baseUrl must not be empty")
+	}
+
+	return &spec, nil
+}
+
+// Validate validates the type and spec of a processor definition without creating the processor
+func Validate(fldPath *field.Path, processorType string, spec *json.RawMessage) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	var parseSpec func(*json.RawMessage) error
+	switch processorType {
+	case ResourceLabelerProcessorType:
+		parseSpec = func(rawSpec *json.RawMessage) error {
+			_, err := parseResourceLabelerSpec(rawSpec)
+			return err
+		}
+	case SleepProcessorType:
+		parseSpec = func(rawSpec *json.RawMessage) error {
+			_, err := parseSleepSpec(rawSpec)
+			return err
+		}
+	case OCIArtifactFilterProcessorType:
+		parseSpec = func(rawSpec *json.RawMessage) error {
+			_, err := parseOCIArtifactFilterSpec(rawSpec)
+			return err
+		}
+	case HelmChartRelocatorProcessorType:
+		parseSpec = func(rawSpec *json.RawMessage) error {
+			_, err := parseHelmChartRelocatorSpec(rawSpec)
+			return err
+		}
+	case extensions.ExecutableType:
+		return append(allErrs, extensions.ValidateExecutable(fldPath.Child("spec"), spec)...)
+	default:
+		supportedTypes := []string{ResourceLabelerProcessorType, SleepProcessorType, OCIArtifactFilterProcessorType, HelmChartRelocatorProcessorType, extensions.ExecutableType}
+		return append(allErrs, field.NotSupported(fldPath.Child("type"), processorType, supportedTypes))
+	}
+
+	if spec == nil {
+		return append(allErrs, field.Required(fldPath.Child("spec"), fmt.Sprintf("spec of processor type %s must not be empty", processorType)))
+	}
+	if err := parseSpec(spec); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(*spec), err.Error()))
+	}
+	return allErrs
+}
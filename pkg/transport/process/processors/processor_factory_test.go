@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package processors_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/ociclient/cache"
+	"github.com/gardener/component-cli/pkg/transport/process/processors"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+var _ = Describe("processorFactory", func() {
+
+	Context("Create", func() {
+
+		It("should create a resource labeler with the labels of the spec", func() {
+			spec := json.RawMessage(`{"labels": [{"name": "transported", "value": true}]}`)
+			p, err := processors.NewProcessorFactory(cache.NewInMemoryCache()).Create(processors.ResourceLabelerProcessorType, &spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			inBuf := bytes.NewBuffer([]byte{})
+			Expect(utils.WriteProcessorMessage(cdv2.ComponentDescriptor{}, cdv2.Resource{}, nil, inBuf)).To(Succeed())
+
+			outBuf := bytes.NewBuffer([]byte{})
+			Expect(p.Process(context.TODO(), inBuf, outBuf)).To(Succeed())
+
+			_, res, _, err := utils.ReadProcessorMessage(outBuf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Labels).To(Equal(cdv2.Labels{
+				{
+					Name:  "transported",
+					Value: json.RawMessage(`true`),
+				},
+			}))
+		})
+
+		It("should create a sleep processor which forwards the processor message", func() {
+			spec := json.RawMessage(`{"duration": "10ms"}`)
+			p, err := processors.NewProcessorFactory(cache.NewInMemoryCache()).Create(processors.SleepProcessorType, &spec)
+			Expect(err).ToNot(HaveOccurred())
+
+			inBuf := bytes.NewBuffer([]byte{})
+			Expect(utils.WriteProcessorMessage(cdv2.ComponentDescriptor{}, cdv2.Resource{}, nil, inBuf)).To(Succeed())
+			expected := inBuf.String()
+
+			outBuf := bytes.NewBuffer([]byte{})
+			start := time.Now()
+			Expect(p.Process(context.TODO(), inBuf, outBuf)).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically(">=", 10*time.Millisecond))
+			Expect(outBuf.String()).To(Equal(expected))
+		})
+
+		It("should return error for an invalid spec", func() {
+			spec := json.RawMessage(`{"labels": []}`)
+			_, err := processors.NewProcessorFactory(cache.NewInMemoryCache()).Create(processors.ResourceLabelerProcessorType, &spec)
+			Expect(err).To(MatchError("labels must not be empty"))
+
+			_, err = processors.NewProcessorFactory(cache.NewInMemoryCache()).Create("UnknownProcessor", &spec)
+			Expect(err).To(MatchError("unknown processor type UnknownProcessor"))
+		})
+
+	})
+
+})
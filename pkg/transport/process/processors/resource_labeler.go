@@ -27,7 +27,7 @@ func NewResourceLabeler(labels ...cdv2.Label) process.ResourceStreamProcessor {
 }
 
 func (p *resourceLabeler) Process(ctx context.Context, r io.Reader, w io.Writer) error {
-	cd, res, resBlobReader, err := utils.ReadProcessorMessage(r)
+	cd, res, resBlobReader, err := utils.ReadProcessorMessageStream(r)
 	if err != nil {
 		return fmt.Errorf("unable to read processor message: %w", err)
 	}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+)
+
+type sleep struct {
+	duration time.Duration
+}
+
+// NewSleep returns a processor that waits for a duration and then forwards the processor message unmodified
+func NewSleep(duration time.Duration) (process.ResourceStreamProcessor, error) {
+	if duration < 0 {
+		return nil, errors.New("duration must not be negative")
+	}
+
+	obj := sleep{
+		duration: duration,
+	}
+	return &obj, nil
+}
+
+func (p *sleep) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(p.duration):
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("unable to forward processor message: %w", err)
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"io"
 	"log"
 	"os"
@@ -12,40 +14,34 @@ import (
 	"time"
 
 	"github.com/gardener/component-cli/pkg/transport/process/extensions"
-	"github.com/gardener/component-cli/pkg/transport/process/utils"
 )
 
 const sleepTimeEnv = "SLEEP_TIME"
 
-// a test processor which sleeps for a configurable duration and then exits with an error.
+// a test processor which sleeps for a configurable duration and then fails with an error.
 func main() {
 	sleepTime, err := time.ParseDuration(os.Getenv(sleepTimeEnv))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	addr := os.Getenv(extensions.ProcessorServerAddressEnv)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if addr == "" {
-		time.Sleep(sleepTime)
-		log.Fatal("finished sleeping -> exit with error")
-	}
-
-	h := func(r io.Reader, w io.WriteCloser) {
-		time.Sleep(sleepTime)
-		log.Fatal("finished sleeping -> exit with error")
-	}
-
-	srv, err := utils.NewUnixDomainSocketServer(addr, h)
-	if err != nil {
+	if err := extensions.Serve(ctx, &sleepProcessor{sleepTime: sleepTime}); err != nil {
 		log.Fatal(err)
 	}
+}
 
-	srv.Start()
-
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+type sleepProcessor struct {
+	sleepTime time.Duration
+}
 
-	srv.Stop()
+func (p *sleepProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	select {
+	case <-time.After(p.sleepTime):
+		return errors.New("finished sleeping -> exit with error")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
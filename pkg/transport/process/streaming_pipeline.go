@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package process
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+type streamingResourceProcessingPipelineImpl struct {
+	processors []ResourceStreamProcessor
+}
+
+// NewStreamingResourceProcessingPipeline returns a new ResourceProcessingPipeline which connects adjacent
+// processors via pipes instead of writing the processor messages to temporary files.
+// All processors of the pipeline run concurrently, therefore the timeout of a processor covers the whole
+// processing of the resource. As processor messages are not persisted, failed processors can't be retried.
+func NewStreamingResourceProcessingPipeline(processors ...ResourceStreamProcessor) (ResourceProcessingPipeline, error) {
+	for _, proc := range processors {
+		if cp, ok := proc.(*configuredProcessor); ok && cp.opts.Retry != nil && cp.opts.Retry.MaxAttempts > 1 {
+			return nil, errors.New("retries are not supported in streaming mode")
+		}
+	}
+
+	p := streamingResourceProcessingPipelineImpl{
+		processors: processors,
+	}
+	return &p, nil
+}
+
+func (p *streamingResourceProcessingPipelineImpl) Process(ctx context.Context, cd cdv2.ComponentDescriptor, res cdv2.Resource) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	ctx, cancelfunc := context.WithCancel(ctx)
+	defer cancelfunc()
+
+	errs := &firstError{}
+	pipes := []*io.PipeReader{}
+	wg := sync.WaitGroup{}
+
+	inputReader, inputWriter := io.Pipe()
+	pipes = append(pipes, inputReader)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		err := utils.WriteProcessorMessage(cd, res, nil, inputWriter)
+		if err != nil {
+			err = fmt.Errorf("unable to write: %w", err)
+			errs.set(err)
+		}
+		inputWriter.CloseWithError(err)
+	}()
+
+	for _, proc := range p.processors {
+		outputReader, outputWriter := io.Pipe()
+		pipes = append(pipes, outputReader)
+
+		wg.Add(1)
+		go func(proc ResourceStreamProcessor, r *io.PipeReader, w *io.PipeWriter) {
+			defer wg.Done()
+			if err := runStreamingProcessor(ctx, proc, r, w); err != nil {
+				errs.set(err)
+				r.CloseWithError(err)
+				w.CloseWithError(err)
+				cancelfunc()
+				return
+			}
+			r.Close()
+			w.Close()
+		}(proc, inputReader, outputWriter)
+
+		inputReader = outputReader
+	}
+
+	// unblock all processors which are waiting for input or output if the processing is cancelled
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			for _, pipe := range pipes {
+				pipe.CloseWithError(ctx.Err())
+			}
+		case <-done:
+		}
+	}()
+
+	processedCD, processedRes, err := readPipelineResult(inputReader)
+	if err != nil {
+		errs.set(fmt.Errorf("unable to read output data: %w", err))
+		inputReader.CloseWithError(err)
+	}
+
+	wg.Wait()
+	close(done)
+
+	if err := errs.get(); err != nil {
+		return nil, cdv2.Resource{}, err
+	}
+
+	return processedCD, processedRes, nil
+}
+
+// runStreamingProcessor executes a processor once and consumes the rest of its input,
+// so that the preceding processor isn't blocked if the processor doesn't read the whole message.
+func runStreamingProcessor(ctx context.Context, proc ResourceStreamProcessor, r io.Reader, w io.Writer) error {
+	timeout := DefaultProcessorTimeout
+	if cp, ok := proc.(*configuredProcessor); ok {
+		if cp.opts.Timeout > 0 {
+			timeout = cp.opts.Timeout
+		}
+		proc = cp.ResourceStreamProcessor
+	}
+
+	ctx, cancelfunc := context.WithTimeout(ctx, timeout)
+	defer cancelfunc()
+
+	if err := proc.Process(ctx, r, w); err != nil {
+		return fmt.Errorf("unable to process resource: %w", err)
+	}
+
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return fmt.Errorf("unable to discard remaining input: %w", err)
+	}
+
+	return nil
+}
+
+func readPipelineResult(r io.Reader) (*cdv2.ComponentDescriptor, cdv2.Resource, error) {
+	cd, res, blobreader, err := utils.ReadProcessorMessageStream(r)
+	if err != nil {
+		return nil, cdv2.Resource{}, err
+	}
+	if blobreader != nil {
+		if err := blobreader.Close(); err != nil {
+			return nil, cdv2.Resource{}, err
+		}
+	}
+
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return nil, cdv2.Resource{}, err
+	}
+
+	return cd, res, nil
+}
+
+// firstError stores the first error which occurred in one of the concurrently running processors.
+// Errors of other processors are usually a consequence of the first one, e.g. closed pipes.
+type firstError struct {
+	mux sync.Mutex
+	err error
+}
+
+func (e *firstError) set(err error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *firstError) get() error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return e.err
+}
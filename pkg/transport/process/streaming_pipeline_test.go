@@ -0,0 +1,238 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package process_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/processors"
+	"github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+var _ = Describe("streaming pipeline", func() {
+
+	Context("Process", func() {
+
+		It("should correctly process resource", func() {
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "my-res",
+					Version: "v0.1.0",
+					Type:    "ociImage",
+				},
+			}
+			l1 := cdv2.Label{
+				Name:  "processor-0",
+				Value: json.RawMessage(`"true"`),
+			}
+			l2 := cdv2.Label{
+				Name:  "processor-1",
+				Value: json.RawMessage(`"true"`),
+			}
+			expectedRes := res
+			expectedRes.Labels = append(expectedRes.Labels, l1)
+			expectedRes.Labels = append(expectedRes.Labels, l2)
+
+			cd := cdv2.ComponentDescriptor{
+				ComponentSpec: cdv2.ComponentSpec{
+					Resources: []cdv2.Resource{
+						res,
+					},
+				},
+			}
+
+			sink := &blobSink{}
+			pipeline, err := process.NewStreamingResourceProcessingPipeline(
+				&blobSource{size: 1024 * 1024},
+				processors.NewResourceLabeler(l1),
+				processors.NewResourceLabeler(l2),
+				sink,
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			actualCD, actualRes, err := pipeline.Process(context.TODO(), cd, res)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*actualCD).To(Equal(cd))
+			Expect(actualRes).To(Equal(expectedRes))
+			Expect(sink.received).To(Equal(int64(1024 * 1024)))
+		})
+
+		It("should return the error of the failed processor", func() {
+			pipeline, err := process.NewStreamingResourceProcessingPipeline(
+				&blobSource{size: 1024 * 1024},
+				failingProcessor{},
+				processors.NewResourceLabeler(),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = pipeline.Process(context.TODO(), cdv2.ComponentDescriptor{}, cdv2.Resource{})
+			Expect(err).To(MatchError("unable to process resource: processor failed"))
+		})
+
+		It("should cancel a processor when the configured timeout is reached", func() {
+			opts := process.ProcessorOptions{
+				Timeout: 100 * time.Millisecond,
+			}
+			pipeline, err := process.NewStreamingResourceProcessingPipeline(
+				processors.NewResourceLabeler(),
+				process.WithProcessorOptions(blockingProcessor{}, opts),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = pipeline.Process(context.TODO(), cdv2.ComponentDescriptor{}, cdv2.Resource{})
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+
+		It("should return an error upon creation if retries are configured", func() {
+			opts := process.ProcessorOptions{
+				Retry: &process.RetryOptions{
+					MaxAttempts: 2,
+				},
+			}
+			_, err := process.NewStreamingResourceProcessingPipeline(process.WithProcessorOptions(processors.NewResourceLabeler(), opts))
+			Expect(err).To(MatchError("retries are not supported in streaming mode"))
+		})
+
+	})
+})
+
+// blobSource acts like a downloader and adds a resource blob of the given size to the processor message.
+type blobSource struct {
+	size int64
+}
+
+func (p *blobSource) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, blobreader, err := utils.ReadProcessorMessageStream(r)
+	if err != nil {
+		return err
+	}
+	if blobreader != nil {
+		defer blobreader.Close()
+	}
+
+	blob := io.LimitReader(zeroReader{}, p.size)
+	return utils.WriteProcessorMessage(*cd, res, bufio.NewReader(blob), w)
+}
+
+// blobSink acts like an uploader and consumes the resource blob of the processor message.
+type blobSink struct {
+	received int64
+}
+
+func (p *blobSink) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, blobreader, err := utils.ReadProcessorMessageStream(r)
+	if err != nil {
+		return err
+	}
+	if blobreader == nil {
+		return errors.New("resource blob must not be nil")
+	}
+	defer blobreader.Close()
+
+	if p.received, err = io.Copy(ioutil.Discard, blobreader); err != nil {
+		return err
+	}
+
+	return utils.WriteProcessorMessage(*cd, res, nil, w)
+}
+
+type failingProcessor struct{}
+
+func (p failingProcessor) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	return errors.New("processor failed")
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+const benchmarkBlobSize = 64 * 1024 * 1024
+
+func BenchmarkFilePipeline(b *testing.B) {
+	benchmarkPipeline(b, func(procs ...process.ResourceStreamProcessor) (process.ResourceProcessingPipeline, error) {
+		return process.NewResourceProcessingPipeline(procs...), nil
+	})
+}
+
+func BenchmarkStreamingPipeline(b *testing.B) {
+	benchmarkPipeline(b, process.NewStreamingResourceProcessingPipeline)
+}
+
+// benchmarkPipeline runs a pipeline of a downloader, two processors and an uploader.
+// Besides the runtime, the number of bytes written by the process (e.g. to temporary files) is reported.
+func benchmarkPipeline(b *testing.B, newPipeline func(...process.ResourceStreamProcessor) (process.ResourceProcessingPipeline, error)) {
+	pipeline, err := newPipeline(
+		&blobSource{size: benchmarkBlobSize},
+		processors.NewResourceLabeler(),
+		processors.NewResourceLabeler(),
+		&blobSink{},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	_, measureWrites := readWrittenBytes()
+	var written int64
+
+	b.SetBytes(benchmarkBlobSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		before, _ := readWrittenBytes()
+		if _, _, err := pipeline.Process(context.TODO(), cdv2.ComponentDescriptor{}, cdv2.Resource{}); err != nil {
+			b.Fatal(err)
+		}
+		after, _ := readWrittenBytes()
+		written += after - before
+	}
+
+	if measureWrites {
+		b.ReportMetric(float64(written)/float64(b.N), "written-B/op")
+	}
+}
+
+// readWrittenBytes returns the number of bytes which the current process has passed to write syscalls.
+// The second return value is false if the information isn't available on the current platform.
+func readWrittenBytes() (int64, bool) {
+	data, err := os.ReadFile("/proc/self/io")
+	if err != nil {
+		return 0, false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "wchar:") {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "wchar:")), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+
+	return 0, false
+}
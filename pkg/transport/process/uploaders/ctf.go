@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package uploaders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+type ctfUploader struct {
+	ctf *processutils.CTFWriter
+}
+
+// NewCTFUploader creates a new uploader which writes resource blobs as local blobs into the component archives
+// of a target CTF. Resources with access type ociRegistry are converted to local blobs which contain the serialized
+// oci artifact, resources with access type web, s3 or localOciBlob are converted to local blobs which contain the
+// downloaded file. The original access is kept in the label "transport.gardener.cloud/original-access".
+// Resources with access type localFilesystemBlob, e.g. from a source CTF, are written unchanged.
+func NewCTFUploader(targetCTF *processutils.CTFWriter) (process.ResourceStreamProcessor, error) {
+	if targetCTF == nil {
+		return nil, errors.New("targetCTF must not be nil")
+	}
+
+	obj := ctfUploader{
+		ctf: targetCTF,
+	}
+	return &obj, nil
+}
+
+func (u *ctfUploader) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, resBlobReader, err := processutils.ReadProcessorMessageStream(r)
+	if err != nil {
+		return fmt.Errorf("unable to read processor message: %w", err)
+	}
+	if resBlobReader == nil {
+		return errors.New("resource blob must not be nil")
+	}
+	defer resBlobReader.Close()
+
+	switch res.Access.GetType() {
+	case cdv2.OCIRegistryType, cdv2.WebType, cdv2.S3AccessType, cdv2.LocalOCIBlobType:
+		originalAccess, err := json.Marshal(res.Access)
+		if err != nil {
+			return fmt.Errorf("unable to marshal resource access: %w", err)
+		}
+		res.Labels = setLabel(res.Labels, processutils.OriginalAccessLabel, originalAccess)
+	case cdv2.LocalFilesystemBlobType:
+	default:
+		return fmt.Errorf("unsupported access type: %s", res.Access.Type)
+	}
+
+	caFs, err := u.ctf.ComponentArchiveFS(cd.Name, cd.Version)
+	if err != nil {
+		return err
+	}
+	dgst, err := writeBlob(caFs, resBlobReader)
+	if err != nil {
+		return err
+	}
+
+	acc, err := cdv2.NewUnstructured(cdv2.NewLocalFilesystemBlobAccess(dgst.String(), res.Type))
+	if err != nil {
+		return fmt.Errorf("unable to create resource access object: %w", err)
+	}
+	res.Access = &acc
+
+	blob, err := caFs.Open(ctf.BlobPath(dgst.String()))
+	if err != nil {
+		return fmt.Errorf("unable to open blob: %w", err)
+	}
+	defer blob.Close()
+
+	if err := processutils.WriteProcessorMessage(*cd, res, blob, w); err != nil {
+		return fmt.Errorf("unable to write processor message: %w", err)
+	}
+
+	return nil
+}
+
+// writeBlob writes a blob to the blobs directory of a component archive. The blob is written to a temporary
+// file outside of the blobs directory first, as the name of the blob is derived from its digest.
+func writeBlob(caFs vfs.FileSystem, r io.Reader) (digest.Digest, error) {
+	tmpfile, err := vfs.TempFile(caFs, "/", ".tmp-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create tempfile: %w", err)
+	}
+	defer caFs.Remove(tmpfile.Name())
+
+	digester := digest.Canonical.Digester()
+	if _, err := io.Copy(io.MultiWriter(tmpfile, digester.Hash()), r); err != nil {
+		tmpfile.Close()
+		return "", fmt.Errorf("unable to write blob: %w", err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		return "", fmt.Errorf("unable to close blob: %w", err)
+	}
+
+	dgst := digester.Digest()
+	if err := caFs.Rename(tmpfile.Name(), ctf.BlobPath(dgst.String())); err != nil {
+		return "", fmt.Errorf("unable to rename blob: %w", err)
+	}
+	return dgst, nil
+}
+
+// setLabel sets the value of a label, an existing label with the same name is overwritten.
+func setLabel(labels cdv2.Labels, name string, value json.RawMessage) cdv2.Labels {
+	for i := range labels {
+		if labels[i].Name == name {
+			labels[i].Value = value
+			return labels
+		}
+	}
+	return append(labels, cdv2.Label{
+		Name:  name,
+		Value: value,
+	})
+}
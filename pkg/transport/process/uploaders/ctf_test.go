@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package uploaders_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	"github.com/mandelsoft/vfs/pkg/memoryfs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/gardener/component-cli/pkg/transport/process/downloaders"
+	"github.com/gardener/component-cli/pkg/transport/process/uploaders"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+)
+
+var _ = Describe("ctf", func() {
+
+	Context("Process", func() {
+
+		It("should write an oci artifact as local blob into a ctf and restore it with the ctf downloader", func() {
+			fs := memoryfs.New()
+			resBytes := []byte("serialized oci artifact")
+			expectedDigest := digest.FromBytes(resBytes)
+
+			acc, err := cdv2.NewUnstructured(cdv2.NewOCIRegistryAccess("example.com/image:0.1.0"))
+			Expect(err).ToNot(HaveOccurred())
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "image",
+					Version: "0.1.0",
+					Type:    cdv2.OCIImageType,
+				},
+				Relation: cdv2.ExternalRelation,
+				Access:   &acc,
+			}
+			cd := cdv2.ComponentDescriptor{
+				Metadata: cdv2.Metadata{
+					Version: cdv2.SchemaVersion,
+				},
+				ComponentSpec: cdv2.ComponentSpec{
+					ObjectMeta: cdv2.ObjectMeta{
+						Name:    "github.com/component-cli/test-component",
+						Version: "0.1.0",
+					},
+					Provider: cdv2.InternalProvider,
+					Resources: []cdv2.Resource{
+						res,
+					},
+				},
+			}
+			Expect(cdv2.DefaultComponent(&cd)).To(Succeed())
+
+			inProcessorMsg := bytes.NewBuffer([]byte{})
+			Expect(processutils.WriteProcessorMessage(cd, res, bytes.NewReader(resBytes), inProcessorMsg)).To(Succeed())
+
+			targetCTF, err := processutils.NewCTFWriter(fs, "/ctf.tar")
+			Expect(err).ToNot(HaveOccurred())
+			u, err := uploaders.NewCTFUploader(targetCTF)
+			Expect(err).ToNot(HaveOccurred())
+			outProcessorMsg := bytes.NewBuffer([]byte{})
+			Expect(u.Process(context.TODO(), inProcessorMsg, outProcessorMsg)).To(Succeed())
+
+			_, uploadedRes, resBlobReader, err := processutils.ReadProcessorMessage(outProcessorMsg)
+			Expect(err).ToNot(HaveOccurred())
+			defer resBlobReader.Close()
+			Expect(uploadedRes.Access.Type).To(Equal(cdv2.LocalFilesystemBlobType))
+			localFilesystemBlobAccess := cdv2.LocalFilesystemBlobAccess{}
+			Expect(uploadedRes.Access.DecodeInto(&localFilesystemBlobAccess)).To(Succeed())
+			Expect(localFilesystemBlobAccess.Filename).To(Equal(expectedDigest.String()))
+			Expect(uploadedRes.Labels).To(HaveLen(1))
+			Expect(uploadedRes.Labels[0].Name).To(Equal(processutils.OriginalAccessLabel))
+
+			uploadedCD := cd.DeepCopy()
+			uploadedCD.Resources = []cdv2.Resource{uploadedRes}
+			Expect(targetCTF.AddComponentDescriptor(uploadedCD)).To(Succeed())
+			Expect(targetCTF.Write()).To(Succeed())
+			Expect(targetCTF.Close()).To(Succeed())
+
+			// the ctf can be read by everything which reads component archives
+			archive, err := ctf.NewCTF(fs, "/ctf.tar")
+			Expect(err).ToNot(HaveOccurred())
+			defer archive.Close()
+			cas := 0
+			Expect(archive.Walk(func(ca *ctf.ComponentArchive) error {
+				cas++
+				Expect(ca.ComponentDescriptor.Name).To(Equal(cd.Name))
+				blob := bytes.NewBuffer([]byte{})
+				_, err := ca.BlobResolver.Resolve(context.TODO(), ca.ComponentDescriptor.Resources[0], blob)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blob.Bytes()).To(Equal(resBytes))
+				return nil
+			})).To(Succeed())
+			Expect(cas).To(Equal(1))
+
+			sourceCTF, err := processutils.ReadCTF(fs, "/ctf.tar")
+			Expect(err).ToNot(HaveOccurred())
+			defer sourceCTF.Close()
+			d, err := downloaders.NewCTFDownloader(sourceCTF)
+			Expect(err).ToNot(HaveOccurred())
+			inProcessorMsg = bytes.NewBuffer([]byte{})
+			Expect(processutils.WriteProcessorMessage(cd, uploadedRes, nil, inProcessorMsg)).To(Succeed())
+			outProcessorMsg = bytes.NewBuffer([]byte{})
+			Expect(d.Process(context.TODO(), inProcessorMsg, outProcessorMsg)).To(Succeed())
+
+			_, downloadedRes, resBlobReader, err := processutils.ReadProcessorMessage(outProcessorMsg)
+			Expect(err).ToNot(HaveOccurred())
+			defer resBlobReader.Close()
+			Expect(downloadedRes.Access.Type).To(Equal(cdv2.OCIRegistryType))
+			ociRegistryAccess := cdv2.OCIRegistryAccess{}
+			Expect(downloadedRes.Access.DecodeInto(&ociRegistryAccess)).To(Succeed())
+			Expect(ociRegistryAccess.ImageReference).To(Equal("example.com/image:0.1.0"))
+			Expect(downloadedRes.Labels).To(BeEmpty())
+			downloadedBytes, err := ioutil.ReadAll(resBlobReader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(downloadedBytes).To(Equal(resBytes))
+		})
+
+		It("should return an error for unsupported access types", func() {
+			acc, err := cdv2.NewUnstructured(cdv2.NewGitHubAccess("https://github.com/gardener/component-cli", "main", "abc"))
+			Expect(err).ToNot(HaveOccurred())
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "repository",
+					Version: "0.1.0",
+					Type:    "git",
+				},
+				Access: &acc,
+			}
+			cd := cdv2.ComponentDescriptor{}
+
+			inProcessorMsg := bytes.NewBuffer([]byte{})
+			Expect(processutils.WriteProcessorMessage(cd, res, bytes.NewReader([]byte("content")), inProcessorMsg)).To(Succeed())
+
+			targetCTF, err := processutils.NewCTFWriter(memoryfs.New(), "/ctf.tar")
+			Expect(err).ToNot(HaveOccurred())
+			defer targetCTF.Close()
+			u, err := uploaders.NewCTFUploader(targetCTF)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(u.Process(context.TODO(), inProcessorMsg, bytes.NewBuffer([]byte{}))).To(MatchError("unsupported access type: github"))
+		})
+
+	})
+
+})
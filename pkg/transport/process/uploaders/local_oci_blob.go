@@ -38,7 +38,7 @@ func NewLocalOCIBlobUploader(client ociclient.Client, targetCtx cdv2.OCIRegistry
 }
 
 func (d *localOCIBlobUploader) Process(ctx context.Context, r io.Reader, w io.Writer) error {
-	cd, res, blobreader, err := processutils.ReadProcessorMessage(r)
+	cd, res, blobreader, err := processutils.ReadProcessorMessageStream(r)
 	if err != nil {
 		return fmt.Errorf("unable to read processor message: %w", err)
 	}
@@ -48,7 +48,7 @@ func NewOCIArtifactUploader(client ociclient.Client, cache cache.Cache, baseUrl
 }
 
 func (u *ociArtifactUploader) Process(ctx context.Context, r io.Reader, w io.Writer) error {
-	cd, res, resBlobReader, err := processutils.ReadProcessorMessage(r)
+	cd, res, resBlobReader, err := processutils.ReadProcessorMessageStream(r)
 	if err != nil {
 		return fmt.Errorf("unable to read processor message: %w", err)
 	}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package uploaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+
+	"github.com/gardener/component-cli/pkg/transport/process"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/transport/s3"
+)
+
+type s3Uploader struct {
+	client    *s3.Client
+	bucket    string
+	keyPrefix string
+}
+
+// NewS3Uploader creates a new uploader which writes resources into a bucket of an S3 compatible object store.
+// Resources with access type s3 keep their object key, resources with access type web are stored with the
+// path of their url as object key. The object key is prefixed with keyPrefix.
+// The access of the resource is set to the uploaded object.
+func NewS3Uploader(client *s3.Client, bucket, keyPrefix string) (process.ResourceStreamProcessor, error) {
+	if client == nil {
+		return nil, errors.New("client must not be nil")
+	}
+
+	if bucket == "" {
+		return nil, errors.New("bucket must not be empty")
+	}
+
+	obj := s3Uploader{
+		client:    client,
+		bucket:    bucket,
+		keyPrefix: keyPrefix,
+	}
+	return &obj, nil
+}
+
+func (u *s3Uploader) Process(ctx context.Context, r io.Reader, w io.Writer) error {
+	cd, res, blobreader, err := processutils.ReadProcessorMessageStream(r)
+	if err != nil {
+		return fmt.Errorf("unable to read processor message: %w", err)
+	}
+	if blobreader == nil {
+		return errors.New("resource blob must not be nil")
+	}
+	defer blobreader.Close()
+
+	key, err := u.objectKey(res)
+	if err != nil {
+		return err
+	}
+
+	// S3 requires the size of an object in advance, therefore the blob is buffered in a tempfile
+	tmpfile, err := ioutil.TempFile("", "")
+	if err != nil {
+		return fmt.Errorf("unable to create tempfile: %w", err)
+	}
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+
+	size, err := io.Copy(tmpfile, blobreader)
+	if err != nil {
+		return fmt.Errorf("unable to copy resource blob to tempfile: %w", err)
+	}
+
+	if _, err := tmpfile.Seek(0, 0); err != nil {
+		return fmt.Errorf("unable to seek to beginning of tempfile: %w", err)
+	}
+
+	if err := u.client.PutObject(ctx, u.bucket, key, tmpfile, size, ""); err != nil {
+		return fmt.Errorf("unable to upload object: %w", err)
+	}
+
+	acc, err := cdv2.NewUnstructured(cdv2.NewS3Access(u.bucket, key))
+	if err != nil {
+		return fmt.Errorf("unable to create resource access object: %w", err)
+	}
+	res.Access = &acc
+
+	if _, err := tmpfile.Seek(0, 0); err != nil {
+		return fmt.Errorf("unable to seek to beginning of tempfile: %w", err)
+	}
+
+	if err := processutils.WriteProcessorMessage(*cd, res, tmpfile, w); err != nil {
+		return fmt.Errorf("unable to write processor message: %w", err)
+	}
+
+	return nil
+}
+
+// objectKey returns the target object key of a resource.
+func (u *s3Uploader) objectKey(res cdv2.Resource) (string, error) {
+	var key string
+	switch res.Access.GetType() {
+	case cdv2.S3AccessType:
+		s3Access := &cdv2.S3Access{}
+		if err := res.Access.DecodeInto(s3Access); err != nil {
+			return "", fmt.Errorf("unable to decode resource access: %w", err)
+		}
+		key = s3Access.ObjectKey
+	case cdv2.WebType:
+		webAccess := &cdv2.Web{}
+		if err := res.Access.DecodeInto(webAccess); err != nil {
+			return "", fmt.Errorf("unable to decode resource access: %w", err)
+		}
+		u, err := url.Parse(webAccess.URL)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse url %s: %w", webAccess.URL, err)
+		}
+		key = u.Path
+	default:
+		return "", fmt.Errorf("unsupported access type: %s", res.Access.Type)
+	}
+
+	key = strings.TrimPrefix(path.Join(u.keyPrefix, key), "/")
+	if key == "" || key == "." {
+		return "", fmt.Errorf("unable to derive object key for resource %s", res.Name)
+	}
+	return key, nil
+}
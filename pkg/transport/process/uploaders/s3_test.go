@@ -0,0 +1,172 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package uploaders_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/pkg/testutils"
+	"github.com/gardener/component-cli/pkg/transport/process"
+	"github.com/gardener/component-cli/pkg/transport/process/downloaders"
+	"github.com/gardener/component-cli/pkg/transport/process/uploaders"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/transport/s3"
+)
+
+var _ = Describe("s3", func() {
+
+	var (
+		credentials = s3.Credentials{
+			AccessKeyID:     "access-key-id",
+			SecretAccessKey: "secret-access-key",
+		}
+		server   *testutils.S3Server
+		s3Client *s3.Client
+	)
+
+	BeforeEach(func() {
+		server = testutils.NewS3Server("eu-west-1", &credentials)
+		var err error
+		s3Client, err = s3.NewClient(server.Client(), s3.Config{Endpoint: server.URL, Region: "eu-west-1", PathStyle: true}, &credentials)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	process := func(p process.ResourceStreamProcessor, cd cdv2.ComponentDescriptor, res cdv2.Resource, blob []byte) (cdv2.Resource, []byte) {
+		var blobReader io.Reader
+		if blob != nil {
+			blobReader = bytes.NewReader(blob)
+		}
+		inProcessorMsg := bytes.NewBuffer([]byte{})
+		Expect(processutils.WriteProcessorMessage(cd, res, blobReader, inProcessorMsg)).To(Succeed())
+
+		outProcessorMsg := bytes.NewBuffer([]byte{})
+		Expect(p.Process(context.TODO(), inProcessorMsg, outProcessorMsg)).To(Succeed())
+
+		_, processedRes, resBlobReader, err := processutils.ReadProcessorMessage(outProcessorMsg)
+		Expect(err).ToNot(HaveOccurred())
+		defer resBlobReader.Close()
+		processedBlob, err := ioutil.ReadAll(resBlobReader)
+		Expect(err).ToNot(HaveOccurred())
+		return processedRes, processedBlob
+	}
+
+	newResource := func(access cdv2.TypedObjectAccessor) (cdv2.ComponentDescriptor, cdv2.Resource) {
+		acc, err := cdv2.NewUnstructured(access)
+		Expect(err).ToNot(HaveOccurred())
+		res := cdv2.Resource{
+			IdentityObjectMeta: cdv2.IdentityObjectMeta{
+				Name:    "archive",
+				Version: "0.1.0",
+				Type:    "archive",
+			},
+			Access: &acc,
+		}
+		cd := cdv2.ComponentDescriptor{
+			ComponentSpec: cdv2.ComponentSpec{
+				ObjectMeta: cdv2.ObjectMeta{
+					Name:    "github.com/component-cli/test-component",
+					Version: "0.1.0",
+				},
+				Resources: []cdv2.Resource{
+					res,
+				},
+			},
+		}
+		return cd, res
+	}
+
+	Context("Process", func() {
+
+		It("should download an s3 object and upload it into the target bucket", func() {
+			resBytes := []byte("archive data")
+			server.PutObject("source-bucket", "archives/archive.tgz", resBytes)
+			cd, res := newResource(cdv2.NewS3Access("source-bucket", "archives/archive.tgz"))
+
+			d, err := downloaders.NewS3Downloader(s3Client)
+			Expect(err).ToNot(HaveOccurred())
+			downloadedRes, downloadedBlob := process(d, cd, res, nil)
+			Expect(downloadedRes.Access.Type).To(Equal(cdv2.S3AccessType))
+			Expect(downloadedBlob).To(Equal(resBytes))
+
+			u, err := uploaders.NewS3Uploader(s3Client, "target-bucket", "mirror")
+			Expect(err).ToNot(HaveOccurred())
+			uploadedRes, uploadedBlob := process(u, cd, downloadedRes, downloadedBlob)
+			Expect(uploadedBlob).To(Equal(resBytes))
+
+			s3Access := cdv2.S3Access{}
+			Expect(uploadedRes.Access.DecodeInto(&s3Access)).To(Succeed())
+			Expect(s3Access.BucketName).To(Equal("target-bucket"))
+			Expect(s3Access.ObjectKey).To(Equal("mirror/archives/archive.tgz"))
+
+			obj, ok := server.Object("target-bucket", "mirror/archives/archive.tgz")
+			Expect(ok).To(BeTrue())
+			Expect(obj).To(Equal(resBytes))
+		})
+
+		It("should download a web resource and upload it into the target bucket", func() {
+			resBytes := []byte("archive data")
+			web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/downloads/archive.tgz" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write(resBytes)
+			}))
+			defer web.Close()
+			cd, res := newResource(newWebAccess(web.URL + "/downloads/archive.tgz"))
+
+			d, err := downloaders.NewWebDownloader(web.Client())
+			Expect(err).ToNot(HaveOccurred())
+			downloadedRes, downloadedBlob := process(d, cd, res, nil)
+			Expect(downloadedBlob).To(Equal(resBytes))
+
+			u, err := uploaders.NewS3Uploader(s3Client, "target-bucket", "")
+			Expect(err).ToNot(HaveOccurred())
+			uploadedRes, _ := process(u, cd, downloadedRes, downloadedBlob)
+
+			Expect(uploadedRes.Access.Type).To(Equal(cdv2.S3AccessType))
+			obj, ok := server.Object("target-bucket", "downloads/archive.tgz")
+			Expect(ok).To(BeTrue())
+			Expect(obj).To(Equal(resBytes))
+		})
+
+		It("should return an error if a web resource can't be downloaded", func() {
+			web := httptest.NewServer(http.NotFoundHandler())
+			defer web.Close()
+			cd, res := newResource(newWebAccess(web.URL + "/missing.tgz"))
+
+			d, err := downloaders.NewWebDownloader(web.Client())
+			Expect(err).ToNot(HaveOccurred())
+
+			inProcessorMsg := bytes.NewBuffer([]byte{})
+			Expect(processutils.WriteProcessorMessage(cd, res, nil, inProcessorMsg)).To(Succeed())
+			err = d.Process(context.TODO(), inProcessorMsg, bytes.NewBuffer([]byte{}))
+			Expect(err).To(MatchError(ContainSubstring("response code 404")))
+		})
+
+	})
+})
+
+// newWebAccess creates a web access. cdv2.NewWebAccess can't be used as it sets the wrong type.
+func newWebAccess(url string) *cdv2.Web {
+	return &cdv2.Web{
+		ObjectType: cdv2.ObjectType{
+			Type: cdv2.WebType,
+		},
+		URL: url,
+	}
+}
@@ -5,15 +5,20 @@ package uploaders
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/yaml"
 
 	"github.com/gardener/component-cli/ociclient"
 	"github.com/gardener/component-cli/ociclient/cache"
 	"github.com/gardener/component-cli/pkg/transport/process"
 	"github.com/gardener/component-cli/pkg/transport/process/extensions"
+	processutils "github.com/gardener/component-cli/pkg/transport/process/utils"
+	"github.com/gardener/component-cli/pkg/transport/s3"
 )
 
 const (
@@ -22,6 +27,12 @@ const (
 
 	// OCIArtifactUploaderType defines the type of an oci artifact uploader
 	OCIArtifactUploaderType = "OciArtifactUploader"
+
+	// CTFUploaderType defines the type of a CTF uploader
+	CTFUploaderType = "CtfUploader"
+
+	// S3UploaderType defines the type of an S3 uploader
+	S3UploaderType = "S3Uploader"
 )
 
 // NewUploaderFactory creates a new uploader factory
@@ -31,17 +42,36 @@ const (
 // - Add source code for creating new uploader to UploaderFactory.Create() method
 func NewUploaderFactory(client ociclient.Client, ocicache cache.Cache, targetCtx cdv2.OCIRegistryRepository) *UploaderFactory {
 	return &UploaderFactory{
-		client:    client,
-		cache:     ocicache,
-		targetCtx: targetCtx,
+		client:     client,
+		cache:      ocicache,
+		targetCtx:  targetCtx,
+		httpClient: http.DefaultClient,
 	}
 }
 
 // UploaderFactory defines a helper struct for creating uploaders
 type UploaderFactory struct {
-	client    ociclient.Client
-	cache     cache.Cache
-	targetCtx cdv2.OCIRegistryRepository
+	client     ociclient.Client
+	cache      cache.Cache
+	targetCtx  cdv2.OCIRegistryRepository
+	targetCTF  *processutils.CTFWriter
+	httpClient *http.Client
+}
+
+// WithTargetRepository returns a copy of the factory whose uploaders upload to another target repository.
+// The copy has no target CTF.
+func (f *UploaderFactory) WithTargetRepository(targetCtx cdv2.OCIRegistryRepository) *UploaderFactory {
+	factory := *f
+	factory.targetCtx = targetCtx
+	factory.targetCTF = nil
+	return &factory
+}
+
+// WithTargetCTF returns a copy of the factory whose CTF uploaders write into a target CTF.
+func (f *UploaderFactory) WithTargetCTF(targetCTF *processutils.CTFWriter) *UploaderFactory {
+	factory := *f
+	factory.targetCTF = targetCTF
+	return &factory
 }
 
 // Create creates a new uploader defined by a type and a spec
@@ -51,6 +81,13 @@ func (f *UploaderFactory) Create(uploaderType string, spec *json.RawMessage) (pr
 		return NewLocalOCIBlobUploader(f.client, f.targetCtx)
 	case OCIArtifactUploaderType:
 		return f.createOCIArtifactUploader(spec)
+	case CTFUploaderType:
+		if f.targetCTF == nil {
+			return nil, errors.New("a ctf uploader requires a target ctf")
+		}
+		return NewCTFUploader(f.targetCTF)
+	case S3UploaderType:
+		return f.createS3Uploader(spec)
 	case extensions.ExecutableType:
 		return extensions.CreateExecutable(spec)
 	default:
@@ -59,16 +96,109 @@ func (f *UploaderFactory) Create(uploaderType string, spec *json.RawMessage) (pr
 }
 
 func (f *UploaderFactory) createOCIArtifactUploader(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
-	type uploaderSpec struct {
-		BaseUrl        string `json:"baseUrl"`
-		KeepSourceRepo bool   `json:"keepSourceRepo"`
+	spec, err := parseOCIArtifactUploaderSpec(rawSpec)
+	if err != nil {
+		return nil, err
 	}
 
-	var spec uploaderSpec
-	err := yaml.Unmarshal(*rawSpec, &spec)
+	return NewOCIArtifactUploader(f.client, f.cache, spec.BaseUrl, spec.KeepSourceRepo)
+}
+
+func (f *UploaderFactory) createS3Uploader(rawSpec *json.RawMessage) (process.ResourceStreamProcessor, error) {
+	spec, err := parseS3UploaderSpec(rawSpec)
 	if err != nil {
+		return nil, err
+	}
+
+	client, err := s3.NewClient(f.httpClient, spec.Config, s3.CredentialsFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create s3 client: %w", err)
+	}
+
+	return NewS3Uploader(client, spec.BucketName, spec.KeyPrefix)
+}
+
+type ociArtifactUploaderSpec struct {
+	BaseUrl        string `json:"baseUrl"`
+	KeepSourceRepo bool   `json:"keepSourceRepo"`
+}
+
+func parseOCIArtifactUploaderSpec(rawSpec *json.RawMessage) (*ociArtifactUploaderSpec, error) {
+	if rawSpec == nil {
+		return nil, fmt.Errorf("spec must not be empty")
+	}
+
+	var spec ociArtifactUploaderSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
 		return nil, fmt.Errorf("unable to parse spec: %w", err)
 	}
 
-	return NewOCIArtifactUploader(f.client, f.cache, spec.BaseUrl, spec.KeepSourceRepo)
+	if spec.BaseUrl == "" {
+		return nil, fmt.Errorf("baseUrl must not be empty")
+	}
+
+	return &spec, nil
+}
+
+// s3UploaderSpec is the spec of an S3 uploader.
+// Credentials are read from the environment variables AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN.
+type s3UploaderSpec struct {
+	s3.Config `json:",inline"`
+	// BucketName is the name of the target bucket.
+	BucketName string `json:"bucketName"`
+	// KeyPrefix is prepended to the object keys of the uploaded resources.
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+}
+
+func parseS3UploaderSpec(rawSpec *json.RawMessage) (*s3UploaderSpec, error) {
+	if rawSpec == nil {
+		return nil, fmt.Errorf("spec must not be empty")
+	}
+
+	var spec s3UploaderSpec
+	if err := yaml.Unmarshal(*rawSpec, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse spec: %w", err)
+	}
+
+	if spec.BucketName == "" {
+		return nil, fmt.Errorf("bucketName must not be empty")
+	}
+
+	if spec.Endpoint != "" {
+		if _, err := s3.ParseEndpoint(spec.Endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	return &spec, nil
+}
+
+// Validate validates the type and spec of an uploader definition without creating the uploader
+func Validate(fldPath *field.Path, uploaderType string, spec *json.RawMessage) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch uploaderType {
+	case LocalOCIBlobUploaderType:
+	case OCIArtifactUploaderType:
+		if spec == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("spec"), "spec of an oci artifact uploader must not be empty"))
+		} else if _, err := parseOCIArtifactUploaderSpec(spec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(*spec), err.Error()))
+		}
+	case CTFUploaderType:
+		if spec != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(*spec), "a ctf uploader has no spec, it writes into the target ctf of the transport"))
+		}
+	case S3UploaderType:
+		if spec == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("spec"), "spec of an s3 uploader must not be empty"))
+		} else if _, err := parseS3UploaderSpec(spec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(*spec), err.Error()))
+		}
+	case extensions.ExecutableType:
+		allErrs = append(allErrs, extensions.ValidateExecutable(fldPath.Child("spec"), spec)...)
+	default:
+		supportedTypes := []string{LocalOCIBlobUploaderType, OCIArtifactUploaderType, CTFUploaderType, S3UploaderType, extensions.ExecutableType}
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), uploaderType, supportedTypes))
+	}
+	return allErrs
 }
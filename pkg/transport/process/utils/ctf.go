@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package utils
+
+import (
+	"archive/tar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/ctf"
+	"github.com/mandelsoft/vfs/pkg/projectionfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+
+	"github.com/gardener/component-cli/pkg/utils"
+)
+
+// OriginalAccessLabel is the name of the resource label which contains the original access of a resource
+// which has been converted to a local blob in a CTF.
+const OriginalAccessLabel = "transport.gardener.cloud/original-access"
+
+// A CTF is a tar archive of component archives (see ctf.NewCTF), which can e.g. be pushed with "component-cli ctf push".
+// The component archives of a CTF are extracted to, or staged in, a component archive directory per component version,
+// so that their blobs are not kept in memory:
+//   <dir>/<component-archive>/component-descriptor.yaml
+//   <dir>/<component-archive>/blobs/<blob>
+
+// componentArchiveDir returns the name of the component archive directory of a component version.
+func componentArchiveDir(name, version string) string {
+	return strings.NewReplacer("/", "_", ":", "_").Replace(name + ":" + version)
+}
+
+// CTFReader reads the component archives of a CTF.
+type CTFReader struct {
+	fs  vfs.FileSystem
+	dir string
+}
+
+// ReadCTF extracts the component archives of a CTF into a temporary directory.
+// The temporary directory must be removed via Close().
+func ReadCTF(fs vfs.FileSystem, ctfPath string) (*CTFReader, error) {
+	archive, err := ctf.NewCTF(fs, ctfPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open ctf %s: %w", ctfPath, err)
+	}
+	defer archive.Close()
+
+	dir, err := vfs.TempDir(fs, "", "ctf-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	r := &CTFReader{
+		fs:  fs,
+		dir: dir,
+	}
+
+	err = archive.Walk(func(ca *ctf.ComponentArchive) error {
+		cd := ca.ComponentDescriptor
+		if err := ca.WriteToFilesystem(fs, filepath.Join(dir, componentArchiveDir(cd.Name, cd.Version))); err != nil {
+			return fmt.Errorf("unable to extract component archive %s:%s: %w", cd.Name, cd.Version, err)
+		}
+		return nil
+	})
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("unable to read ctf %s: %w", ctfPath, err)
+	}
+	return r, nil
+}
+
+// ComponentArchive returns the component archive of a component version.
+// ctf.NotFoundError is returned if the CTF doesn't contain the component version.
+func (r *CTFReader) ComponentArchive(name, version string) (*ctf.ComponentArchive, error) {
+	caPath := filepath.Join(r.dir, componentArchiveDir(name, version))
+	if _, err := r.fs.Stat(caPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ctf.NotFoundError
+		}
+		return nil, fmt.Errorf("unable to get file info for component archive %s:%s: %w", name, version, err)
+	}
+	caFs, err := projectionfs.New(r.fs, caPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create filesystem for component archive: %w", err)
+	}
+	return ctf.NewComponentArchiveFromFilesystem(caFs)
+}
+
+// Close removes the extracted component archives.
+func (r *CTFReader) Close() error {
+	return r.fs.RemoveAll(r.dir)
+}
+
+// CTFWriter writes component archives into a CTF. An existing CTF is extended.
+// The blobs of a component version are staged in a component archive directory, until the component descriptor
+// is added. The staging directory "<ctf-path>.staging" is kept until the component archive has been written,
+// so that a resumed transport can add blobs which have been staged by a previous run.
+type CTFWriter struct {
+	fs         vfs.FileSystem
+	path       string
+	stagingDir string
+	archive    *ctf.CTF
+
+	mux sync.Mutex
+	// added contains the staged component archive directories which have been added to the CTF since the last write.
+	added []string
+}
+
+// NewCTFWriter opens a CTF for writing, a new CTF is created if it doesn't exist.
+// The CTF must be closed via Close().
+func NewCTFWriter(fs vfs.FileSystem, ctfPath string) (*CTFWriter, error) {
+	if _, err := fs.Stat(ctfPath); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("unable to get file info for ctf %s: %w", ctfPath, err)
+		}
+		if err := createEmptyCTF(fs, ctfPath); err != nil {
+			return nil, err
+		}
+	}
+
+	archive, err := ctf.NewCTF(fs, ctfPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open ctf %s: %w", ctfPath, err)
+	}
+	return &CTFWriter{
+		fs:         fs,
+		path:       ctfPath,
+		stagingDir: ctfPath + ".staging",
+		archive:    archive,
+	}, nil
+}
+
+// createEmptyCTF creates a CTF without component archives.
+func createEmptyCTF(fs vfs.FileSystem, ctfPath string) error {
+	if err := fs.MkdirAll(filepath.Dir(ctfPath), os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create directory of ctf %s: %w", ctfPath, err)
+	}
+	file, err := fs.Create(ctfPath)
+	if err != nil {
+		return fmt.Errorf("unable to create ctf %s: %w", ctfPath, err)
+	}
+	defer file.Close()
+	if err := tar.NewWriter(file).Close(); err != nil {
+		return fmt.Errorf("unable to write ctf %s: %w", ctfPath, err)
+	}
+	return nil
+}
+
+// Path returns the path of the CTF.
+func (w *CTFWriter) Path() string {
+	return w.path
+}
+
+// ComponentArchiveFS returns the filesystem of the staged component archive directory of a component version.
+// Blobs are written to its blobs directory, named by the digest of the blob (see ctf.BlobPath).
+func (w *CTFWriter) ComponentArchiveFS(name, version string) (vfs.FileSystem, error) {
+	caPath := filepath.Join(w.stagingDir, componentArchiveDir(name, version))
+	if err := w.fs.MkdirAll(filepath.Join(caPath, ctf.BlobsDirectoryName), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create component archive directory: %w", err)
+	}
+	caFs, err := projectionfs.New(w.fs, caPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create filesystem for component archive: %w", err)
+	}
+	return caFs, nil
+}
+
+// ComponentArchiveRef returns the reference of the component archive of a component version in the CTF,
+// which consists of the path of the CTF and the name of the component archive file.
+func (w *CTFWriter) ComponentArchiveRef(name, version string) string {
+	return filepath.Join(w.path, utils.CTFComponentArchiveFilename(name, version))
+}
+
+// AddComponentDescriptor adds the component archive of a component descriptor and its staged blobs to the CTF.
+// The CTF is only written via Write().
+func (w *CTFWriter) AddComponentDescriptor(cd *cdv2.ComponentDescriptor) error {
+	caFs, err := w.ComponentArchiveFS(cd.Name, cd.Version)
+	if err != nil {
+		return err
+	}
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	filename := utils.CTFComponentArchiveFilename(cd.Name, cd.Version)
+	if err := w.archive.AddComponentArchiveWithName(filename, ctf.NewComponentArchive(cd, caFs), ctf.ArchiveFormatTar); err != nil {
+		return fmt.Errorf("unable to add component archive: %w", err)
+	}
+	w.added = append(w.added, filepath.Join(w.stagingDir, componentArchiveDir(cd.Name, cd.Version)))
+	return nil
+}
+
+// Write writes the CTF with all added component archives and removes their staged component archive directories.
+func (w *CTFWriter) Write() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if err := w.archive.Write(); err != nil {
+		return fmt.Errorf("unable to write ctf %s: %w", w.path, err)
+	}
+	for _, caPath := range w.added {
+		if err := w.fs.RemoveAll(caPath); err != nil {
+			return fmt.Errorf("unable to remove staged component archive: %w", err)
+		}
+	}
+	w.added = nil
+	return nil
+}
+
+// Close removes the temporary files of the CTF. The staging directory is removed if it is empty.
+func (w *CTFWriter) Close() error {
+	if entries, err := vfs.ReadDir(w.fs, w.stagingDir); err == nil && len(entries) == 0 {
+		if err := w.fs.Remove(w.stagingDir); err != nil {
+			return fmt.Errorf("unable to remove staging directory: %w", err)
+		}
+	}
+	return w.archive.Close()
+}
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
 	"sigs.k8s.io/yaml"
@@ -54,7 +53,13 @@ func WriteProcessorMessage(cd cdv2.ComponentDescriptor, res cdv2.Resource, resou
 	}
 
 	if resourceBlobReader != nil {
-		if err := utils.WriteFileToTARArchive(ResourceBlobFile, resourceBlobReader, tw); err != nil {
+		if stream, ok := resourceBlobReader.(*resourceBlobStream); ok {
+			// the size of a streamed blob is known from the processor message it originates from.
+			// therefore it can directly be written without buffering.
+			if err := utils.WriteSizedFileToTARArchive(ResourceBlobFile, stream, stream.remaining, tw); err != nil {
+				return fmt.Errorf("unable to write %s: %w", ResourceBlobFile, err)
+			}
+		} else if err := utils.WriteFileToTARArchive(ResourceBlobFile, resourceBlobReader, tw); err != nil {
 			return fmt.Errorf("unable to write %s: %w", ResourceBlobFile, err)
 		}
 	}
@@ -71,7 +76,7 @@ func ReadProcessorMessage(r io.Reader) (*cdv2.ComponentDescriptor, cdv2.Resource
 
 	var cd *cdv2.ComponentDescriptor
 	var res cdv2.Resource
-	var f *os.File
+	var f *TempFile
 
 	for {
 		header, err := tr.Next()
@@ -79,23 +84,28 @@ func ReadProcessorMessage(r io.Reader) (*cdv2.ComponentDescriptor, cdv2.Resource
 			if err == io.EOF {
 				break
 			}
+			closeTempFile(f)
 			return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read tar header: %w", err)
 		}
 
 		switch header.Name {
 		case ResourceFile:
 			if res, err = readResource(tr); err != nil {
+				closeTempFile(f)
 				return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read %s: %w", ResourceFile, err)
 			}
 		case ComponentDescriptorFile:
 			if cd, err = readComponentDescriptor(tr); err != nil {
+				closeTempFile(f)
 				return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read %s: %w", ComponentDescriptorFile, err)
 			}
 		case ResourceBlobFile:
-			if f, err = ioutil.TempFile("", ""); err != nil {
-				return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to create tempfile: %w", err)
+			closeTempFile(f)
+			if f, err = NewTempFile(); err != nil {
+				return nil, cdv2.Resource{}, nil, err
 			}
 			if _, err := io.Copy(f, tr); err != nil {
+				closeTempFile(f)
 				return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read %s: %w", ResourceBlobFile, err)
 			}
 		}
@@ -106,12 +116,122 @@ func ReadProcessorMessage(r io.Reader) (*cdv2.ComponentDescriptor, cdv2.Resource
 	}
 
 	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		closeTempFile(f)
 		return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to seek to beginning of resource blob file: %w", err)
 	}
 
 	return cd, res, f, nil
 }
 
+// ReadProcessorMessageStream reads the component descriptor, resource and resource blob from a processor
+// message like ReadProcessorMessage. In contrast to ReadProcessorMessage, the resource blob is not copied
+// into a tempfile but lazily read from the underlying reader. The returned blob reader is therefore only
+// valid until the underlying reader is closed. If a non-nil value is returned, it must be closed by the
+// caller. Closing the blob reader consumes the rest of the processor message.
+//
+// Processor messages which are written via WriteProcessorMessage contain the resource blob as last file.
+// If the resource blob is found before the component descriptor and resource, it is buffered in a tempfile.
+func ReadProcessorMessageStream(r io.Reader) (*cdv2.ComponentDescriptor, cdv2.Resource, io.ReadCloser, error) {
+	tr := tar.NewReader(r)
+
+	var cd *cdv2.ComponentDescriptor
+	var res *cdv2.Resource
+	var f *TempFile
+
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			closeTempFile(f)
+			return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read tar header: %w", err)
+		}
+
+		switch header.Name {
+		case ResourceFile:
+			r, err := readResource(tr)
+			if err != nil {
+				closeTempFile(f)
+				return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read %s: %w", ResourceFile, err)
+			}
+			res = &r
+		case ComponentDescriptorFile:
+			if cd, err = readComponentDescriptor(tr); err != nil {
+				closeTempFile(f)
+				return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read %s: %w", ComponentDescriptorFile, err)
+			}
+		case ResourceBlobFile:
+			if cd != nil && res != nil {
+				closeTempFile(f)
+				stream := &resourceBlobStream{
+					tr:        tr,
+					remaining: header.Size,
+				}
+				return cd, *res, stream, nil
+			}
+			closeTempFile(f)
+			if f, err = NewTempFile(); err != nil {
+				return nil, cdv2.Resource{}, nil, err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				closeTempFile(f)
+				return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to read %s: %w", ResourceBlobFile, err)
+			}
+		}
+	}
+
+	if res == nil {
+		res = &cdv2.Resource{}
+	}
+
+	if f == nil {
+		return cd, *res, nil, nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		closeTempFile(f)
+		return nil, cdv2.Resource{}, nil, fmt.Errorf("unable to seek to beginning of resource blob file: %w", err)
+	}
+
+	return cd, *res, f, nil
+}
+
+// closeTempFile closes and removes a tempfile which is not returned to the caller.
+func closeTempFile(f *TempFile) {
+	if f != nil {
+		_ = f.Close()
+	}
+}
+
+// resourceBlobStream reads the resource blob directly from the tar archive of a processor message.
+type resourceBlobStream struct {
+	tr        *tar.Reader
+	remaining int64
+}
+
+func (s *resourceBlobStream) Read(p []byte) (int, error) {
+	n, err := s.tr.Read(p)
+	s.remaining -= int64(n)
+	return n, err
+}
+
+// Close consumes the rest of the processor message, so that the writer of the message isn't blocked.
+func (s *resourceBlobStream) Close() error {
+	for {
+		if _, err := io.Copy(ioutil.Discard, s.tr); err != nil {
+			return fmt.Errorf("unable to discard remaining data: %w", err)
+		}
+		s.remaining = 0
+		if _, err := s.tr.Next(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("unable to read tar header: %w", err)
+		}
+	}
+}
+
 func readResource(r *tar.Reader) (cdv2.Resource, error) {
 	buf := bytes.NewBuffer([]byte{})
 	if _, err := io.Copy(buf, r); err != nil {
@@ -4,11 +4,15 @@
 package utils_test
 
 import (
+	"archive/tar"
 	"bytes"
 	"io"
+	"io/ioutil"
+	"os"
 	"strings"
 
 	cdv2 "github.com/gardener/component-spec/bindings-go/apis/v2"
+	"github.com/gardener/component-spec/bindings-go/codec"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -17,6 +21,24 @@ import (
 
 var _ = Describe("util", func() {
 
+	var (
+		tmpDir    string
+		oldTmpDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "")
+		Expect(err).ToNot(HaveOccurred())
+		oldTmpDir = os.Getenv("TMPDIR")
+		Expect(os.Setenv("TMPDIR", tmpDir)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.Setenv("TMPDIR", oldTmpDir)).To(Succeed())
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
 	Context("WriteProcessorMessage & ReadProcessorMessage", func() {
 
 		It("should correctly write and read a processor message", func() {
@@ -53,6 +75,144 @@ var _ = Describe("util", func() {
 			Expect(resourceBlobBuf.String()).To(Equal(resourceData))
 		})
 
+		It("should not buffer a resource blob of a processor message again when it is written", func() {
+			processMsgBuf := bytes.NewBuffer([]byte{})
+			Expect(utils.WriteProcessorMessage(cdv2.ComponentDescriptor{}, cdv2.Resource{}, strings.NewReader("test-data"), processMsgBuf)).To(Succeed())
+
+			cd, res, resourceBlobReader, err := utils.ReadProcessorMessage(processMsgBuf)
+			Expect(err).ToNot(HaveOccurred())
+			defer resourceBlobReader.Close()
+
+			w := &tempDirRecorder{dir: tmpDir}
+			Expect(utils.WriteProcessorMessage(*cd, res, resourceBlobReader, w)).To(Succeed())
+			// only the tempfile of the read processor message exists while the new one is written
+			Expect(w.maxFiles).To(Equal(1))
+		})
+
+	})
+
+	Context("ReadProcessorMessageStream", func() {
+
+		It("should lazily read the resource blob of a processor message", func() {
+			res := cdv2.Resource{
+				IdentityObjectMeta: cdv2.IdentityObjectMeta{
+					Name:    "my-res",
+					Version: "v0.1.0",
+					Type:    "ociImage",
+				},
+			}
+			resourceData := "test-data"
+			cd := cdv2.ComponentDescriptor{}
+
+			processMsgBuf := bytes.NewBuffer([]byte{})
+			err := utils.WriteProcessorMessage(cd, res, strings.NewReader(resourceData), processMsgBuf)
+			Expect(err).ToNot(HaveOccurred())
+
+			actualCD, actualRes, resourceBlobReader, err := utils.ReadProcessorMessageStream(processMsgBuf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*actualCD).To(Equal(cd))
+			Expect(actualRes).To(Equal(res))
+
+			// the resource blob must not be consumed before it is read
+			Expect(processMsgBuf.Len()).To(BeNumerically(">", 0))
+
+			resourceBlobBuf := bytes.NewBuffer([]byte{})
+			_, err = io.Copy(resourceBlobBuf, resourceBlobReader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resourceBlobBuf.String()).To(Equal(resourceData))
+			Expect(resourceBlobReader.Close()).To(Succeed())
+		})
+
+		It("should forward a streamed resource blob to a new processor message", func() {
+			resourceData := "test-data"
+			cd := cdv2.ComponentDescriptor{}
+
+			processMsgBuf := bytes.NewBuffer([]byte{})
+			Expect(utils.WriteProcessorMessage(cd, cdv2.Resource{}, strings.NewReader(resourceData), processMsgBuf)).To(Succeed())
+
+			_, res, resourceBlobReader, err := utils.ReadProcessorMessageStream(processMsgBuf)
+			Expect(err).ToNot(HaveOccurred())
+			defer resourceBlobReader.Close()
+
+			forwardedMsgBuf := bytes.NewBuffer([]byte{})
+			Expect(utils.WriteProcessorMessage(cd, res, resourceBlobReader, forwardedMsgBuf)).To(Succeed())
+
+			_, _, forwardedBlobReader, err := utils.ReadProcessorMessage(forwardedMsgBuf)
+			Expect(err).ToNot(HaveOccurred())
+			defer forwardedBlobReader.Close()
+
+			resourceBlobBuf := bytes.NewBuffer([]byte{})
+			_, err = io.Copy(resourceBlobBuf, forwardedBlobReader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resourceBlobBuf.String()).To(Equal(resourceData))
+		})
+
+		Context("resource blob before the component descriptor", func() {
+
+			It("should remove the buffered resource blob when it is closed", func() {
+				cdBytes, err := codec.Encode(&cdv2.ComponentDescriptor{})
+				Expect(err).ToNot(HaveOccurred())
+
+				processMsgBuf := bytes.NewBuffer([]byte{})
+				tw := tar.NewWriter(processMsgBuf)
+				writeTarFile(tw, utils.ResourceBlobFile, []byte("test-data"))
+				writeTarFile(tw, utils.ComponentDescriptorFile, cdBytes)
+				writeTarFile(tw, utils.ResourceFile, []byte("{}"))
+				Expect(tw.Close()).To(Succeed())
+
+				_, _, resourceBlobReader, err := utils.ReadProcessorMessageStream(processMsgBuf)
+				Expect(err).ToNot(HaveOccurred())
+
+				resourceBlobBuf := bytes.NewBuffer([]byte{})
+				_, err = io.Copy(resourceBlobBuf, resourceBlobReader)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resourceBlobBuf.String()).To(Equal("test-data"))
+
+				Expect(resourceBlobReader.Close()).To(Succeed())
+				Expect(ioutil.ReadDir(tmpDir)).To(BeEmpty())
+			})
+
+			It("should remove the buffered resource blob if the message is invalid", func() {
+				processMsgBuf := bytes.NewBuffer([]byte{})
+				tw := tar.NewWriter(processMsgBuf)
+				writeTarFile(tw, utils.ResourceBlobFile, []byte("test-data"))
+				writeTarFile(tw, utils.ResourceFile, []byte("invalid"))
+				Expect(tw.Close()).To(Succeed())
+
+				_, _, _, err := utils.ReadProcessorMessageStream(processMsgBuf)
+				Expect(err).To(HaveOccurred())
+				Expect(ioutil.ReadDir(tmpDir)).To(BeEmpty())
+			})
+
+		})
+
 	})
 
 })
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) {
+	Expect(tw.WriteHeader(&tar.Header{
+		Name: name,
+		Size: int64(len(data)),
+		Mode: 0600,
+	})).To(Succeed())
+	_, err := tw.Write(data)
+	Expect(err).ToNot(HaveOccurred())
+}
+
+// tempDirRecorder records the maximal number of files in a directory while it is written to.
+type tempDirRecorder struct {
+	dir      string
+	maxFiles int
+}
+
+func (w *tempDirRecorder) Write(p []byte) (int, error) {
+	files, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		return 0, err
+	}
+	if len(files) > w.maxFiles {
+		w.maxFiles = len(files)
+	}
+	return len(p), nil
+}
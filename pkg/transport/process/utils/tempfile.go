@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// TempFile is a temporary file which is deleted when it is closed.
+type TempFile struct {
+	*os.File
+}
+
+// NewTempFile creates a new temporary file in the default directory for temporary files.
+func NewTempFile() (*TempFile, error) {
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create tempfile: %w", err)
+	}
+	return &TempFile{File: f}, nil
+}
+
+// Close closes and deletes the temporary file.
+func (f *TempFile) Close() error {
+	err := f.File.Close()
+	if rmErr := os.Remove(f.Name()); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// JSONFormat writes the report as json
+	JSONFormat = "json"
+	// YAMLFormat writes the report as yaml
+	YAMLFormat = "yaml"
+)
+
+// Status describes the outcome of a component or resource.
+type Status string
+
+const (
+	// StatusSucceeded means that the component or resource has been transferred successfully.
+	StatusSucceeded Status = "Succeeded"
+	// StatusFailed means that the transfer of the component or resource failed.
+	StatusFailed Status = "Failed"
+	// StatusSkipped means that the component or resource hasn't been transferred,
+	// e.g. because it is copied by reference or has already been transferred by a previous run.
+	StatusSkipped Status = "Skipped"
+)
+
+// Report is a machine-readable summary of a copy or transport.
+// All methods are safe for concurrent use.
+type Report struct {
+	mux sync.Mutex
+
+	// StartTime is the time when the operation has been started.
+	StartTime metav1.Time `json:"startTime"`
+	// EndTime is the time when the operation has been finished.
+	EndTime metav1.Time `json:"endTime"`
+	// Components contains the reports of all components in processing order.
+	Components []*ComponentReport `json:"components"`
+}
+
+// ComponentReport describes the outcome of a component descriptor.
+type ComponentReport struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Target is the repository the component descriptor is transported to,
+	// if it is transported to multiple repositories and not to the main target.
+	Target string `json:"target,omitempty"`
+	// SourceRef is the location of the source component descriptor.
+	SourceRef string `json:"sourceRef,omitempty"`
+	// TargetRef is the oci reference of the uploaded component descriptor.
+	TargetRef string `json:"targetRef,omitempty"`
+	// TargetDigest is the manifest digest of the uploaded component descriptor.
+	TargetDigest string `json:"targetDigest,omitempty"`
+	// Duration is the time it took to transfer the component descriptor and its resources.
+	Duration metav1.Duration `json:"duration"`
+	Status   Status          `json:"status"`
+	Error    string          `json:"error,omitempty"`
+	// Resources contains the reports of the resources of the component.
+	Resources []ResourceReport `json:"resources"`
+}
+
+// ResourceReport describes the outcome of a resource.
+type ResourceReport struct {
+	Name          string            `json:"name"`
+	Version       string            `json:"version"`
+	ExtraIdentity map[string]string `json:"extraIdentity,omitempty"`
+	// SourceRef is the location of the resource in the source.
+	SourceRef string `json:"sourceRef,omitempty"`
+	// SourceDigest is the digest of the resource in the source.
+	SourceDigest string `json:"sourceDigest,omitempty"`
+	// TargetRef is the location of the resource in the target.
+	TargetRef string `json:"targetRef,omitempty"`
+	// TargetDigest is the digest of the resource in the target.
+	TargetDigest string `json:"targetDigest,omitempty"`
+	// BytesTransferred is the number of bytes which have been transferred for the resource.
+	BytesTransferred int64 `json:"bytesTransferred"`
+	// Duration is the time it took to transfer the resource.
+	Duration metav1.Duration `json:"duration"`
+	// Processors contains the names of the processors which have been applied to the resource in execution order.
+	Processors []string `json:"processors,omitempty"`
+	Status     Status   `json:"status"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// New creates a new report which starts now.
+func New() *Report {
+	return &Report{
+		StartTime:  metav1.Now(),
+		Components: []*ComponentReport{},
+	}
+}
+
+// UpdateComponent modifies the report of a component while holding the lock of the report.
+// The component report is created if it doesn't exist yet.
+func (r *Report) UpdateComponent(name, version string, update func(c *ComponentReport)) {
+	r.UpdateTargetComponent("", name, version, update)
+}
+
+// UpdateTargetComponent modifies the report of a component which is transported to an additional target.
+// The component report is created if it doesn't exist yet.
+func (r *Report) UpdateTargetComponent(target, name, version string, update func(c *ComponentReport)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for _, c := range r.Components {
+		if c.Name == name && c.Version == version && c.Target == target {
+			update(c)
+			return
+		}
+	}
+	c := &ComponentReport{
+		Name:      name,
+		Version:   version,
+		Target:    target,
+		Resources: []ResourceReport{},
+	}
+	r.Components = append(r.Components, c)
+	update(c)
+}
+
+// AddResource adds the report of a resource to the report of its component.
+func (r *Report) AddResource(componentName, componentVersion string, res ResourceReport) {
+	r.AddTargetResource("", componentName, componentVersion, res)
+}
+
+// AddTargetResource adds the report of a resource to the report of its component for an additional target.
+func (r *Report) AddTargetResource(target, componentName, componentVersion string, res ResourceReport) {
+	r.UpdateTargetComponent(target, componentName, componentVersion, func(c *ComponentReport) {
+		c.Resources = append(c.Resources, res)
+	})
+}
+
+// Finish sets the end time of the report.
+func (r *Report) Finish() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.EndTime = metav1.Now()
+}
+
+// Write writes the report to a file. The format is derived from the file extension.
+func (r *Report) Write(fs vfs.FileSystem, path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	var data []byte
+	if format == JSONFormat {
+		data, err = json.MarshalIndent(r, "", "  ")
+	} else {
+		data, err = yaml.Marshal(r)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to marshal report: %w", err)
+	}
+
+	if err := vfs.WriteFile(fs, path, data, 0644); err != nil {
+		return fmt.Errorf("unable to write report to %s: %w", path, err)
+	}
+	return nil
+}
+
+// FormatFromPath returns the report format for a file path.
+// Files with the extension .json are written as json, files with the extension .yaml or .yml as yaml.
+func FormatFromPath(path string) (string, error) {
+	switch filepath.Ext(path) {
+	case ".json":
+		return JSONFormat, nil
+	case ".yaml", ".yml":
+		return YAMLFormat, nil
+	default:
+		return "", fmt.Errorf("unable to derive report format from %s: file extension must be one of .json, .yaml, .yml", path)
+	}
+}
+
+// Since returns the duration since t.
+func Since(t time.Time) metav1.Duration {
+	return metav1.Duration{Duration: time.Since(t)}
+}
+
+// StatusFromError returns the status and error message for the result of an operation.
+func StatusFromError(err error) (Status, string) {
+	if err != nil {
+		return StatusFailed, err.Error()
+	}
+	return StatusSucceeded, ""
+}
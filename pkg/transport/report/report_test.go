@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package report_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mandelsoft/vfs/pkg/memoryfs"
+	"github.com/mandelsoft/vfs/pkg/vfs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/gardener/component-cli/pkg/transport/report"
+)
+
+var _ = Describe("Report", func() {
+
+	newReport := func() *report.Report {
+		r := report.New()
+		r.UpdateComponent("example.com/a", "v0.1.0", func(c *report.ComponentReport) {
+			c.SourceRef = "example.com/source/component-descriptors/example.com/a:v0.1.0"
+		})
+		r.AddResource("example.com/a", "v0.1.0", report.ResourceReport{
+			Name:             "image",
+			Version:          "0.1.0",
+			SourceRef:        "example.com/image:0.1.0",
+			TargetRef:        "example.com/target/image:0.1.0",
+			BytesTransferred: 10,
+			Duration:         metav1.Duration{Duration: time.Second},
+			Processors:       []string{"downloader", "uploader"},
+			Status:           report.StatusSucceeded,
+		})
+		status, msg := report.StatusFromError(errors.New("upload failed"))
+		r.UpdateComponent("example.com/a", "v0.1.0", func(c *report.ComponentReport) {
+			c.Status = status
+			c.Error = msg
+		})
+		r.Finish()
+		return r
+	}
+
+	It("should collect resources per component", func() {
+		r := newReport()
+		Expect(r.Components).To(HaveLen(1))
+		Expect(r.Components[0].SourceRef).To(Equal("example.com/source/component-descriptors/example.com/a:v0.1.0"))
+		Expect(r.Components[0].Status).To(Equal(report.StatusFailed))
+		Expect(r.Components[0].Error).To(Equal("upload failed"))
+		Expect(r.Components[0].Resources).To(HaveLen(1))
+		Expect(r.Components[0].Resources[0].Name).To(Equal("image"))
+	})
+
+	It("should write the report as json and yaml", func() {
+		fs := memoryfs.New()
+		r := newReport()
+
+		Expect(r.Write(fs, "/report.json")).To(Succeed())
+		data, err := vfs.ReadFile(fs, "/report.json")
+		Expect(err).ToNot(HaveOccurred())
+		fromJSON := report.Report{}
+		Expect(json.Unmarshal(data, &fromJSON)).To(Succeed())
+		Expect(fromJSON.Components).To(HaveLen(1))
+		Expect(fromJSON.Components[0].Resources[0].Duration.Duration).To(Equal(time.Second))
+
+		Expect(r.Write(fs, "/report.yaml")).To(Succeed())
+		data, err = vfs.ReadFile(fs, "/report.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		fromYAML := report.Report{}
+		Expect(yaml.Unmarshal(data, &fromYAML)).To(Succeed())
+		Expect(fromYAML.Components[0].Resources[0]).To(Equal(fromJSON.Components[0].Resources[0]))
+
+		Expect(r.Write(fs, "/report.txt")).To(HaveOccurred())
+	})
+
+	It("should derive the format from the file extension", func() {
+		Expect(report.FormatFromPath("report.json")).To(Equal(report.JSONFormat))
+		Expect(report.FormatFromPath("report.yaml")).To(Equal(report.YAMLFormat))
+		Expect(report.FormatFromPath("report.yml")).To(Equal(report.YAMLFormat))
+		_, err := report.FormatFromPath("report")
+		Expect(err).To(HaveOccurred())
+	})
+
+})
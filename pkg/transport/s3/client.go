@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRegion is the region which is used if no region is configured.
+	DefaultRegion = "us-east-1"
+
+	// AccessKeyIDEnv is the environment variable which contains the access key id.
+	AccessKeyIDEnv = "AWS_ACCESS_KEY_ID"
+	// SecretAccessKeyEnv is the environment variable which contains the secret access key.
+	SecretAccessKeyEnv = "AWS_SECRET_ACCESS_KEY"
+	// SessionTokenEnv is the environment variable which contains the optional session token.
+	SessionTokenEnv = "AWS_SESSION_TOKEN"
+)
+
+// Config configures the S3 endpoint a client talks to.
+type Config struct {
+	// Endpoint is the url of the S3 endpoint, e.g. "https://s3.eu-central-1.amazonaws.com".
+	// Defaults to the AWS endpoint of the region.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Region is the region which is used for signing requests. Defaults to "us-east-1".
+	Region string `json:"region,omitempty"`
+	// PathStyle addresses buckets as part of the path ("<endpoint>/<bucket>/<key>")
+	// instead of as part of the host ("<bucket>.<endpoint>/<key>").
+	// Most S3 compatible object stores require path style access.
+	PathStyle bool `json:"pathStyle,omitempty"`
+}
+
+// Credentials are the credentials which are used to sign requests.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// CredentialsFromEnv reads the credentials from the standard AWS environment variables.
+// Nil is returned if no access key id is set, which results in anonymous requests.
+func CredentialsFromEnv() *Credentials {
+	accessKeyID := os.Getenv(AccessKeyIDEnv)
+	if accessKeyID == "" {
+		return nil
+	}
+	return &Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: os.Getenv(SecretAccessKeyEnv),
+		SessionToken:    os.Getenv(SessionTokenEnv),
+	}
+}
+
+// Client is a minimal S3 client which can read and write objects.
+type Client struct {
+	httpClient  *http.Client
+	endpoint    *url.URL
+	region      string
+	pathStyle   bool
+	credentials *Credentials
+}
+
+// NewClient creates a new S3 client. Requests are sent anonymously if credentials is nil.
+func NewClient(httpClient *http.Client, cfg Config, credentials *Credentials) (*Client, error) {
+	if httpClient == nil {
+		return nil, errors.New("httpClient must not be nil")
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = DefaultEndpoint(region)
+	}
+	endpoint, err := ParseEndpoint(rawEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := Client{
+		httpClient:  httpClient,
+		endpoint:    endpoint,
+		region:      region,
+		pathStyle:   cfg.PathStyle,
+		credentials: credentials,
+	}
+	return &obj, nil
+}
+
+// DefaultEndpoint returns the AWS endpoint of a region.
+func DefaultEndpoint(region string) string {
+	if region == DefaultRegion {
+		return "https://s3.amazonaws.com"
+	}
+	return fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+}
+
+// ParseEndpoint parses an endpoint url. Only http and https urls without path are allowed.
+func ParseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse endpoint %s: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("scheme of endpoint %s must be one of http, https", endpoint)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("endpoint %s must contain a host", endpoint)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("endpoint %s must not contain a path", endpoint)
+	}
+	return u, nil
+}
+
+// ObjectURL returns the url of an object.
+func (c *Client) ObjectURL(bucket, key string) *url.URL {
+	u := *c.endpoint
+	path := "/" + strings.TrimPrefix(key, "/")
+	if c.pathStyle {
+		path = "/" + bucket + path
+	} else {
+		u.Host = bucket + "." + u.Host
+	}
+	u.Path = path
+	u.RawPath = uriEncode(path, false)
+	return &u
+}
+
+// GetObject reads an object. The caller has to close the returned reader.
+func (c *Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, bucket, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get object %s: %w", req.URL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, responseError(req, resp)
+	}
+	return resp.Body, nil
+}
+
+// PutObject writes an object. S3 requires the size of the object to be known in advance.
+func (c *Client) PutObject(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error {
+	req, err := c.newRequest(ctx, http.MethodPut, bucket, key, ioutil.NopCloser(r))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if err := c.sign(req); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to put object %s: %w", req.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(req, resp)
+	}
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, bucket, key string, body io.ReadCloser) (*http.Request, error) {
+	if bucket == "" {
+		return nil, errors.New("bucket must not be empty")
+	}
+	if key == "" {
+		return nil, errors.New("key must not be empty")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.ObjectURL(bucket, key).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	if body == nil {
+		if err := c.sign(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func (c *Client) sign(req *http.Request) error {
+	if c.credentials == nil {
+		return nil
+	}
+	return Sign(req, *c.credentials, c.region, UnsignedPayload, time.Now())
+}
+
+func responseError(req *http.Request, resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		return fmt.Errorf("%s %s failed with response code %d", req.Method, req.URL, resp.StatusCode)
+	}
+	return fmt.Errorf("%s %s failed with response code %d: %s", req.Method, req.URL, resp.StatusCode, msg)
+}
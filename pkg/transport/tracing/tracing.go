@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// SpanData describes a finished span.
+// Spans follow the OpenTelemetry model: all spans of an operation share the trace id
+// and every span except the root span references the span it has been started in.
+type SpanData struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Name     string
+	// Attributes contains alternating keys and values.
+	Attributes []interface{}
+	StartTime  time.Time
+	EndTime    time.Time
+	// Error is the error of the traced operation.
+	Error error
+}
+
+// Duration returns the duration of the span.
+func (d SpanData) Duration() time.Duration {
+	return d.EndTime.Sub(d.StartTime)
+}
+
+// Exporter receives all finished spans.
+// Implementations must be safe for concurrent use.
+type Exporter interface {
+	ExportSpan(ctx context.Context, span SpanData)
+}
+
+type exporterKey struct{}
+
+type spanKey struct{}
+
+// ContextWithExporter returns a context whose spans are exported with the given exporter.
+// Spans are logged at V(4) if the context has no exporter.
+func ContextWithExporter(ctx context.Context, exporter Exporter) context.Context {
+	return context.WithValue(ctx, exporterKey{}, exporter)
+}
+
+// Span traces a single operation. A span must be ended with End().
+type Span struct {
+	ctx  context.Context
+	once sync.Once
+	mux  sync.Mutex
+	data SpanData
+}
+
+// Start starts a new span which is a child of the span in the context.
+// The returned context contains the new span.
+func Start(ctx context.Context, name string, keysAndValues ...interface{}) (context.Context, *Span) {
+	span := &Span{
+		ctx: ctx,
+		data: SpanData{
+			SpanID:     randomID(8),
+			Name:       name,
+			Attributes: keysAndValues,
+			StartTime:  time.Now(),
+		},
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentID = parent.data.SpanID
+	} else {
+		span.data.TraceID = randomID(16)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds attributes as alternating keys and values to the span.
+func (s *Span) SetAttributes(keysAndValues ...interface{}) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.data.Attributes = append(s.data.Attributes, keysAndValues...)
+}
+
+// RecordError records the error of the traced operation. Nil errors are ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.data.Error = err
+}
+
+// End finishes the span and exports it. Subsequent calls are ignored.
+func (s *Span) End() {
+	s.once.Do(func() {
+		s.mux.Lock()
+		s.data.EndTime = time.Now()
+		data := s.data
+		s.mux.Unlock()
+
+		if exporter, ok := s.ctx.Value(exporterKey{}).(Exporter); ok {
+			exporter.ExportSpan(s.ctx, data)
+			return
+		}
+		logSpan(s.ctx, data)
+	})
+}
+
+func logSpan(ctx context.Context, data SpanData) {
+	keysAndValues := []interface{}{
+		"name", data.Name,
+		"traceID", data.TraceID,
+		"spanID", data.SpanID,
+		"parentID", data.ParentID,
+		"duration", data.Duration().String(),
+	}
+	keysAndValues = append(keysAndValues, data.Attributes...)
+	if data.Error != nil {
+		keysAndValues = append(keysAndValues, "error", data.Error.Error())
+	}
+	logr.FromContextOrDiscard(ctx).V(4).Info("span finished", keysAndValues...)
+}
+
+func randomID(n int) string {
+	id := make([]byte, n)
+	// ids are only used to correlate spans, therefore a failing entropy source is ignored
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
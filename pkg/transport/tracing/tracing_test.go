@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2021 SAP SE or an SAP affiliate company and Gardener contributors.
+//
+// SPDX-License-Identifier: Apache-2.0
+package tracing_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/gardener/component-cli/pkg/transport/tracing"
+)
+
+type recordingExporter struct {
+	spans []tracing.SpanData
+}
+
+func (e *recordingExporter) ExportSpan(_ context.Context, span tracing.SpanData) {
+	e.spans = append(e.spans, span)
+}
+
+var _ = Describe("Tracing", func() {
+
+	It("should export child spans with the trace of their parent", func() {
+		exporter := &recordingExporter{}
+		ctx := tracing.ContextWithExporter(context.TODO(), exporter)
+
+		ctx, parent := tracing.Start(ctx, "parent", "a", "b")
+		_, child := tracing.Start(ctx, "child")
+		child.SetAttributes("c", 1)
+		child.RecordError(errors.New("failed"))
+		child.End()
+		parent.End()
+
+		Expect(exporter.spans).To(HaveLen(2))
+		childData, parentData := exporter.spans[0], exporter.spans[1]
+		Expect(parentData.Name).To(Equal("parent"))
+		Expect(parentData.ParentID).To(BeEmpty())
+		Expect(parentData.Attributes).To(Equal([]interface{}{"a", "b"}))
+		Expect(parentData.Error).ToNot(HaveOccurred())
+
+		Expect(childData.Name).To(Equal("child"))
+		Expect(childData.TraceID).To(Equal(parentData.TraceID))
+		Expect(childData.ParentID).To(Equal(parentData.SpanID))
+		Expect(childData.SpanID).ToNot(Equal(parentData.SpanID))
+		Expect(childData.Attributes).To(Equal([]interface{}{"c", 1}))
+		Expect(childData.Error).To(MatchError("failed"))
+		Expect(childData.Duration()).To(BeNumerically(">=", 0))
+	})
+
+	It("should start a new trace for root spans and export a span only once", func() {
+		exporter := &recordingExporter{}
+		ctx := tracing.ContextWithExporter(context.TODO(), exporter)
+
+		_, first := tracing.Start(ctx, "first")
+		_, second := tracing.Start(ctx, "second")
+		first.End()
+		first.End()
+		second.End()
+
+		Expect(exporter.spans).To(HaveLen(2))
+		Expect(exporter.spans[0].TraceID).ToNot(Equal(exporter.spans[1].TraceID))
+	})
+
+})
@@ -178,7 +178,9 @@ func BytesString(bytes uint64, accuracy int) string {
 	return fmt.Sprintf("%s %s", stringValue, unit)
 }
 
-// WriteFileToTARArchive writes a new file with name=filename and content=inputReader to outputWriter
+// WriteFileToTARArchive writes a new file with name=filename and content=inputReader to outputWriter.
+// If the size of the content can't be determined from the reader (regular files and in-memory readers),
+// the content is buffered in a tempfile to calculate the size.
 func WriteFileToTARArchive(filename string, inputReader io.Reader, outputWriter *tar.Writer) error {
 	if filename == "" {
 		return errors.New("filename must not be empty")
@@ -192,11 +194,22 @@ func WriteFileToTARArchive(filename string, inputReader io.Reader, outputWriter
 		return errors.New("outputWriter must not be nil")
 	}
 
+	size, ok, err := remainingSize(inputReader)
+	if err != nil {
+		return fmt.Errorf("unable to determine content size: %w", err)
+	}
+	if ok {
+		return WriteSizedFileToTARArchive(filename, inputReader, size, outputWriter)
+	}
+
 	tempfile, err := ioutil.TempFile("", "")
 	if err != nil {
 		return fmt.Errorf("unable to create tempfile: %w", err)
 	}
-	defer tempfile.Close()
+	defer func() {
+		tempfile.Close()
+		os.Remove(tempfile.Name())
+	}()
 
 	fsize, err := io.Copy(tempfile, inputReader)
 	if err != nil {
@@ -207,9 +220,28 @@ func WriteFileToTARArchive(filename string, inputReader io.Reader, outputWriter
 		return fmt.Errorf("unable to seek to beginning of tempfile: %w", err)
 	}
 
+	return WriteSizedFileToTARArchive(filename, tempfile, fsize, outputWriter)
+}
+
+// WriteSizedFileToTARArchive writes a new file with name=filename and content=inputReader to outputWriter.
+// The content is streamed directly into the archive, therefore size must exactly match the number
+// of bytes that are read from inputReader.
+func WriteSizedFileToTARArchive(filename string, inputReader io.Reader, size int64, outputWriter *tar.Writer) error {
+	if filename == "" {
+		return errors.New("filename must not be empty")
+	}
+
+	if inputReader == nil {
+		return errors.New("inputReader must not be nil")
+	}
+
+	if outputWriter == nil {
+		return errors.New("outputWriter must not be nil")
+	}
+
 	header := tar.Header{
 		Name:    filename,
-		Size:    int64(fsize),
+		Size:    size,
 		Mode:    0600,
 		ModTime: time.Now(),
 	}
@@ -218,13 +250,52 @@ func WriteFileToTARArchive(filename string, inputReader io.Reader, outputWriter
 		return fmt.Errorf("unable to write tar header: %w", err)
 	}
 
-	if _, err := io.Copy(outputWriter, tempfile); err != nil {
+	n, err := io.Copy(outputWriter, inputReader)
+	if err != nil {
 		return fmt.Errorf("unable to write file to tar archive: %w", err)
 	}
+	if n != size {
+		return fmt.Errorf("unable to write file to tar archive: expected %d bytes but got %d bytes", size, n)
+	}
 
 	return nil
 }
 
+// statSeeker is implemented by *os.File and types which embed it.
+type statSeeker interface {
+	Stat() (os.FileInfo, error)
+	io.Seeker
+}
+
+// remainingSize returns the number of bytes that are left to read from a reader,
+// if it can be determined without consuming the reader.
+func remainingSize(r io.Reader) (int64, bool, error) {
+	switch reader := r.(type) {
+	case *bytes.Reader:
+		return int64(reader.Len()), true, nil
+	case *bytes.Buffer:
+		return int64(reader.Len()), true, nil
+	case *strings.Reader:
+		return int64(reader.Len()), true, nil
+	case statSeeker:
+		// files and wrappers of files, like the buffered resource blobs of processor messages
+		info, err := reader.Stat()
+		if err != nil {
+			return 0, false, err
+		}
+		if !info.Mode().IsRegular() {
+			return 0, false, nil
+		}
+		offset, err := reader.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false, err
+		}
+		return info.Size() - offset, true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
 // TargetOCIArtifactRef calculates the target reference for
 func TargetOCIArtifactRef(targetRepo, ref string, keepOrigHost bool) (string, error) {
 	if !strings.Contains(targetRepo, "://") {